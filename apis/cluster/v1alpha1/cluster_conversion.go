@@ -0,0 +1,24 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+// Hub marks Cluster as the conversion hub, so every other version
+// (currently just v1beta1) converts to and from this one instead of
+// directly between each other. v1alpha1 is the hub, rather than the
+// newer v1beta1, because every reconciler and internal package in
+// this repository still operates on v1alpha1 types
+func (*Cluster) Hub() {}
@@ -17,26 +17,42 @@
 package v1alpha1
 
 import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
 	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
 	constantsapi "github.com/oneinfra/oneinfra/internal/pkg/constants"
 	"github.com/oneinfra/oneinfra/internal/pkg/utils"
 	"github.com/oneinfra/oneinfra/pkg/constants"
 )
 
+// clusterWebhookClient is used by the defaulting webhook to look up
+// the OneInfraConfig in force for a cluster's namespace.
+// webhook.Defaulter's Default() has no access to the manager that
+// set it up, so SetupWebhookWithManager stashes a client here
+var clusterWebhookClient client.Client
+
 // SetupWebhookWithManager registers this web hook on the given
 // manager instance
 func (cluster *Cluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	clusterWebhookClient = mgr.GetClient()
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(cluster).
 		Complete()
 }
 
 // +kubebuilder:webhook:path=/mutate-cluster-oneinfra-ereslibre-es-v1alpha1-cluster,mutating=true,failurePolicy=fail,groups=cluster.oneinfra.ereslibre.es,resources=clusters,verbs=create;update,versions=v1alpha1,name=mcluster.kb.io
+// +kubebuilder:rbac:groups=cluster.oneinfra.ereslibre.es,resources=oneinfraconfigs,verbs=get;list;watch
 
 var _ webhook.Defaulter = &Cluster{}
 
@@ -44,11 +60,13 @@ var _ webhook.Defaulter = &Cluster{}
 func (cluster *Cluster) Default() {
 	klog.Info("default", "name", cluster.Name)
 	cluster.addFinalizer()
+	cluster.defaultFromOperatorConfig()
 	cluster.defaultKubernetesVersion()
 	cluster.defaultControlPlaneReplicas()
 	cluster.defaultVPN()
 	cluster.defaultUninitializedCertificatesLabel()
 	cluster.defaultNetworking()
+	cluster.defaultComponents()
 }
 
 func (cluster *Cluster) addFinalizer() {
@@ -64,6 +82,39 @@ func (cluster *Cluster) addFinalizer() {
 	)
 }
 
+// defaultFromOperatorConfig applies the namespace-level defaults
+// declared by the OneInfraConfig in this cluster's namespace, if any,
+// to the spec fields the cluster hasn't set itself. It is a no-op if
+// the webhook has no client available, the namespace has no
+// OneInfraConfig, or more than one is found
+func (cluster *Cluster) defaultFromOperatorConfig() {
+	if clusterWebhookClient == nil {
+		return
+	}
+	operatorConfigList := &OneInfraConfigList{}
+	if err := clusterWebhookClient.List(context.Background(), operatorConfigList, client.InNamespace(cluster.Namespace)); err != nil {
+		klog.Errorf("could not list OneInfraConfig in namespace %q: %v", cluster.Namespace, err)
+		return
+	}
+	if len(operatorConfigList.Items) != 1 {
+		return
+	}
+	operatorConfig := operatorConfigList.Items[0]
+	if cluster.Spec.KubernetesVersion == "" && operatorConfig.Spec.KubernetesVersion != "" {
+		cluster.Spec.KubernetesVersion = operatorConfig.Spec.KubernetesVersion
+	}
+	if cluster.Spec.Size == nil && operatorConfig.Spec.Size != nil {
+		size := *operatorConfig.Spec.Size
+		cluster.Spec.Size = &size
+	}
+	if cluster.Spec.VPN == nil && operatorConfig.Spec.VPNEnabled != nil {
+		cluster.Spec.VPN = &VPN{Enabled: *operatorConfig.Spec.VPNEnabled}
+	}
+	if cluster.Spec.ImageRegistry == "" && operatorConfig.Spec.ImageRepository != "" {
+		cluster.Spec.ImageRegistry = operatorConfig.Spec.ImageRepository
+	}
+}
+
 func (cluster *Cluster) defaultKubernetesVersion() {
 	if cluster.Spec.KubernetesVersion == "" || cluster.Spec.KubernetesVersion == "default" {
 		cluster.Spec.KubernetesVersion = constantsapi.ReleaseData.DefaultKubernetesVersion
@@ -87,7 +138,11 @@ func (cluster *Cluster) defaultVPN() {
 		defaultVPNCIDR := constants.DefaultVPNCIDR
 		cluster.Spec.VPN.CIDR = &defaultVPNCIDR
 	}
-	if cluster.Spec.VPN.Enabled && (cluster.Spec.VPN.PrivateKey == nil || cluster.Spec.VPN.PublicKey == nil) {
+	if cluster.Spec.VPN.Backend == "" {
+		cluster.Spec.VPN.Backend = VPNBackendWireGuard
+	}
+	if cluster.Spec.VPN.Enabled && cluster.Spec.VPN.Backend == VPNBackendWireGuard &&
+		(cluster.Spec.VPN.PrivateKey == nil || cluster.Spec.VPN.PublicKey == nil) {
 		privateKey, err := wgtypes.GeneratePrivateKey()
 		if err != nil {
 			return
@@ -119,17 +174,27 @@ func (cluster *Cluster) defaultNetworking() {
 	}
 }
 
+func (cluster *Cluster) defaultComponents() {
+	if cluster.Spec.Components == nil {
+		cluster.Spec.Components = &Components{
+			Scheduler:         true,
+			ControllerManager: true,
+		}
+	}
+}
+
 func (cluster *Cluster) needsCertificateInitialization() bool {
+	usesExternalEtcd := cluster.Spec.EtcdServer != nil && cluster.Spec.EtcdServer.External != nil
 	if cluster.Spec.CertificateAuthorities == nil ||
 		cluster.Spec.CertificateAuthorities.APIServerClient == nil ||
 		cluster.Spec.CertificateAuthorities.CertificateSigner == nil ||
 		cluster.Spec.CertificateAuthorities.Kubelet == nil ||
 		cluster.Spec.CertificateAuthorities.KubeletClient == nil ||
-		cluster.Spec.CertificateAuthorities.EtcdClient == nil ||
-		cluster.Spec.CertificateAuthorities.EtcdPeer == nil {
+		(!usesExternalEtcd && cluster.Spec.CertificateAuthorities.EtcdClient == nil) ||
+		(!usesExternalEtcd && cluster.Spec.CertificateAuthorities.EtcdPeer == nil) {
 		return true
 	}
-	if cluster.Spec.EtcdServer == nil || cluster.Spec.EtcdServer.CA == nil {
+	if !usesExternalEtcd && (cluster.Spec.EtcdServer == nil || cluster.Spec.EtcdServer.CA == nil) {
 		return true
 	}
 	if cluster.Spec.APIServer == nil ||
@@ -137,6 +202,9 @@ func (cluster *Cluster) needsCertificateInitialization() bool {
 		cluster.Spec.APIServer.ServiceAccount == nil {
 		return true
 	}
+	if cluster.Spec.JoinKeyCipherSuite == JoinKeyCipherSuiteNaClBox {
+		return cluster.Spec.JoinBoxKey == nil
+	}
 	return cluster.Spec.JoinKey == nil
 }
 
@@ -147,15 +215,186 @@ var _ webhook.Validator = &Cluster{}
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (cluster *Cluster) ValidateCreate() error {
 	klog.Info("validate create", "name", cluster.Name)
+	if err := cluster.validateVPNCIDR(); err != nil {
+		return err
+	}
+	if err := cluster.validateVPNCIDRDoesNotOverlap(); err != nil {
+		return err
+	}
+	return cluster.validateExtraSANs()
+}
+
+// validateVPNCIDR rejects a malformed VPN CIDR early, instead of
+// letting the manager fail later when it tries to allocate VPN
+// addresses out of it
+func (cluster *Cluster) validateVPNCIDR() error {
+	if cluster.Spec.VPN == nil || cluster.Spec.VPN.CIDR == nil {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(*cluster.Spec.VPN.CIDR); err != nil {
+		return errors.Wrapf(err, "invalid vpn.CIDR %q", *cluster.Spec.VPN.CIDR)
+	}
+	return nil
+}
+
+// validateVPNCIDRDoesNotOverlap rejects a VPN CIDR that overlaps
+// with the VPN CIDR of another cluster, since VPN peers across
+// clusters would otherwise be assigned colliding addresses. A no-op
+// if the webhook has no client available
+func (cluster *Cluster) validateVPNCIDRDoesNotOverlap() error {
+	if clusterWebhookClient == nil || cluster.Spec.VPN == nil || cluster.Spec.VPN.CIDR == nil {
+		return nil
+	}
+	_, clusterNet, err := net.ParseCIDR(*cluster.Spec.VPN.CIDR)
+	if err != nil {
+		return nil
+	}
+	clusterList := &ClusterList{}
+	if err := clusterWebhookClient.List(context.Background(), clusterList); err != nil {
+		klog.Errorf("could not list clusters to check for overlapping vpn.CIDR: %v", err)
+		return nil
+	}
+	for _, otherCluster := range clusterList.Items {
+		if otherCluster.Namespace == cluster.Namespace && otherCluster.Name == cluster.Name {
+			continue
+		}
+		if otherCluster.Spec.VPN == nil || otherCluster.Spec.VPN.CIDR == nil {
+			continue
+		}
+		_, otherNet, err := net.ParseCIDR(*otherCluster.Spec.VPN.CIDR)
+		if err != nil {
+			continue
+		}
+		if clusterNet.Contains(otherNet.IP) || otherNet.Contains(clusterNet.IP) {
+			return errors.Errorf("vpn.CIDR %q overlaps with cluster %q/%q's vpn.CIDR %q", *cluster.Spec.VPN.CIDR, otherCluster.Namespace, otherCluster.Name, *otherCluster.Spec.VPN.CIDR)
+		}
+	}
+	return nil
+}
+
+// validateExtraSANs rejects API server extra SANs that are neither a
+// valid IP address nor a valid DNS name, since an invalid SAN would
+// otherwise surface much later as an obscure certificate generation
+// failure
+func (cluster *Cluster) validateExtraSANs() error {
+	if cluster.Spec.APIServer == nil {
+		return nil
+	}
+	for _, extraSAN := range cluster.Spec.APIServer.ExtraSANs {
+		if net.ParseIP(extraSAN) != nil {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(extraSAN); len(errs) > 0 {
+			return errors.Errorf("apiServer.extraSANs %q is not a valid IP address or DNS name: %s", extraSAN, strings.Join(errs, "; "))
+		}
+	}
 	return nil
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (cluster *Cluster) ValidateUpdate(old runtime.Object) error {
 	klog.Info("validate update", "name", cluster.Name)
+	oldCluster, ok := old.(*Cluster)
+	if !ok {
+		return nil
+	}
+	if err := cluster.validateExtraSANs(); err != nil {
+		return err
+	}
+	_, migrationAllowed := cluster.Annotations[constants.OneInfraAllowMigrationAnnotation]
+	if err := cluster.validateVPNCIDRImmutable(oldCluster, migrationAllowed); err != nil {
+		return err
+	}
+	if migrationAllowed {
+		return nil
+	}
+	return cluster.validateCertificateAuthoritiesImmutable(oldCluster)
+}
+
+// validateVPNCIDRImmutable rejects changes to the VPN CIDR, unless
+// migrationAllowed is set, in which case only a strict expansion of
+// the existing range is accepted. The cluster name is intentionally
+// not checked here: Kubernetes already enforces metadata.name
+// immutability on every object, oneinfra clusters included
+func (cluster *Cluster) validateVPNCIDRImmutable(old *Cluster, migrationAllowed bool) error {
+	if old.Spec.VPN == nil || cluster.Spec.VPN == nil ||
+		old.Spec.VPN.CIDR == nil || cluster.Spec.VPN.CIDR == nil {
+		return nil
+	}
+	if *old.Spec.VPN.CIDR == *cluster.Spec.VPN.CIDR {
+		return nil
+	}
+	if !migrationAllowed {
+		return errors.Errorf("vpn.CIDR is immutable once set; use `oi cluster expand-vpn-cidr` to grow it")
+	}
+	return validateVPNCIDRExpansion(*old.Spec.VPN.CIDR, *cluster.Spec.VPN.CIDR)
+}
+
+// validateVPNCIDRExpansion accepts newCIDR only if it fully contains
+// oldCIDR, so expand-vpn-cidr can only grow the existing range, never
+// move it or shrink it
+func validateVPNCIDRExpansion(oldCIDR, newCIDR string) error {
+	_, oldNet, err := net.ParseCIDR(oldCIDR)
+	if err != nil {
+		return errors.Wrapf(err, "invalid existing vpn.CIDR %q", oldCIDR)
+	}
+	_, newNet, err := net.ParseCIDR(newCIDR)
+	if err != nil {
+		return errors.Wrapf(err, "invalid vpn.CIDR %q", newCIDR)
+	}
+	oldOnes, _ := oldNet.Mask.Size()
+	newOnes, _ := newNet.Mask.Size()
+	if newOnes > oldOnes || !newNet.Contains(oldNet.IP) {
+		return errors.Errorf("vpn.CIDR %q does not fully contain the existing %q; expand-vpn-cidr only supports growing the existing range", newCIDR, oldCIDR)
+	}
 	return nil
 }
 
+// validateCertificateAuthoritiesImmutable rejects direct edits to any
+// certificate authority material; `oi cluster rotate-ca` is the only
+// supported way to change it, and it does so through the allow
+// migration annotation rather than through this check
+func (cluster *Cluster) validateCertificateAuthoritiesImmutable(old *Cluster) error {
+	if old.Spec.CertificateAuthorities != nil && cluster.Spec.CertificateAuthorities != nil {
+		if err := validateCertificateAuthorityImmutable("certificateAuthorities.apiServerClient", old.Spec.CertificateAuthorities.APIServerClient, cluster.Spec.CertificateAuthorities.APIServerClient); err != nil {
+			return err
+		}
+		if err := validateCertificateAuthorityImmutable("certificateAuthorities.certificateSigner", old.Spec.CertificateAuthorities.CertificateSigner, cluster.Spec.CertificateAuthorities.CertificateSigner); err != nil {
+			return err
+		}
+		if err := validateCertificateAuthorityImmutable("certificateAuthorities.kubelet", old.Spec.CertificateAuthorities.Kubelet, cluster.Spec.CertificateAuthorities.Kubelet); err != nil {
+			return err
+		}
+		if err := validateCertificateAuthorityImmutable("certificateAuthorities.kubeletClient", old.Spec.CertificateAuthorities.KubeletClient, cluster.Spec.CertificateAuthorities.KubeletClient); err != nil {
+			return err
+		}
+		if err := validateCertificateAuthorityImmutable("certificateAuthorities.etcdClient", old.Spec.CertificateAuthorities.EtcdClient, cluster.Spec.CertificateAuthorities.EtcdClient); err != nil {
+			return err
+		}
+		if err := validateCertificateAuthorityImmutable("certificateAuthorities.etcdPeer", old.Spec.CertificateAuthorities.EtcdPeer, cluster.Spec.CertificateAuthorities.EtcdPeer); err != nil {
+			return err
+		}
+	}
+	if old.Spec.EtcdServer != nil && cluster.Spec.EtcdServer != nil {
+		if err := validateCertificateAuthorityImmutable("etcdServer.ca", old.Spec.EtcdServer.CA, cluster.Spec.EtcdServer.CA); err != nil {
+			return err
+		}
+	}
+	if old.Spec.APIServer != nil && cluster.Spec.APIServer != nil {
+		if err := validateCertificateAuthorityImmutable("apiServer.ca", old.Spec.APIServer.CA, cluster.Spec.APIServer.CA); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateCertificateAuthorityImmutable(fieldName string, old, new *commonv1alpha1.Certificate) error {
+	if old == nil || new == nil || old.Certificate == new.Certificate {
+		return nil
+	}
+	return errors.Errorf("%s is immutable once issued; use `oi cluster rotate-ca` to rotate it", fieldName)
+}
+
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (cluster *Cluster) ValidateDelete() error {
 	klog.Info("validate delete", "name", cluster.Name)
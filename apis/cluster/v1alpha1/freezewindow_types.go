@@ -0,0 +1,86 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FreezeWindowSpec defines the desired state of FreezeWindow
+type FreezeWindowSpec struct {
+	// StartTime is when this freeze window starts blocking automated
+	// disruptive operations (certificate rotations, migrations)
+	// fleet-wide.
+	StartTime metav1.Time `json:"startTime"`
+
+	// EndTime is when this freeze window stops blocking automated
+	// disruptive operations. Must be after StartTime.
+	EndTime metav1.Time `json:"endTime"`
+
+	// ExemptClusters lists clusters that remain unaffected by this
+	// freeze window, so a cluster with its own maintenance schedule
+	// can keep rotating or migrating while the rest of the fleet is
+	// frozen.
+	//
+	// +optional
+	ExemptClusters []FreezeWindowExemptCluster `json:"exemptClusters,omitempty"`
+}
+
+// FreezeWindowExemptCluster identifies a cluster exempted from a
+// FreezeWindow
+type FreezeWindowExemptCluster struct {
+	// Namespace is the namespace of the exempt cluster
+	Namespace string `json:"namespace"`
+	// Name is the name of the exempt cluster
+	Name string `json:"name"`
+}
+
+// FreezeWindowStatus defines the observed state of FreezeWindow
+type FreezeWindowStatus struct {
+}
+
+// +kubebuilder:printcolumn:name="Start",type=date,JSONPath=`.spec.startTime`
+// +kubebuilder:printcolumn:name="End",type=date,JSONPath=`.spec.endTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// FreezeWindow is the Schema for the freezewindows API. While the
+// current time falls within any FreezeWindow's [StartTime, EndTime)
+// interval, controllers skip automated disruptive operations on every
+// cluster that is not listed in that window's ExemptClusters.
+type FreezeWindow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FreezeWindowSpec   `json:"spec,omitempty"`
+	Status FreezeWindowStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FreezeWindowList contains a list of FreezeWindow
+type FreezeWindowList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FreezeWindow `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FreezeWindow{}, &FreezeWindowList{})
+}
@@ -0,0 +1,81 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OneInfraConfigSpec defines the namespace-level defaults applied to
+// clusters created in the same namespace
+type OneInfraConfigSpec struct {
+	// ImageRepository overrides the default repository component
+	// images are pulled from
+	//
+	// +optional
+	ImageRepository string `json:"imageRepository,omitempty"`
+
+	// KubernetesVersion is the default Kubernetes version clusters
+	// without an explicit one resolve to
+	//
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// Size is the default sizing preset applied to clusters that
+	// don't specify one
+	//
+	// +optional
+	Size *ClusterSize `json:"size,omitempty"`
+
+	// VPNEnabled is the default for whether a cluster's VPN is
+	// enabled
+	//
+	// +optional
+	VPNEnabled *bool `json:"vpnEnabled,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Image repository",type=string,JSONPath=`.spec.imageRepository`
+// +kubebuilder:printcolumn:name="Kubernetes version",type=string,JSONPath=`.spec.kubernetesVersion`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:object:root=true
+
+// OneInfraConfig declares namespace-level defaults applied to
+// clusters created in that namespace by the defaulting webhook,
+// centralizing per-team policy instead of having every Cluster in a
+// namespace repeat it
+type OneInfraConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OneInfraConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OneInfraConfigList contains a list of OneInfraConfig
+type OneInfraConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OneInfraConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OneInfraConfig{}, &OneInfraConfigList{})
+}
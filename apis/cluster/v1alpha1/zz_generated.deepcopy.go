@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /**
@@ -22,41 +23,121 @@ package v1alpha1
 
 import (
 	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AddonImagePolicy) DeepCopyInto(out *AddonImagePolicy) {
+	*out = *in
+	if in.AllowedRegistries != nil {
+		in, out := &in.AllowedRegistries, &out.AllowedRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredDigests != nil {
+		in, out := &in.RequiredDigests, &out.RequiredDigests
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FrozenAddons != nil {
+		in, out := &in.FrozenAddons, &out.FrozenAddons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AddonImagePolicy.
+func (in *AddonImagePolicy) DeepCopy() *AddonImagePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AddonImagePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Baseline) DeepCopyInto(out *Baseline) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PriorityClasses != nil {
+		in, out := &in.PriorityClasses, &out.PriorityClasses
+		*out = make([]PriorityClass, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Baseline.
+func (in *Baseline) DeepCopy() *Baseline {
+	if in == nil {
+		return nil
+	}
+	out := new(Baseline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Authentication) DeepCopyInto(out *Authentication) {
+	*out = *in
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		*out = make([]JWTAuthenticator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Authentication.
+func (in *Authentication) DeepCopy() *Authentication {
+	if in == nil {
+		return nil
+	}
+	out := new(Authentication)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertificateAuthorities) DeepCopyInto(out *CertificateAuthorities) {
 	*out = *in
 	if in.APIServerClient != nil {
 		in, out := &in.APIServerClient, &out.APIServerClient
 		*out = new(commonv1alpha1.Certificate)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.CertificateSigner != nil {
 		in, out := &in.CertificateSigner, &out.CertificateSigner
 		*out = new(commonv1alpha1.Certificate)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Kubelet != nil {
 		in, out := &in.Kubelet, &out.Kubelet
 		*out = new(commonv1alpha1.Certificate)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.KubeletClient != nil {
 		in, out := &in.KubeletClient, &out.KubeletClient
 		*out = new(commonv1alpha1.Certificate)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.EtcdClient != nil {
 		in, out := &in.EtcdClient, &out.EtcdClient
 		*out = new(commonv1alpha1.Certificate)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.EtcdPeer != nil {
 		in, out := &in.EtcdPeer, &out.EtcdPeer
 		*out = new(commonv1alpha1.Certificate)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -97,6 +178,22 @@ func (in *Cluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterHistoryEntry) DeepCopyInto(out *ClusterHistoryEntry) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterHistoryEntry.
+func (in *ClusterHistoryEntry) DeepCopy() *ClusterHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterList) DeepCopyInto(out *ClusterList) {
 	*out = *in
@@ -170,18 +267,122 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	if in.JoinKey != nil {
 		in, out := &in.JoinKey, &out.JoinKey
 		*out = new(commonv1alpha1.KeyPair)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JoinBoxKey != nil {
+		in, out := &in.JoinBoxKey, &out.JoinBoxKey
+		*out = new(commonv1alpha1.KeyPair)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SigningKey != nil {
+		in, out := &in.SigningKey, &out.SigningKey
+		*out = new(commonv1alpha1.KeyPair)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.JoinTokens != nil {
 		in, out := &in.JoinTokens, &out.JoinTokens
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+		*out = make([]JoinToken, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Networking != nil {
 		in, out := &in.Networking, &out.Networking
 		*out = new(ClusterNetworking)
 		**out = **in
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(commonv1alpha1.Proxy)
+		**out = **in
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(HealthCheck)
+		**out = **in
+	}
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(UpgradeStrategy)
+		**out = **in
+	}
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(Authentication)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = new(Components)
+		**out = **in
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		*out = new(ClusterSize)
+		**out = **in
+	}
+	if in.SizeOverrides != nil {
+		in, out := &in.SizeOverrides, &out.SizeOverrides
+		*out = new(SizeOverrides)
+		**out = **in
+	}
+	if in.IngressTuning != nil {
+		in, out := &in.IngressTuning, &out.IngressTuning
+		*out = new(IngressTuning)
+		**out = **in
+	}
+	if in.ImageDigests != nil {
+		in, out := &in.ImageDigests, &out.ImageDigests
+		*out = new(ImageDigests)
+		**out = **in
+	}
+	if in.ImageSignaturePolicy != nil {
+		in, out := &in.ImageSignaturePolicy, &out.ImageSignaturePolicy
+		*out = new(commonv1alpha1.ImageSignaturePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AddonImagePolicy != nil {
+		in, out := &in.AddonImagePolicy, &out.AddonImagePolicy
+		*out = new(AddonImagePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		*out = new(Baseline)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertificateRotationThreshold != nil {
+		in, out := &in.CertificateRotationThreshold, &out.CertificateRotationThreshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExtraHostPathMounts != nil {
+		in, out := &in.ExtraHostPathMounts, &out.ExtraHostPathMounts
+		*out = make([]HostPathMount, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopyInto is a deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostPathMount) DeepCopyInto(out *HostPathMount) {
+	*out = *in
+}
+
+// DeepCopy is a deepcopy function, copying the receiver, creating a new HostPathMount.
+func (in *HostPathMount) DeepCopy() *HostPathMount {
+	if in == nil {
+		return nil
+	}
+	out := new(HostPathMount)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
@@ -201,7 +402,7 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		in, out := &in.ClientCertificates, &out.ClientCertificates
 		*out = make(map[string]commonv1alpha1.Certificate, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	if in.StorageClientEndpoints != nil {
@@ -221,12 +422,16 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 	if in.VPNPeers != nil {
 		in, out := &in.VPNPeers, &out.VPNPeers
 		*out = make([]VPNPeer, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.JoinTokens != nil {
 		in, out := &in.JoinTokens, &out.JoinTokens
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+		*out = make([]JoinTokenStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -235,6 +440,35 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(ClusterUpgradeStatus)
+		**out = **in
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]ClusterHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProvisioningTimestamps != nil {
+		in, out := &in.ProvisioningTimestamps, &out.ProvisioningTimestamps
+		*out = make(map[ProvisioningPhase]metav1.Time, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.Compliance != nil {
+		in, out := &in.Compliance, &out.Compliance
+		*out = new(ComplianceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(IngressStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
@@ -247,6 +481,72 @@ func (in *ClusterStatus) DeepCopy() *ClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterUpgradeStatus) DeepCopyInto(out *ClusterUpgradeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUpgradeStatus.
+func (in *ClusterUpgradeStatus) DeepCopy() *ClusterUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Components) DeepCopyInto(out *Components) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Components.
+func (in *Components) DeepCopy() *Components {
+	if in == nil {
+		return nil
+	}
+	out := new(Components)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceIssue) DeepCopyInto(out *ComplianceIssue) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceIssue.
+func (in *ComplianceIssue) DeepCopy() *ComplianceIssue {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceIssue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComplianceStatus) DeepCopyInto(out *ComplianceStatus) {
+	*out = *in
+	in.LastScanned.DeepCopyInto(&out.LastScanned)
+	if in.Issues != nil {
+		in, out := &in.Issues, &out.Issues
+		*out = make([]ComplianceIssue, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComplianceStatus.
+func (in *ComplianceStatus) DeepCopy() *ComplianceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComplianceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
@@ -348,14 +648,14 @@ func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
 		in, out := &in.ClientCertificates, &out.ClientCertificates
 		*out = make(map[string]commonv1alpha1.Certificate, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	if in.ServerCertificates != nil {
 		in, out := &in.ServerCertificates, &out.ServerCertificates
 		*out = make(map[string]commonv1alpha1.Certificate, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 	if in.InputEndpoints != nil {
@@ -397,6 +697,16 @@ func (in *EtcdServer) DeepCopyInto(out *EtcdServer) {
 	if in.CA != nil {
 		in, out := &in.CA, &out.CA
 		*out = new(commonv1alpha1.Certificate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(ExternalEtcd)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KineSQL != nil {
+		in, out := &in.KineSQL, &out.KineSQL
+		*out = new(KineSQLBackend)
 		**out = **in
 	}
 }
@@ -412,76 +722,600 @@ func (in *EtcdServer) DeepCopy() *EtcdServer {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubeAPIServer) DeepCopyInto(out *KubeAPIServer) {
+func (in *ExternalEtcd) DeepCopyInto(out *ExternalEtcd) {
 	*out = *in
-	if in.CA != nil {
-		in, out := &in.CA, &out.CA
-		*out = new(commonv1alpha1.Certificate)
-		**out = **in
-	}
-	if in.ServiceAccount != nil {
-		in, out := &in.ServiceAccount, &out.ServiceAccount
-		*out = new(commonv1alpha1.KeyPair)
-		**out = **in
-	}
-	if in.ExtraSANs != nil {
-		in, out := &in.ExtraSANs, &out.ExtraSANs
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(commonv1alpha1.Certificate)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeAPIServer.
-func (in *KubeAPIServer) DeepCopy() *KubeAPIServer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalEtcd.
+func (in *ExternalEtcd) DeepCopy() *ExternalEtcd {
 	if in == nil {
 		return nil
 	}
-	out := new(KubeAPIServer)
+	out := new(ExternalEtcd)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VPN) DeepCopyInto(out *VPN) {
+func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
 	*out = *in
-	if in.PrivateKey != nil {
-		in, out := &in.PrivateKey, &out.PrivateKey
-		*out = new(string)
-		**out = **in
-	}
-	if in.PublicKey != nil {
-		in, out := &in.PublicKey, &out.PublicKey
-		*out = new(string)
-		**out = **in
-	}
-	if in.CIDR != nil {
-		in, out := &in.CIDR, &out.CIDR
-		*out = new(string)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPN.
-func (in *VPN) DeepCopy() *VPN {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthCheck.
+func (in *HealthCheck) DeepCopy() *HealthCheck {
 	if in == nil {
 		return nil
 	}
-	out := new(VPN)
+	out := new(HealthCheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VPNPeer) DeepCopyInto(out *VPNPeer) {
+func (in *ImageDigests) DeepCopyInto(out *ImageDigests) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNPeer.
-func (in *VPNPeer) DeepCopy() *VPNPeer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageDigests.
+func (in *ImageDigests) DeepCopy() *ImageDigests {
 	if in == nil {
 		return nil
 	}
-	out := new(VPNPeer)
+	out := new(ImageDigests)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressBackendStatus) DeepCopyInto(out *IngressBackendStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressBackendStatus.
+func (in *IngressBackendStatus) DeepCopy() *IngressBackendStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressBackendStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressStatus) DeepCopyInto(out *IngressStatus) {
+	*out = *in
+	in.LastCollected.DeepCopyInto(&out.LastCollected)
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]IngressBackendStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressStatus.
+func (in *IngressStatus) DeepCopy() *IngressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressTuning) DeepCopyInto(out *IngressTuning) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressTuning.
+func (in *IngressTuning) DeepCopy() *IngressTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTAuthenticator) DeepCopyInto(out *JWTAuthenticator) {
+	*out = *in
+	in.Issuer.DeepCopyInto(&out.Issuer)
+	in.ClaimMappings.DeepCopyInto(&out.ClaimMappings)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTAuthenticator.
+func (in *JWTAuthenticator) DeepCopy() *JWTAuthenticator {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTAuthenticator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTClaimMappings) DeepCopyInto(out *JWTClaimMappings) {
+	*out = *in
+	out.Username = in.Username
+	out.Groups = in.Groups
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTClaimMappings.
+func (in *JWTClaimMappings) DeepCopy() *JWTClaimMappings {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTClaimMappings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTClaimOrPrefix) DeepCopyInto(out *JWTClaimOrPrefix) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTClaimOrPrefix.
+func (in *JWTClaimOrPrefix) DeepCopy() *JWTClaimOrPrefix {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTClaimOrPrefix)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTIssuer) DeepCopyInto(out *JWTIssuer) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTIssuer.
+func (in *JWTIssuer) DeepCopy() *JWTIssuer {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTIssuer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KineSQLBackend) DeepCopyInto(out *KineSQLBackend) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KineSQLBackend.
+func (in *KineSQLBackend) DeepCopy() *KineSQLBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(KineSQLBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeAPIServer) DeepCopyInto(out *KubeAPIServer) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(commonv1alpha1.Certificate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(commonv1alpha1.KeyPair)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraSANs != nil {
+		in, out := &in.ExtraSANs, &out.ExtraSANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuditLog != nil {
+		in, out := &in.AuditLog, &out.AuditLog
+		*out = new(AuditLog)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeAPIServer.
+func (in *KubeAPIServer) DeepCopy() *KubeAPIServer {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeAPIServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriorityClass) DeepCopyInto(out *PriorityClass) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriorityClass.
+func (in *PriorityClass) DeepCopy() *PriorityClass {
+	if in == nil {
+		return nil
+	}
+	out := new(PriorityClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SizeOverrides) DeepCopyInto(out *SizeOverrides) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SizeOverrides.
+func (in *SizeOverrides) DeepCopy() *SizeOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(SizeOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStrategy) DeepCopyInto(out *UpgradeStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStrategy.
+func (in *UpgradeStrategy) DeepCopy() *UpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPN) DeepCopyInto(out *VPN) {
+	*out = *in
+	if in.PrivateKey != nil {
+		in, out := &in.PrivateKey, &out.PrivateKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.PublicKey != nil {
+		in, out := &in.PublicKey, &out.PublicKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.CIDR != nil {
+		in, out := &in.CIDR, &out.CIDR
+		*out = new(string)
+		**out = **in
+	}
+	if in.PeerQuotas != nil {
+		in, out := &in.PeerQuotas, &out.PeerQuotas
+		*out = make(map[VPNPeerPurpose]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExternalEndpoint != nil {
+		in, out := &in.ExternalEndpoint, &out.ExternalEndpoint
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPN.
+func (in *VPN) DeepCopy() *VPN {
+	if in == nil {
+		return nil
+	}
+	out := new(VPN)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPNPeer) DeepCopyInto(out *VPNPeer) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPNPeer.
+func (in *VPNPeer) DeepCopy() *VPNPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(VPNPeer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JoinToken) DeepCopyInto(out *JoinToken) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JoinToken.
+func (in *JoinToken) DeepCopy() *JoinToken {
+	if in == nil {
+		return nil
+	}
+	out := new(JoinToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JoinTokenStatus) DeepCopyInto(out *JoinTokenStatus) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JoinTokenStatus.
+func (in *JoinTokenStatus) DeepCopy() *JoinTokenStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(JoinTokenStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneInfraConfig) DeepCopyInto(out *OneInfraConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneInfraConfig.
+func (in *OneInfraConfig) DeepCopy() *OneInfraConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OneInfraConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OneInfraConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneInfraConfigList) DeepCopyInto(out *OneInfraConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OneInfraConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneInfraConfigList.
+func (in *OneInfraConfigList) DeepCopy() *OneInfraConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(OneInfraConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OneInfraConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OneInfraConfigSpec) DeepCopyInto(out *OneInfraConfigSpec) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		*out = new(ClusterSize)
+		**out = **in
+	}
+	if in.VPNEnabled != nil {
+		in, out := &in.VPNEnabled, &out.VPNEnabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OneInfraConfigSpec.
+func (in *OneInfraConfigSpec) DeepCopy() *OneInfraConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OneInfraConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLog) DeepCopyInto(out *AuditLog) {
+	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(AuditWebhookSink)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLog.
+func (in *AuditLog) DeepCopy() *AuditLog {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditWebhookSink) DeepCopyInto(out *AuditWebhookSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditWebhookSink.
+func (in *AuditWebhookSink) DeepCopy() *AuditWebhookSink {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditWebhookSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindow) DeepCopyInto(out *FreezeWindow) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindow.
+func (in *FreezeWindow) DeepCopy() *FreezeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FreezeWindow) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindowExemptCluster) DeepCopyInto(out *FreezeWindowExemptCluster) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindowExemptCluster.
+func (in *FreezeWindowExemptCluster) DeepCopy() *FreezeWindowExemptCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindowExemptCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindowList) DeepCopyInto(out *FreezeWindowList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FreezeWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindowList.
+func (in *FreezeWindowList) DeepCopy() *FreezeWindowList {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindowList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FreezeWindowList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindowSpec) DeepCopyInto(out *FreezeWindowSpec) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	in.EndTime.DeepCopyInto(&out.EndTime)
+	if in.ExemptClusters != nil {
+		in, out := &in.ExemptClusters, &out.ExemptClusters
+		*out = make([]FreezeWindowExemptCluster, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindowSpec.
+func (in *FreezeWindowSpec) DeepCopy() *FreezeWindowSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindowSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FreezeWindowStatus) DeepCopyInto(out *FreezeWindowStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FreezeWindowStatus.
+func (in *FreezeWindowStatus) DeepCopy() *FreezeWindowStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FreezeWindowStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -0,0 +1,81 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1beta1
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+// certificateAuthoritiesAnnotation stashes the inline certificate
+// authorities of a v1alpha1 Cluster as JSON, so a round trip through
+// v1beta1 and back to v1alpha1 does not lose certificate material
+// that this version only carries a Secret reference for. Never set
+// on a Cluster created directly as v1beta1, which has no inline
+// certificates to lose in the first place
+const certificateAuthoritiesAnnotation = "v1beta1.cluster.oneinfra.ereslibre.es/certificate-authorities"
+
+var _ conversion.Convertible = &Cluster{}
+
+// ConvertTo converts this Cluster to the Hub version (v1alpha1)
+func (cluster *Cluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*clusterv1alpha1.Cluster)
+	dst.ObjectMeta = cluster.ObjectMeta
+	dst.Spec = cluster.Spec.ClusterSpec
+	dst.Spec.CertificateAuthorities = nil
+	dst.Status = cluster.Status
+	if stashed, exists := cluster.Annotations[certificateAuthoritiesAnnotation]; exists {
+		certificateAuthorities := &clusterv1alpha1.CertificateAuthorities{}
+		if err := json.Unmarshal([]byte(stashed), certificateAuthorities); err != nil {
+			return errors.Wrap(err, "could not restore stashed certificateAuthorities")
+		}
+		dst.Spec.CertificateAuthorities = certificateAuthorities
+		delete(dst.Annotations, certificateAuthoritiesAnnotation)
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this
+// version. The inline certificate authorities of src are stashed in
+// an annotation, since this version only carries a Secret reference
+// for them; a cluster created directly as v1beta1 is expected to
+// have already provisioned that Secret out of band, and is left with
+// an empty reference here if it has not
+func (cluster *Cluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*clusterv1alpha1.Cluster)
+	cluster.ObjectMeta = src.ObjectMeta
+	cluster.Spec.ClusterSpec = src.Spec
+	cluster.Spec.ClusterSpec.CertificateAuthorities = nil
+	cluster.Spec.CertificateAuthorities = nil
+	cluster.Status = src.Status
+	if src.Spec.CertificateAuthorities == nil {
+		return nil
+	}
+	stashed, err := json.Marshal(src.Spec.CertificateAuthorities)
+	if err != nil {
+		return errors.Wrap(err, "could not stash certificateAuthorities")
+	}
+	if cluster.Annotations == nil {
+		cluster.Annotations = map[string]string{}
+	}
+	cluster.Annotations[certificateAuthoritiesAnnotation] = string(stashed)
+	return nil
+}
@@ -0,0 +1,84 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+)
+
+// ClusterSpec defines the desired state of Cluster. It inlines
+// v1alpha1.ClusterSpec and only overrides CertificateAuthorities,
+// which here references the Secret holding the cluster's CA bundle
+// instead of embedding its certificates inline
+type ClusterSpec struct {
+	clusterv1alpha1.ClusterSpec `json:",inline"`
+
+	// +optional
+	CertificateAuthorities *CertificateAuthorities `json:"certificateAuthorities,omitempty"`
+}
+
+// CertificateAuthorities represents a set of Certificate Authorities,
+// each referencing the Secret holding its certificate material
+type CertificateAuthorities struct {
+	// +optional
+	APIServerClient *commonv1alpha1.CertificateReference `json:"apiServerClient,omitempty"`
+	// +optional
+	CertificateSigner *commonv1alpha1.CertificateReference `json:"certificateSigner,omitempty"`
+	// +optional
+	Kubelet *commonv1alpha1.CertificateReference `json:"kubelet,omitempty"`
+	// +optional
+	KubeletClient *commonv1alpha1.CertificateReference `json:"kubeletClient,omitempty"`
+	// +optional
+	EtcdClient *commonv1alpha1.CertificateReference `json:"etcdClient,omitempty"`
+	// +optional
+	EtcdPeer *commonv1alpha1.CertificateReference `json:"etcdPeer,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +kubebuilder:printcolumn:name="Kubernetes version",type=string,JSONPath=`.spec.kubernetesVersion`
+// +kubebuilder:printcolumn:name="API server endpoint",type=string,JSONPath=`.status.apiServerEndpoint`
+// +kubebuilder:printcolumn:name="VPN",type=boolean,JSONPath=`.spec.vpn.enabled`
+// +kubebuilder:printcolumn:name="VPN CIDR",type=string,JSONPath=`.spec.vpn.CIDR`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// Cluster is the Schema for the clusters API
+type Cluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterSpec                   `json:"spec,omitempty"`
+	Status clusterv1alpha1.ClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterList contains a list of Cluster
+type ClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
+}
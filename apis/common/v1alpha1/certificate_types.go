@@ -16,6 +16,10 @@
 
 package v1alpha1
 
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // Certificate represents a Certificate
 type Certificate struct {
 	// Certificate is a PEM encoded certificate
@@ -23,6 +27,22 @@ type Certificate struct {
 
 	// PrivateKey is a PEM encoded private key
 	PrivateKey string `json:"privateKey,omitempty"`
+
+	// Provenance tracks the origin of this certificate
+	//
+	// +optional
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// CertificateReference points at a Kubernetes Secret holding a
+// certificate and its private key, instead of embedding them inline.
+// The referenced Secret is expected to be of type
+// "kubernetes.io/tls", carrying "tls.crt" and "tls.key" keys, and to
+// live in the same namespace as the object referencing it
+type CertificateReference struct {
+	// SecretName is the name of the Secret holding this certificate's
+	// material
+	SecretName string `json:"secretName,omitempty"`
 }
 
 // KeyPair represents a public/private key pair
@@ -32,4 +52,29 @@ type KeyPair struct {
 
 	// PrivateKey is a PEM encoded private key
 	PrivateKey string `json:"privateKey,omitempty"`
+
+	// Provenance tracks the origin of this key pair
+	//
+	// +optional
+	Provenance *Provenance `json:"provenance,omitempty"`
+}
+
+// Provenance records who generated an artifact (a certificate, key
+// pair, kubeconfig or component config) and when, so stale artifacts
+// can be identified after a bug fix ships in a newer oneinfra version
+type Provenance struct {
+	// GeneratorVersion is the oneinfra version that generated this
+	// artifact
+	GeneratorVersion string `json:"generatorVersion,omitempty"`
+
+	// GeneratedAt is the time at which this artifact was generated
+	GeneratedAt metav1.Time `json:"generatedAt,omitempty"`
+}
+
+// NewProvenance returns a Provenance generated now by generatorVersion
+func NewProvenance(generatorVersion string) *Provenance {
+	return &Provenance{
+		GeneratorVersion: generatorVersion,
+		GeneratedAt:      metav1.Now(),
+	}
 }
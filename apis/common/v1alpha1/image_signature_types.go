@@ -0,0 +1,37 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+// ImageSignaturePolicy requires component images to pass cosign
+// signature verification against the configured public keys before
+// the reconciler starts containers from them. An image is accepted
+// as soon as it verifies against any one of PublicKeys
+type ImageSignaturePolicy struct {
+	// PublicKeys is the list of cosign PEM encoded public keys that
+	// component images are verified against. A policy with no public
+	// keys verifies nothing
+	//
+	// +optional
+	PublicKeys []string `json:"publicKeys,omitempty"`
+}
+
+// Enabled returns whether this policy requires signature
+// verification. A nil policy, or one with no configured public keys,
+// requires no verification
+func (imageSignaturePolicy *ImageSignaturePolicy) Enabled() bool {
+	return imageSignaturePolicy != nil && len(imageSignaturePolicy.PublicKeys) > 0
+}
@@ -0,0 +1,63 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+import (
+	"strings"
+)
+
+// Proxy represents the egress proxy settings that apply to
+// component containers, honoring corporate environments that
+// mandate proxying all outbound traffic
+type Proxy struct {
+	// HTTPProxy is the proxy used for plain HTTP requests
+	//
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the proxy used for HTTPS requests
+	//
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is a comma separated list of hosts that should be
+	// reached directly, bypassing the proxy
+	//
+	// +optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// Env returns this proxy configuration as a map of environment
+// variables, following the conventional HTTP_PROXY/HTTPS_PROXY/
+// NO_PROXY names and their lowercase counterparts
+func (proxy *Proxy) Env() map[string]string {
+	if proxy == nil {
+		return map[string]string{}
+	}
+	env := map[string]string{}
+	addIfSet := func(name, value string) {
+		if value == "" {
+			return
+		}
+		env[name] = value
+		env[strings.ToLower(name)] = value
+	}
+	addIfSet("HTTP_PROXY", proxy.HTTPProxy)
+	addIfSet("HTTPS_PROXY", proxy.HTTPSProxy)
+	addIfSet("NO_PROXY", proxy.NoProxy)
+	return env
+}
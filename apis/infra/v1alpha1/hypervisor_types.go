@@ -26,20 +26,29 @@ import (
 type HypervisorSpec struct {
 	// LocalCRIEndpoint is the unix socket where this hypervisor is
 	// reachable. This is only intended for development and testing
-	// purposes. On production environments RemoteCRIEndpoint should be
-	// used. Either a LocalCRIEndpoint or a RemoteCRIEndpoint has to be
-	// provided.
+	// purposes. On production environments RemoteCRIEndpoint or
+	// SSHCRIEndpoint should be used. Exactly one of LocalCRIEndpoint,
+	// RemoteCRIEndpoint or SSHCRIEndpoint has to be provided.
 	//
 	// +optional
 	LocalCRIEndpoint *LocalHypervisorCRIEndpoint `json:"localCRIEndpoint,omitempty"`
 
 	// RemoteCRIEndpoint is the TCP address where this hypervisor is
-	// reachable. Either a LocalCRIEndpoint or a RemoteCRIEndpoint has
-	// to be provided.
+	// reachable. Exactly one of LocalCRIEndpoint, RemoteCRIEndpoint or
+	// SSHCRIEndpoint has to be provided.
 	//
 	// +optional
 	RemoteCRIEndpoint *RemoteHypervisorCRIEndpoint `json:"remoteCRIEndpoint,omitempty"`
 
+	// SSHCRIEndpoint reaches this hypervisor's CRI unix socket by
+	// forwarding it over an SSH connection, for hypervisors that only
+	// expose SSH rather than a directly TLS-authenticated CRI
+	// endpoint. Exactly one of LocalCRIEndpoint, RemoteCRIEndpoint or
+	// SSHCRIEndpoint has to be provided.
+	//
+	// +optional
+	SSHCRIEndpoint *SSHHypervisorCRIEndpoint `json:"sshCRIEndpoint,omitempty"`
+
 	// Public hypervisors will be scheduled cluster ingress components,
 	// whereas private hypervisors will be scheduled the control plane
 	// components themselves.
@@ -52,6 +61,179 @@ type HypervisorSpec struct {
 	// PortRange is the port range to be used for allocating exposed
 	// components.
 	PortRange HypervisorPortRange `json:"portRange,omitempty"`
+
+	// Reservations constrains the resources that scheduled components
+	// are allowed to consume on this hypervisor, so that the rest
+	// stays available to other workloads sharing the same hypervisor.
+	// Unset means no constraint is applied.
+	//
+	// +optional
+	Reservations *HypervisorReservations `json:"reservations,omitempty"`
+
+	// Proxy holds the egress proxy settings that will be injected
+	// into every component container scheduled on this hypervisor,
+	// overriding the cluster level proxy settings, if any.
+	//
+	// +optional
+	Proxy *commonv1alpha1.Proxy `json:"proxy,omitempty"`
+
+	// EtcdDataDir overrides the host directory where etcd data for
+	// components scheduled on this hypervisor is stored, useful to
+	// place it on faster local storage (e.g. an NVMe mount) than the
+	// default location. A cluster and component subdirectory is
+	// created underneath it. Unset keeps the built-in default
+	// location.
+	//
+	// +optional
+	EtcdDataDir string `json:"etcdDataDir,omitempty"`
+
+	// SELinux declares the SELinux labeling options that apply to
+	// files and containers provisioned on this hypervisor. This is a
+	// declaration of the host's security profile rather than a live
+	// probe, since the manager only ever talks to a hypervisor
+	// through its CRI endpoint, which exposes no way to introspect
+	// the host's SELinux enforcement mode. Populate this when the
+	// hypervisor host runs with SELinux in enforcing mode (e.g.
+	// RHEL/Fedora), to avoid silent permission failures when
+	// components start up. Unset means the host is assumed to run
+	// without SELinux enforcement.
+	//
+	// +optional
+	SELinux *HypervisorSELinux `json:"seLinux,omitempty"`
+
+	// ImageSignaturePolicy, when set, overrides the cluster level
+	// image signature policy for components scheduled on this
+	// hypervisor.
+	//
+	// +optional
+	ImageSignaturePolicy *commonv1alpha1.ImageSignaturePolicy `json:"imageSignaturePolicy,omitempty"`
+
+	// CRILimits constrains how fast the manager is allowed to talk to
+	// this hypervisor's CRI endpoint. Unset means no rate limiting or
+	// circuit breaking is applied.
+	//
+	// +optional
+	CRILimits *HypervisorCRILimits `json:"criLimits,omitempty"`
+
+	// Attestation, when set, requires this hypervisor's
+	// RemoteCRIEndpoint client certificate to chain up to one of the
+	// pinned trusted certificate authorities before the manager
+	// establishes any connection to it, so a Hypervisor object
+	// pointing at a rogue host cannot be trusted into the fleet with
+	// a client certificate of its own choosing. Only meaningful
+	// together with RemoteCRIEndpoint; ignored for LocalCRIEndpoint,
+	// which is for development and testing purposes only. Unset
+	// performs no attestation, trusting the certificate embedded in
+	// this Hypervisor object as-is.
+	//
+	// +optional
+	Attestation *HypervisorAttestation `json:"attestation,omitempty"`
+
+	// AllowedExtraHostPathMountPrefixes restricts which host paths a
+	// cluster's ExtraHostPathMounts may bind mount from this
+	// hypervisor. A component requesting an extra host path mount not
+	// covered by one of these prefixes is not scheduled on this
+	// hypervisor. Unset, or empty, allows no extra host path mounts at
+	// all, so this allow-list has to be opted into explicitly.
+	//
+	// +optional
+	AllowedExtraHostPathMountPrefixes []string `json:"allowedExtraHostPathMountPrefixes,omitempty"`
+
+	// Unschedulable marks this hypervisor as cordoned: the placement
+	// scheduler will not place any new component on it, although
+	// components already scheduled here keep running until they are
+	// individually drained or deleted. Set this ahead of maintenance,
+	// then use `oi hypervisor drain` to move its existing components
+	// elsewhere.
+	//
+	// +optional
+	Unschedulable bool `json:"unschedulable,omitempty"`
+}
+
+// HypervisorCRILimits represents the client side rate limiting and
+// circuit breaking applied to the CRI connection established with an
+// hypervisor
+type HypervisorCRILimits struct {
+	// QPS is the maximum number of CRI requests per second the
+	// manager will issue against this hypervisor. Unset, or zero,
+	// means no rate limiting is applied.
+	//
+	// +optional
+	QPS float64 `json:"qps,omitempty"`
+
+	// Burst is the maximum number of CRI requests the manager is
+	// allowed to burst above QPS before being throttled. Defaults to
+	// the rounded up value of QPS when left unset.
+	//
+	// +optional
+	Burst int `json:"burst,omitempty"`
+
+	// CircuitBreakerThreshold is the number of consecutive failed CRI
+	// requests after which the manager stops talking to this
+	// hypervisor and fails fast, instead of continuing to pile up
+	// requests against an endpoint that is not responding. Unset, or
+	// zero, disables the circuit breaker.
+	//
+	// +optional
+	CircuitBreakerThreshold int `json:"circuitBreakerThreshold,omitempty"`
+}
+
+// HypervisorSELinux represents the SELinux labeling options to apply
+// to files and containers provisioned on a hypervisor
+type HypervisorSELinux struct {
+	// Type is the SELinux type that will be applied to containers
+	// and the bind mounts they receive. Defaults to "container_t"
+	// when left empty.
+	//
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Level is the SELinux MCS level that will be applied to
+	// containers and the bind mounts they receive. Left empty, the
+	// container runtime's own level allocation applies.
+	//
+	// +optional
+	Level string `json:"level,omitempty"`
+}
+
+// HypervisorAttestation pins the certificate authorities a
+// hypervisor's identity is attested against before it is trusted
+type HypervisorAttestation struct {
+	// TrustedCACertificates is a bundle of PEM encoded CA certificates
+	// the hypervisor's RemoteCRIEndpoint client certificate must
+	// chain up to. A hypervisor whose client certificate cannot be
+	// verified against any of these authorities is refused a
+	// connection.
+	TrustedCACertificates []string `json:"trustedCACertificates,omitempty"`
+}
+
+// HypervisorReservations represents the resources that oneinfra
+// components are confined to on a shared hypervisor
+type HypervisorReservations struct {
+	// CPUSet is the list of CPUs that oneinfra components are allowed
+	// to use on this hypervisor, expressed in Linux cpuset syntax
+	// (e.g. "0-1,3"). The remaining CPUs are left for other
+	// workloads. Unset means no CPU constraint is applied.
+	//
+	// +optional
+	CPUSet string `json:"cpuSet,omitempty"`
+
+	// MemoryMB is the amount of memory, in megabytes, that oneinfra
+	// components are allowed to use in total on this hypervisor.
+	// Unset means no memory constraint is applied.
+	//
+	// +optional
+	MemoryMB int64 `json:"memoryMB,omitempty"`
+
+	// MaxComponents caps the placement scheduler's load measure for
+	// this hypervisor: the number of host ports allocated on it. A
+	// component holding several host ports counts once per port, so
+	// this is not a count of components. Unset or zero means no
+	// cap is applied, and this hypervisor is always considered to
+	// have room.
+	//
+	// +optional
+	MaxComponents int `json:"maxComponents,omitempty"`
 }
 
 // FileMap is a map of file paths as keys and their sum as values
@@ -89,6 +271,30 @@ type RemoteHypervisorCRIEndpoint struct {
 	ClientCertificate *commonv1alpha1.Certificate `json:"clientCertificate,omitempty"`
 }
 
+// SSHHypervisorCRIEndpoint represents a remote hypervisor CRI
+// endpoint reached by forwarding its CRI unix socket over an SSH
+// connection, for hypervisors that only expose SSH
+type SSHHypervisorCRIEndpoint struct {
+	// Address is the address (host:port) of the SSH server to connect to
+	Address string `json:"address,omitempty"`
+
+	// User is the username to authenticate as
+	User string `json:"user,omitempty"`
+
+	// PrivateKey is the PEM encoded private key used to authenticate
+	// against this hypervisor, specific to it
+	PrivateKey string `json:"privateKey,omitempty"`
+
+	// HostPublicKey is the authorized_keys encoded public key this
+	// hypervisor's SSH host key is verified against. A connection to
+	// a host presenting a different key is refused.
+	HostPublicKey string `json:"hostPublicKey,omitempty"`
+
+	// RemoteCRISocket is the path to the CRI unix socket on the
+	// remote hypervisor, forwarded over the SSH connection
+	RemoteCRISocket string `json:"remoteCRISocket,omitempty"`
+}
+
 // HypervisorStatus defines the observed state of Hypervisor
 type HypervisorStatus struct {
 	// AllocatedPorts is a list of hypervisor allocated ports
@@ -97,6 +303,78 @@ type HypervisorStatus struct {
 	// components have been deleted
 	FreedPorts []int                    `json:"freedPorts,omitempty"`
 	Files      NamespacedClusterFileMap `json:"files,omitempty"`
+
+	// PendingComponentTransactions tracks components whose creation
+	// was started but not yet confirmed complete on this hypervisor.
+	// A reconcile that crashes mid-creation leaves an entry behind,
+	// so the next reconcile can detect and roll back the partial
+	// pod, containers, ports and files instead of leaking them.
+	//
+	// +optional
+	PendingComponentTransactions []ComponentTransaction `json:"pendingComponentTransactions,omitempty"`
+
+	// PodRestarts tracks the auto-repair restart attempts for pods
+	// found with containers not all running on this hypervisor, so
+	// repeated crashes are retried with exponential backoff and
+	// eventually stop being retried once a maximum number of
+	// attempts is reached.
+	//
+	// +optional
+	PodRestarts []PodRestart `json:"podRestarts,omitempty"`
+
+	// PreflightChecks holds the outcome of the host preflight checks
+	// (kernel version, wireguard module, conntrack settings, cgroup
+	// v2, open file limits) run against this hypervisor. A
+	// hypervisor with no preflight checks recorded yet has not been
+	// probed; one with any failing check should not be scheduled
+	// workloads until it is remediated.
+	//
+	// +optional
+	PreflightChecks []PreflightCheckResult `json:"preflightChecks,omitempty"`
+}
+
+// PreflightCheckResult represents the outcome of a single host
+// preflight check run against an hypervisor
+type PreflightCheckResult struct {
+	// Name identifies the preflight check this result belongs to
+	Name string `json:"name,omitempty"`
+
+	// Passed is whether the check succeeded
+	Passed bool `json:"passed,omitempty"`
+
+	// RemediationHint is a machine-readable hint on how to fix the
+	// host, populated when Passed is false
+	//
+	// +optional
+	RemediationHint string `json:"remediationHint,omitempty"`
+}
+
+// ComponentTransaction identifies an in-progress component
+// provisioning attempt on an hypervisor
+type ComponentTransaction struct {
+	ClusterNamespace string `json:"clusterNamespace,omitempty"`
+	Cluster          string `json:"cluster,omitempty"`
+	Component        string `json:"component,omitempty"`
+}
+
+// PodRestart tracks the auto-repair restart attempts performed for a
+// single pod on an hypervisor, after it was found with containers not
+// all running
+type PodRestart struct {
+	ClusterNamespace string `json:"clusterNamespace,omitempty"`
+	Cluster          string `json:"cluster,omitempty"`
+	Component        string `json:"component,omitempty"`
+	Pod              string `json:"pod,omitempty"`
+
+	// Attempts is the number of times this pod has been automatically
+	// recreated after being found with containers not all running
+	Attempts int `json:"attempts,omitempty"`
+
+	// LastRestartTime is the last time this pod was automatically
+	// recreated
+	//
+	// +optional
+	LastRestartTime *metav1.Time `json:"lastRestartTime,omitempty"`
 }
 
 // HypervisorPortRange represents a port range
@@ -0,0 +1,114 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// hypervisorWebhookClient is used by the validating webhook to list
+// other hypervisors when checking for port range overlaps.
+// webhook.Validator's ValidateCreate()/ValidateUpdate() have no
+// access to the manager that set them up, so SetupWebhookWithManager
+// stashes a client here
+var hypervisorWebhookClient client.Client
+
+// SetupWebhookWithManager registers this web hook on the given
+// manager instance
+func (hypervisor *Hypervisor) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	hypervisorWebhookClient = mgr.GetClient()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(hypervisor).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-infra-oneinfra-ereslibre-es-v1alpha1-hypervisor,mutating=false,failurePolicy=fail,groups=infra.oneinfra.ereslibre.es,resources=hypervisors,versions=v1alpha1,name=vhypervisor.kb.io
+
+var _ webhook.Validator = &Hypervisor{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (hypervisor *Hypervisor) ValidateCreate() error {
+	klog.Info("validate create", "name", hypervisor.Name)
+	if err := hypervisor.validatePortRange(); err != nil {
+		return err
+	}
+	return hypervisor.validatePortRangeDoesNotOverlap()
+}
+
+// validatePortRange rejects a port range that could never hand out a
+// port, instead of letting every component placed on this hypervisor
+// fail to schedule later on
+func (hypervisor *Hypervisor) validatePortRange() error {
+	if hypervisor.Spec.PortRange.Low <= 0 || hypervisor.Spec.PortRange.High <= 0 {
+		return errors.Errorf("portRange.low and portRange.high must both be set on hypervisor %q", hypervisor.Name)
+	}
+	if hypervisor.Spec.PortRange.Low > hypervisor.Spec.PortRange.High {
+		return errors.Errorf("portRange.low (%d) must not be greater than portRange.high (%d) on hypervisor %q", hypervisor.Spec.PortRange.Low, hypervisor.Spec.PortRange.High, hypervisor.Name)
+	}
+	return nil
+}
+
+// validatePortRangeDoesNotOverlap rejects a port range that overlaps
+// with the port range of another hypervisor sharing the same IP
+// address, since components scheduled on either one would otherwise
+// be assigned colliding host ports on the same machine. A no-op if
+// the webhook has no client available, or if this hypervisor has no
+// IP address set yet
+func (hypervisor *Hypervisor) validatePortRangeDoesNotOverlap() error {
+	if hypervisorWebhookClient == nil || hypervisor.Spec.IPAddress == "" {
+		return nil
+	}
+	hypervisorList := &HypervisorList{}
+	if err := hypervisorWebhookClient.List(context.Background(), hypervisorList); err != nil {
+		klog.Errorf("could not list hypervisors to check for overlapping portRange: %v", err)
+		return nil
+	}
+	for _, otherHypervisor := range hypervisorList.Items {
+		if otherHypervisor.Name == hypervisor.Name {
+			continue
+		}
+		if otherHypervisor.Spec.IPAddress != hypervisor.Spec.IPAddress {
+			continue
+		}
+		if hypervisor.Spec.PortRange.Low <= otherHypervisor.Spec.PortRange.High && otherHypervisor.Spec.PortRange.Low <= hypervisor.Spec.PortRange.High {
+			return errors.Errorf("portRange [%d, %d] overlaps with hypervisor %q's portRange [%d, %d] on the same IP address %q", hypervisor.Spec.PortRange.Low, hypervisor.Spec.PortRange.High, otherHypervisor.Name, otherHypervisor.Spec.PortRange.Low, otherHypervisor.Spec.PortRange.High, hypervisor.Spec.IPAddress)
+		}
+	}
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (hypervisor *Hypervisor) ValidateUpdate(old runtime.Object) error {
+	klog.Info("validate update", "name", hypervisor.Name)
+	if err := hypervisor.validatePortRange(); err != nil {
+		return err
+	}
+	return hypervisor.validatePortRangeDoesNotOverlap()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (hypervisor *Hypervisor) ValidateDelete() error {
+	klog.Info("validate delete", "name", hypervisor.Name)
+	return nil
+}
@@ -0,0 +1,98 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HypervisorPoolSpec defines the desired state of HypervisorPool
+type HypervisorPoolSpec struct {
+	// HypervisorNames is the list of Hypervisor names that belong to
+	// this pool. A Cluster referencing this pool will only be
+	// scheduled on these hypervisors, guaranteeing that the tenant
+	// never lands on hypervisors serving the general fleet.
+	HypervisorNames []string `json:"hypervisorNames,omitempty"`
+
+	// SchedulingStrategy picks how new components are placed among
+	// this pool's hypervisors. "Spread" (the default when unset)
+	// places each new component on the least loaded hypervisor.
+	// "BinPack" places it on the most loaded hypervisor that still
+	// has room, according to its Reservations.MaxComponents, before
+	// spilling over to an idle one. "Webhook" defers the decision to
+	// PlacementWebhook instead.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Spread;BinPack;Webhook
+	SchedulingStrategy string `json:"schedulingStrategy,omitempty"`
+
+	// PlacementWebhook, when SchedulingStrategy is "Webhook", is
+	// called with the list of candidate hypervisors for each
+	// unscheduled component, and picks which one it is placed on.
+	// This lets an organization plug in its own placement policy
+	// without forking oneinfra.
+	//
+	// +optional
+	PlacementWebhook *PlacementWebhook `json:"placementWebhook,omitempty"`
+}
+
+// PlacementWebhook points to an external HTTP service consulted by
+// the "Webhook" scheduling strategy
+type PlacementWebhook struct {
+	// URL is the endpoint the component scheduler POSTs the placement
+	// request to. It is expected to respond within Timeout with the
+	// name of the chosen hypervisor.
+	URL string `json:"url"`
+
+	// TimeoutSeconds bounds how long the scheduler waits for the
+	// webhook to respond before falling back to the Spread strategy.
+	// Defaults to 10 seconds when unset.
+	//
+	// +optional
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// HypervisorPoolStatus defines the observed state of HypervisorPool
+type HypervisorPoolStatus struct {
+}
+
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:subresource:status
+
+// HypervisorPool is the Schema for the hypervisorpools API
+type HypervisorPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HypervisorPoolSpec   `json:"spec,omitempty"`
+	Status HypervisorPoolStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HypervisorPoolList contains a list of HypervisorPool
+type HypervisorPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HypervisorPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HypervisorPool{}, &HypervisorPoolList{})
+}
@@ -1,3 +1,4 @@
+//go:build !ignore_autogenerated
 // +build !ignore_autogenerated
 
 /**
@@ -97,6 +98,21 @@ func (in ComponentFileMap) DeepCopy() ComponentFileMap {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentTransaction) DeepCopyInto(out *ComponentTransaction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentTransaction.
+func (in *ComponentTransaction) DeepCopy() *ComponentTransaction {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentTransaction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in FileMap) DeepCopyInto(out *FileMap) {
 	{
@@ -177,6 +193,105 @@ func (in *HypervisorList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypervisorPool) DeepCopyInto(out *HypervisorPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorPool.
+func (in *HypervisorPool) DeepCopy() *HypervisorPool {
+	if in == nil {
+		return nil
+	}
+	out := new(HypervisorPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HypervisorPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypervisorPoolList) DeepCopyInto(out *HypervisorPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HypervisorPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorPoolList.
+func (in *HypervisorPoolList) DeepCopy() *HypervisorPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(HypervisorPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HypervisorPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypervisorPoolSpec) DeepCopyInto(out *HypervisorPoolSpec) {
+	*out = *in
+	if in.HypervisorNames != nil {
+		in, out := &in.HypervisorNames, &out.HypervisorNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PlacementWebhook != nil {
+		in, out := &in.PlacementWebhook, &out.PlacementWebhook
+		*out = new(PlacementWebhook)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorPoolSpec.
+func (in *HypervisorPoolSpec) DeepCopy() *HypervisorPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HypervisorPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypervisorPoolStatus) DeepCopyInto(out *HypervisorPoolStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorPoolStatus.
+func (in *HypervisorPoolStatus) DeepCopy() *HypervisorPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HypervisorPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HypervisorPortAllocation) DeepCopyInto(out *HypervisorPortAllocation) {
 	*out = *in
@@ -207,6 +322,21 @@ func (in *HypervisorPortRange) DeepCopy() *HypervisorPortRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypervisorReservations) DeepCopyInto(out *HypervisorReservations) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorReservations.
+func (in *HypervisorReservations) DeepCopy() *HypervisorReservations {
+	if in == nil {
+		return nil
+	}
+	out := new(HypervisorReservations)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HypervisorSpec) DeepCopyInto(out *HypervisorSpec) {
 	*out = *in
@@ -220,7 +350,97 @@ func (in *HypervisorSpec) DeepCopyInto(out *HypervisorSpec) {
 		*out = new(RemoteHypervisorCRIEndpoint)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SSHCRIEndpoint != nil {
+		in, out := &in.SSHCRIEndpoint, &out.SSHCRIEndpoint
+		*out = new(SSHHypervisorCRIEndpoint)
+		**out = **in
+	}
 	out.PortRange = in.PortRange
+	if in.Reservations != nil {
+		in, out := &in.Reservations, &out.Reservations
+		*out = new(HypervisorReservations)
+		**out = **in
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(commonv1alpha1.Proxy)
+		**out = **in
+	}
+	if in.SELinux != nil {
+		in, out := &in.SELinux, &out.SELinux
+		*out = new(HypervisorSELinux)
+		**out = **in
+	}
+	if in.ImageSignaturePolicy != nil {
+		in, out := &in.ImageSignaturePolicy, &out.ImageSignaturePolicy
+		*out = new(commonv1alpha1.ImageSignaturePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CRILimits != nil {
+		in, out := &in.CRILimits, &out.CRILimits
+		*out = new(HypervisorCRILimits)
+		**out = **in
+	}
+	if in.Attestation != nil {
+		in, out := &in.Attestation, &out.Attestation
+		*out = new(HypervisorAttestation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedExtraHostPathMountPrefixes != nil {
+		in, out := &in.AllowedExtraHostPathMountPrefixes, &out.AllowedExtraHostPathMountPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypervisorCRILimits) DeepCopyInto(out *HypervisorCRILimits) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorCRILimits.
+func (in *HypervisorCRILimits) DeepCopy() *HypervisorCRILimits {
+	if in == nil {
+		return nil
+	}
+	out := new(HypervisorCRILimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypervisorAttestation) DeepCopyInto(out *HypervisorAttestation) {
+	*out = *in
+	if in.TrustedCACertificates != nil {
+		in, out := &in.TrustedCACertificates, &out.TrustedCACertificates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorAttestation.
+func (in *HypervisorAttestation) DeepCopy() *HypervisorAttestation {
+	if in == nil {
+		return nil
+	}
+	out := new(HypervisorAttestation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HypervisorSELinux) DeepCopyInto(out *HypervisorSELinux) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorSELinux.
+func (in *HypervisorSELinux) DeepCopy() *HypervisorSELinux {
+	if in == nil {
+		return nil
+	}
+	out := new(HypervisorSELinux)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorSpec.
@@ -283,6 +503,23 @@ func (in *HypervisorStatus) DeepCopyInto(out *HypervisorStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.PendingComponentTransactions != nil {
+		in, out := &in.PendingComponentTransactions, &out.PendingComponentTransactions
+		*out = make([]ComponentTransaction, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodRestarts != nil {
+		in, out := &in.PodRestarts, &out.PodRestarts
+		*out = make([]PodRestart, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreflightChecks != nil {
+		in, out := &in.PreflightChecks, &out.PreflightChecks
+		*out = make([]PreflightCheckResult, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HypervisorStatus.
@@ -361,13 +598,62 @@ func (in NamespacedClusterFileMap) DeepCopy() NamespacedClusterFileMap {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementWebhook) DeepCopyInto(out *PlacementWebhook) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementWebhook.
+func (in *PlacementWebhook) DeepCopy() *PlacementWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodRestart) DeepCopyInto(out *PodRestart) {
+	*out = *in
+	if in.LastRestartTime != nil {
+		in, out := &in.LastRestartTime, &out.LastRestartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodRestart.
+func (in *PodRestart) DeepCopy() *PodRestart {
+	if in == nil {
+		return nil
+	}
+	out := new(PodRestart)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreflightCheckResult) DeepCopyInto(out *PreflightCheckResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreflightCheckResult.
+func (in *PreflightCheckResult) DeepCopy() *PreflightCheckResult {
+	if in == nil {
+		return nil
+	}
+	out := new(PreflightCheckResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteHypervisorCRIEndpoint) DeepCopyInto(out *RemoteHypervisorCRIEndpoint) {
 	*out = *in
 	if in.ClientCertificate != nil {
 		in, out := &in.ClientCertificate, &out.ClientCertificate
 		*out = new(commonv1alpha1.Certificate)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -380,3 +666,18 @@ func (in *RemoteHypervisorCRIEndpoint) DeepCopy() *RemoteHypervisorCRIEndpoint {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHHypervisorCRIEndpoint) DeepCopyInto(out *SSHHypervisorCRIEndpoint) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHHypervisorCRIEndpoint.
+func (in *SSHHypervisorCRIEndpoint) DeepCopy() *SSHHypervisorCRIEndpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHHypervisorCRIEndpoint)
+	in.DeepCopyInto(out)
+	return out
+}
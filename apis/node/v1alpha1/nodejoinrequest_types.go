@@ -27,6 +27,17 @@ const (
 	Issued commonv1alpha1.ConditionType = "Issued"
 )
 
+// NodeJoinRequestStatusSchemaVersion is the schema version of
+// NodeJoinRequestStatus produced by this version of `oneinfra`. Node
+// agents compare it against the version they were built with, and
+// refuse to consume a status payload newer than they understand,
+// instead of guessing at fields they have never heard of.
+//
+// Version 2 added Signature.
+// Version 3 added ClusterCACertificate.
+// Version 4 added CipherSuite.
+const NodeJoinRequestStatusSchemaVersion = 4
+
 // NodeJoinRequestSpec defines the desired state of NodeJoinRequest
 type NodeJoinRequestSpec struct {
 	// Base64 encoded symmetric key, used by `oneinfra` management
@@ -45,8 +56,11 @@ type NodeJoinRequestSpec struct {
 	// +optional
 	APIServerEndpoint string `json:"apiServerEndpoint,omitempty"`
 
-	// The local node container runtime endpoint.
-	// (e.g. unix:///run/containerd/containerd.sock)
+	// The local node container runtime endpoint. Any CRI v1alpha2
+	// compliant runtime is accepted, e.g. containerd
+	// (unix:///run/containerd/containerd.sock), CRI-O
+	// (unix:///var/run/crio/crio.sock) or cri-dockerd
+	// (unix:///run/cri-dockerd.sock).
 	ContainerRuntimeEndpoint string `json:"containerRuntimeEndpoint,omitempty"`
 
 	// The local node image service endpoint. It's usually the same as
@@ -59,15 +73,50 @@ type NodeJoinRequestSpec struct {
 	//
 	// +optional
 	ExtraSANs []string `json:"extraSANs,omitempty"`
+
+	// PreferredAddressFamily is the joining node's preferred address
+	// family, used to select which of the node's local addresses the
+	// kubelet advertises as its node IP on dual-stack or IPv6-only
+	// hosts. One of "IPv4" or "IPv6". If not provided, the node
+	// agent lets the kubelet pick its node IP on its own.
+	//
+	// +optional
+	PreferredAddressFamily string `json:"preferredAddressFamily,omitempty"`
 }
 
 // NodeJoinRequestStatus defines the observed state of NodeJoinRequest
 type NodeJoinRequestStatus struct {
+	// SchemaVersion is the schema version of this status payload, as
+	// understood by the `oneinfra` instance that filled it in. Node
+	// agents use it to detect a payload using fields newer than the
+	// agent knows how to decode.
+	//
+	// +optional
+	SchemaVersion int `json:"schemaVersion,omitempty"`
+
+	// SourceFingerprint is a hash of the cluster state (apiserver
+	// endpoint, VPN settings, CA bundles) this status was generated
+	// from. `oneinfra` compares it against the cluster's current state
+	// on every reconcile, and refreshes this status whenever they
+	// diverge, even if it was already issued.
+	//
+	// +optional
+	SourceFingerprint string `json:"sourceFingerprint,omitempty"`
+
 	// KubernetesVersion contains the Kubernetes version of the cluster
 	// this node is joining to. Filled by `oneinfra`, and ciphered using
 	// the provided SymmetricKey in the request spec. Base64 encoded.
 	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
 
+	// ImageRegistry, when the joined cluster overrides it, contains
+	// the registry the joining node should pull its kubelet installer
+	// image from instead of the built-in default. Filled by
+	// `oneinfra`, and ciphered using the provided SymmetricKey in the
+	// request spec. Base64 encoded.
+	//
+	// +optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
 	// VPN contains the VPN information for this node join request. Nil
 	// if VPN is disabled. Filled by `oneinfra`.
 	VPN *VPN `json:"vpn,omitempty"`
@@ -100,11 +149,40 @@ type NodeJoinRequestStatus struct {
 	// encoded.
 	KubeletClientCACertificate string `json:"kubeletClientCACertificate,omitempty"`
 
+	// ClusterCACertificate contains the contents of the cluster
+	// certificate authority, the one that signed the apiserver serving
+	// certificate embedded in KubeConfig. Exposed explicitly so
+	// node-side components can verify the apiserver against exactly
+	// this authority instead of relying on the one implicitly embedded
+	// in KubeConfig. Filled by `oneinfra`, and ciphered using the
+	// provided SymmetricKey in the request spec. Base64 encoded.
+	//
+	// +optional
+	ClusterCACertificate string `json:"clusterCACertificate,omitempty"`
+
 	// Conditions contains a list of conditions for this
 	// request. `oneinfra` will set the `Issued` condition to `True`
 	// when this request has all the information set, and available in
 	// this `Status` object.
 	Conditions commonv1alpha1.ConditionList `json:"conditions,omitempty"`
+
+	// CipherSuite records the asymmetric cipher suite this cluster's
+	// join key uses, e.g. "RSA-OAEP" or "NaCl-Box", the one the
+	// management plane used to decrypt SymmetricKey from the request
+	// spec. Filled by `oneinfra`. Not ciphered, since it carries no
+	// sensitive information.
+	//
+	// +optional
+	CipherSuite string `json:"cipherSuite,omitempty"`
+
+	// Signature contains a base64 encoded Ed25519 detached signature,
+	// computed by `oneinfra` with the cluster's signing key over the
+	// other fields of this status, once they are all set. Joining
+	// nodes that know the cluster's signing public key can verify it
+	// to detect tampering in transit or at rest.
+	//
+	// +optional
+	Signature string `json:"signature,omitempty"`
 }
 
 // VPN defines the VPN related information to a node join request.
@@ -132,6 +210,13 @@ type VPN struct {
 	// `oneinfra`, and ciphered using the provided SymmetricKey in the
 	// request spec. Base64 encoded.
 	EndpointPublicKey string `json:"endpointPublicKey,omitempty"`
+
+	// KeepaliveSeconds is the WireGuard persistent keepalive interval
+	// this node should use, in seconds. Filled by `oneinfra`. Not
+	// ciphered, since it carries no sensitive information.
+	//
+	// +optional
+	KeepaliveSeconds int `json:"keepaliveSeconds,omitempty"`
 }
 
 // +genclient
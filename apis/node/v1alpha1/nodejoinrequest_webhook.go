@@ -0,0 +1,67 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"github.com/oneinfra/oneinfra/pkg/constants"
+)
+
+// SetupWebhookWithManager registers this web hook on the given
+// manager instance
+func (nodeJoinRequest *NodeJoinRequest) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(nodeJoinRequest).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-node-oneinfra-ereslibre-es-v1alpha1-nodejoinrequest,mutating=true,failurePolicy=fail,groups=node.oneinfra.ereslibre.es,resources=nodejoinrequests,verbs=create,versions=v1alpha1,name=mnodejoinrequest.kb.io
+
+var _ webhook.Defaulter = &NodeJoinRequest{}
+
+// Default implements webhook.Defaulter so a webhook will be
+// registered for the type.
+//
+// Every field in NodeJoinRequestSpec besides metadata arrives already
+// encrypted with a symmetric key held only by the joining node (see
+// `oi join`): by the time this webhook runs, the apiserver endpoint,
+// container runtime endpoint, image service endpoint, extra SANs and
+// preferred address family are all ciphertext it cannot read, let
+// alone default. The only thing left that is safe, and useful, to
+// default here is metadata
+func (nodeJoinRequest *NodeJoinRequest) Default() {
+	klog.Info("default", "name", nodeJoinRequest.Name)
+	nodeJoinRequest.defaultRequestedAt()
+}
+
+// defaultRequestedAt stamps the time this request was first
+// admitted, so stale join requests that were never issued can later
+// be told apart from ones still in flight
+func (nodeJoinRequest *NodeJoinRequest) defaultRequestedAt() {
+	if nodeJoinRequest.Annotations == nil {
+		nodeJoinRequest.Annotations = map[string]string{}
+	}
+	if _, exists := nodeJoinRequest.Annotations[constants.OneInfraRequestedAtAnnotation]; exists {
+		return
+	}
+	nodeJoinRequest.Annotations[constants.OneInfraRequestedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,71 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+)
+
+const (
+	// Completed represents a leave request Completed condition
+	Completed commonv1alpha1.ConditionType = "Completed"
+)
+
+// NodeLeaveRequestSpec defines the desired state of NodeLeaveRequest.
+// The name of the NodeLeaveRequest itself is the name of the Node to
+// decommission, the same convention NodeJoinRequest uses for the
+// node being joined
+type NodeLeaveRequestSpec struct {
+}
+
+// NodeLeaveRequestStatus defines the observed state of NodeLeaveRequest
+type NodeLeaveRequestStatus struct {
+	// Conditions contains a list of conditions for this
+	// request. `oneinfra` will set the `Completed` condition to
+	// `True` once the node has been drained, its Node object
+	// deleted, and its VPN peer released.
+	Conditions commonv1alpha1.ConditionList `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+// +genclient:nonNamespaced
+// +kubebuilder:object:root=true
+
+// NodeLeaveRequest is the Schema for the nodeleaverequests API
+type NodeLeaveRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeLeaveRequestSpec   `json:"spec,omitempty"`
+	Status NodeLeaveRequestStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NodeLeaveRequestList contains a list of NodeLeaveRequest
+type NodeLeaveRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeLeaveRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NodeLeaveRequest{}, &NodeLeaveRequestList{})
+}
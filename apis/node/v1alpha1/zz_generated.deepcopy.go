@@ -131,6 +131,102 @@ func (in *NodeJoinRequestStatus) DeepCopy() *NodeJoinRequestStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLeaveRequest) DeepCopyInto(out *NodeLeaveRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLeaveRequest.
+func (in *NodeLeaveRequest) DeepCopy() *NodeLeaveRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLeaveRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeLeaveRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLeaveRequestList) DeepCopyInto(out *NodeLeaveRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NodeLeaveRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLeaveRequestList.
+func (in *NodeLeaveRequestList) DeepCopy() *NodeLeaveRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLeaveRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeLeaveRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLeaveRequestSpec) DeepCopyInto(out *NodeLeaveRequestSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLeaveRequestSpec.
+func (in *NodeLeaveRequestSpec) DeepCopy() *NodeLeaveRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLeaveRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLeaveRequestStatus) DeepCopyInto(out *NodeLeaveRequestStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(commonv1alpha1.ConditionList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLeaveRequestStatus.
+func (in *NodeLeaveRequestStatus) DeepCopy() *NodeLeaveRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLeaveRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VPN) DeepCopyInto(out *VPN) {
 	*out = *in
@@ -20,6 +20,57 @@ package v1alpha1
 
 const (
 
+	// NodeLeaveRequestOpenAPISchema represents the OpenAPI schema for kind NodeLeaveRequest
+	NodeLeaveRequestOpenAPISchema = `description: NodeLeaveRequest is the Schema for the nodeleaverequests API
+properties:
+  apiVersion:
+    description: 'APIVersion defines the versioned schema of this representation of
+      an object. Servers should convert recognized schemas to the latest internal
+      value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources'
+    type: string
+  kind:
+    description: 'Kind is a string value representing the REST resource this object
+      represents. Servers may infer this from the endpoint the client submits requests
+      to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds'
+    type: string
+  metadata:
+    type: object
+  spec:
+    description: NodeLeaveRequestSpec defines the desired state of NodeLeaveRequest.
+      The name of the NodeLeaveRequest itself is the name of the Node to decommission,
+      the same convention NodeJoinRequest uses for the node being joined
+    type: object
+  status:
+    description: NodeLeaveRequestStatus defines the observed state of NodeLeaveRequest
+    properties:
+      conditions:
+        description: Conditions contains a list of conditions for this request. "oneinfra"
+          will set the "Completed" condition to "True" once the node has been drained,
+          its Node object deleted, and its VPN peer released.
+        items:
+          description: Condition represents a condition
+          properties:
+            lastSetTime:
+              format: date-time
+              type: string
+            lastTransitionTime:
+              format: date-time
+              type: string
+            message:
+              type: string
+            reason:
+              type: string
+            status:
+              description: ConditionStatus represents a condition status
+              type: string
+            type:
+              description: ConditionType represents a condition type
+              type: string
+          type: object
+        type: array
+    type: object
+type: object`
+
 	// NodeJoinRequestOpenAPISchema represents the OpenAPI schema for kind NodeJoinRequest
 	NodeJoinRequestOpenAPISchema = `description: NodeJoinRequest is the Schema for the nodejoinrequests API
 properties:
@@ -44,7 +95,9 @@ properties:
           not provided, the default cluster API endpoint will be used.
         type: string
       containerRuntimeEndpoint:
-        description: The local node container runtime endpoint. (e.g. unix:///run/containerd/containerd.sock)
+        description: The local node container runtime endpoint. Any CRI v1alpha2
+          compliant runtime is accepted, e.g. containerd (unix:///run/containerd/containerd.sock),
+          CRI-O (unix:///var/run/crio/crio.sock) or cri-dockerd (unix:///run/cri-dockerd.sock).
         type: string
       extraSANs:
         description: A list of extra Subject Alternative Names (SAN's) that will be
@@ -56,6 +109,13 @@ properties:
         description: The local node image service endpoint. It's usually the same
           as the container runtime endpoint. (e.g. unix:///run/containerd/containerd.sock)
         type: string
+      preferredAddressFamily:
+        description: PreferredAddressFamily is the joining node's preferred address
+          family, used to select which of the node's local addresses the kubelet
+          advertises as its node IP on dual-stack or IPv6-only hosts. One of "IPv4"
+          or "IPv6". If not provided, the node agent lets the kubelet pick its node
+          IP on its own.
+        type: string
       symmetricKey:
         description: Base64 encoded symmetric key, used by "oneinfra" management cluster
           to cipher joining information. This key must be ciphered with the join public
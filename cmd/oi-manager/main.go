@@ -19,9 +19,12 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
@@ -33,11 +36,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	clusterv1beta1 "github.com/oneinfra/oneinfra/apis/cluster/v1beta1"
 	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
 	nodev1alpha1 "github.com/oneinfra/oneinfra/apis/node/v1alpha1"
 	"github.com/oneinfra/oneinfra/controllers"
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
 	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+	"github.com/oneinfra/oneinfra/internal/pkg/crypto"
+	"github.com/oneinfra/oneinfra/internal/pkg/dashboard"
 	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+	constantsapi "github.com/oneinfra/oneinfra/pkg/constants"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -51,6 +60,7 @@ func init() {
 
 	_ = infrav1alpha1.AddToScheme(scheme)
 	_ = clusterv1alpha1.AddToScheme(scheme)
+	_ = clusterv1beta1.AddToScheme(scheme)
 	_ = nodev1alpha1.AddToScheme(scheme)
 	// +kubebuilder:scaffold:scheme
 }
@@ -59,14 +69,76 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var verbosityLevel int
+	var printRBAC bool
+	var keyInCRDSecretBackend bool
+	var keyPoolSize int
+	var watchNamespaces string
+	var clusterSelector string
+	var secretsEncryptionKeyPairPath string
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.IntVar(&verbosityLevel, "verbosity", 1, "The verbosity level for the controller manager.")
+	flag.BoolVar(&printRBAC, "print-rbac", false,
+		"Print the minimal ClusterRole required by the manager for the enabled feature set and exit.")
+	flag.BoolVar(&keyInCRDSecretBackend, "key-in-crd-secret-backend", false,
+		"Whether private keys are stored in the custom resources instead of in Kubernetes Secrets. "+
+			"Affects the RBAC permissions printed by --print-rbac.")
+	flag.IntVar(&keyPoolSize, "key-pool-size", 0,
+		"The number of CA and leaf RSA keys to pre-generate in the background and keep ready for "+
+			"certificate generation. 0 disables the key pool, generating keys inline instead.")
+	flag.StringVar(&watchNamespaces, "watch-namespaces", "",
+		"Comma separated list of namespaces this manager instance will reconcile. Empty watches all namespaces. "+
+			"Allows sharding managers across namespaces against a shared CRD schema.")
+	flag.StringVar(&clusterSelector, "cluster-selector", "",
+		"Label selector restricting which clusters (and their components and node join requests) this "+
+			"manager instance will reconcile. Empty reconciles all of them.")
+	flag.StringVar(&secretsEncryptionKeyPairPath, "secrets-encryption-key-pair", "",
+		"Path to an RSA key pair used to envelope-encrypt private keys and key pairs before they are "+
+			"stored in cluster specs and statuses. Empty stores them in plain PEM.")
 	flag.Set("alsologtostderr", "true")
 	flag.Parse()
 
+	if keyPoolSize > 0 {
+		crypto.EnableKeyPool(constantsapi.DefaultCAKeyBitSize, keyPoolSize)
+		crypto.EnableKeyPool(constantsapi.DefaultKeyBitSize, keyPoolSize)
+	}
+
+	if secretsEncryptionKeyPairPath != "" {
+		secretsEncryptionKeyPair, err := crypto.NewKeyPairFromFile(secretsEncryptionKeyPairPath)
+		if err != nil {
+			klog.Errorf("could not load --secrets-encryption-key-pair %q: %v", secretsEncryptionKeyPairPath, err)
+			os.Exit(1)
+		}
+		cluster.SetSecretsEncryptionProvider(secretsEncryptionKeyPair)
+	}
+
+	scope := controllers.Scope{}
+	if watchNamespaces != "" {
+		scope.Namespaces = strings.Split(watchNamespaces, ",")
+	}
+	if clusterSelector != "" {
+		selector, err := labels.Parse(clusterSelector)
+		if err != nil {
+			klog.Errorf("could not parse --cluster-selector %q: %v", clusterSelector, err)
+			os.Exit(1)
+		}
+		scope.LabelSelector = selector
+	}
+
+	if printRBAC {
+		clusterRoleYAML, err := manifests.GenerateManagerClusterRoleYAML(manifests.ManagerFeatures{
+			KeyInCRDSecretBackend: keyInCRDSecretBackend,
+		})
+		if err != nil {
+			klog.Errorf("could not generate manager RBAC manifest: %v", err)
+			os.Exit(1)
+		}
+		fmt.Print(clusterRoleYAML)
+		return
+	}
+
 	ctrl.SetLogger(zap.New(func(o *zap.Options) {
 		o.Development = true
 	}))
@@ -75,14 +147,23 @@ func main() {
 	klog.InitFlags(klogFlags)
 	klogFlags.Set("v", strconv.Itoa(verbosityLevel))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	managerOptions := ctrl.Options{
 		Scheme:             scheme,
 		MetricsBindAddress: metricsAddr,
 		LeaderElection:     enableLeaderElection,
 		LeaderElectionID:   "oneinfra-manager-leader-election",
 		Port:               9443,
 		NewClient:          rawClient,
-	})
+	}
+	// A single watched namespace can be pushed down to the cache's
+	// ListWatch. With several namespaces the cache still watches
+	// cluster-wide, and scope.Predicate (applied per controller below)
+	// filters out objects outside the watched set.
+	if len(scope.Namespaces) == 1 {
+		managerOptions.Namespace = scope.Namespaces[0]
+	}
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), managerOptions)
 	if err != nil {
 		klog.Errorf("could not set up controller manager: %v", err)
 		os.Exit(1)
@@ -92,9 +173,16 @@ func main() {
 		klog.Warning("could not update oneinfra versions ConfigMap")
 	}
 
+	dashboardAggregator := dashboard.NewAggregator()
+	if err := mgr.AddMetricsExtraHandler("/dashboard", dashboardAggregator); err != nil {
+		klog.Errorf("could not register dashboard aggregation endpoint: %v", err)
+		os.Exit(1)
+	}
+
 	if err = (&controllers.ComponentScheduler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Scope:  scope,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error("could not set component scheduler controller")
 		os.Exit(1)
@@ -102,6 +190,7 @@ func main() {
 	if err = (&controllers.ComponentReconciler{
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
+		Scope:          scope,
 		ConnectionPool: infra.HypervisorConnectionPool{},
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error("could not set component reconciler controller")
@@ -110,20 +199,32 @@ func main() {
 	if err = (&controllers.ClusterInitializer{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Scope:  scope,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error("could not set cluster initializer controller")
 		os.Exit(1)
 	}
+	if err = (&controllers.HypervisorInitializer{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Scope:  scope,
+	}).SetupWithManager(mgr); err != nil {
+		klog.Error("could not set hypervisor initializer controller")
+		os.Exit(1)
+	}
 	if err = (&controllers.ClusterController{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Scope:  scope,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error("could not set cluster controller controller")
 		os.Exit(1)
 	}
 	if err = (&controllers.ClusterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		Scope:     scope,
+		Dashboard: dashboardAggregator,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error("could not set cluster reconciler controller")
 		os.Exit(1)
@@ -131,10 +232,35 @@ func main() {
 	if err = (&controllers.NodeJoinRequestReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
+		Scope:  scope,
 	}).SetupWithManager(mgr); err != nil {
 		klog.Error("could not set node join request reconciler controller")
 		os.Exit(1)
 	}
+	if err = (&controllers.NodeLeaveRequestReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Scope:  scope,
+	}).SetupWithManager(mgr); err != nil {
+		klog.Error("could not set node leave request reconciler controller")
+		os.Exit(1)
+	}
+	if err = (&controllers.ComplianceScanner{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Scope:  scope,
+	}).SetupWithManager(mgr); err != nil {
+		klog.Error("could not set compliance scanner controller")
+		os.Exit(1)
+	}
+	if err = (&controllers.IngressStatsScanner{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Scope:  scope,
+	}).SetupWithManager(mgr); err != nil {
+		klog.Error("could not set ingress stats scanner controller")
+		os.Exit(1)
+	}
 	if err = (&clusterv1alpha1.Cluster{}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Error("could not set up cluster webhook")
 		os.Exit(1)
@@ -143,6 +269,14 @@ func main() {
 		klog.Error("could not set up component webhook")
 		os.Exit(1)
 	}
+	if err = (&nodev1alpha1.NodeJoinRequest{}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Error("could not set up node join request webhook")
+		os.Exit(1)
+	}
+	if err = (&infrav1alpha1.Hypervisor{}).SetupWebhookWithManager(mgr); err != nil {
+		klog.Error("could not set up hypervisor webhook")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -21,15 +21,22 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/urfave/cli/v2"
 	"k8s.io/klog/v2"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/app/oi/apply"
+	"github.com/oneinfra/oneinfra/internal/app/oi/bundle"
 	"github.com/oneinfra/oneinfra/internal/app/oi/cluster"
+	"github.com/oneinfra/oneinfra/internal/app/oi/hypervisor"
 	jointoken "github.com/oneinfra/oneinfra/internal/app/oi/join-token"
 	"github.com/oneinfra/oneinfra/internal/app/oi/node"
+	"github.com/oneinfra/oneinfra/internal/app/oi/placement"
+	"github.com/oneinfra/oneinfra/internal/app/oi/query"
 	"github.com/oneinfra/oneinfra/internal/pkg/constants"
 	releasecomponents "github.com/oneinfra/oneinfra/internal/pkg/release-components"
 	constantsapi "github.com/oneinfra/oneinfra/pkg/constants"
@@ -85,6 +92,21 @@ func main() {
 							return cluster.Inject(c.String("name"), kubernetesVersion, c.Int("control-plane-replicas"), c.Bool("vpn-enabled"), c.String("vpn-cidr"), c.StringSlice("apiserver-extra-sans"))
 						},
 					},
+					{
+						Name:  "apply",
+						Usage: "apply a cluster class document, decomposing it into the underlying cluster and component resources",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "filename",
+								Aliases:  []string{"f"},
+								Required: true,
+								Usage:    "path to the cluster class document to apply",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return cluster.Apply(c.String("filename"))
+						},
+					},
 					{
 						Name:  "admin-kubeconfig",
 						Usage: "generate an admin kubeconfig file for the cluster",
@@ -93,9 +115,29 @@ func main() {
 								Name:  "cluster",
 								Usage: "cluster name (can be omitted if stdin has only one cluster resource)",
 							},
+							&cli.BoolFlag{
+								Name:  "merge",
+								Usage: "merge the generated kubeconfig into --kubeconfig instead of printing it to stdout",
+							},
+							&cli.StringFlag{
+								Name:  "kubeconfig",
+								Usage: "kubeconfig file to merge into when --merge is set",
+								Value: defaultKubeConfigPath(),
+							},
+							&cli.StringFlag{
+								Name:  "context-name",
+								Usage: "name for the merged cluster, user and context (defaults to the cluster name)",
+							},
+							&cli.BoolFlag{
+								Name:  "set-current",
+								Usage: "set the merged context as the current context (only with --merge)",
+							},
 						},
 						Action: func(c *cli.Context) error {
-							return cluster.AdminKubeConfig(c.String("cluster"))
+							if !c.Bool("merge") {
+								return cluster.AdminKubeConfig(c.String("cluster"))
+							}
+							return cluster.MergeKubeConfig(c.String("cluster"), c.String("kubeconfig"), c.String("context-name"), c.Bool("set-current"))
 						},
 					},
 					{
@@ -111,6 +153,140 @@ func main() {
 							return cluster.APIServerCA(c.String("cluster"))
 						},
 					},
+					{
+						Name:  "exec-credential",
+						Usage: "mints a fresh client certificate and prints it as a client.authentication.k8s.io ExecCredential object, for use from a kubeconfig's exec block",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "cluster",
+								Usage: "cluster name",
+							},
+							&cli.StringFlag{
+								Name:  "user",
+								Usage: "common name for the minted client certificate",
+								Value: "kubernetes-admin",
+							},
+							&cli.StringSliceFlag{
+								Name:  "group",
+								Usage: "organization (group) for the minted client certificate",
+								Value: cli.NewStringSlice("system:masters"),
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return cluster.ExecCredential(c.String("cluster"), c.String("user"), c.StringSlice("group"))
+						},
+					},
+					{
+						Name:  "connection-info",
+						Usage: "prints a Secret with this cluster's connection details, ready to be consumed by common CD tools",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "cluster",
+								Usage: "cluster name",
+							},
+							&cli.StringFlag{
+								Name:  "format",
+								Usage: "connection info format (argocd, flux)",
+								Value: "argocd",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return cluster.ConnectionInfo(c.String("cluster"), c.String("format"))
+						},
+					},
+					{
+						Name:  "history",
+						Usage: "prints the lifecycle history recorded for a cluster, querying the management API directly",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "cluster",
+								Usage: "cluster name",
+							},
+							&cli.StringFlag{
+								Name:  "namespace",
+								Usage: "cluster namespace",
+								Value: "default",
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output format (table, json)",
+								Value:   "table",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return cluster.History(c.String("namespace"), c.String("cluster"), c.String("output"))
+						},
+					},
+					{
+						Name:  "status",
+						Usage: "prints the tracking identifier and provisioning progress recorded for a cluster, querying the management API directly",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "cluster",
+								Usage: "cluster name",
+							},
+							&cli.StringFlag{
+								Name:  "namespace",
+								Usage: "cluster namespace",
+								Value: "default",
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output format (table, json)",
+								Value:   "table",
+							},
+							&cli.BoolFlag{
+								Name:  "wait",
+								Usage: "poll until the cluster has fully provisioned instead of reporting once",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return cluster.Status(c.String("namespace"), c.String("cluster"), c.String("output"), c.Bool("wait"))
+						},
+					},
+					{
+						Name:  "rotate-ca",
+						Usage: "immediately rotates all certificate authorities for a cluster, bypassing the automatic expiry-driven rotation schedule; talks to the management API directly",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "cluster",
+								Usage: "cluster name",
+							},
+							&cli.StringFlag{
+								Name:  "namespace",
+								Usage: "cluster namespace",
+								Value: "default",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return cluster.RotateCA(c.String("namespace"), c.String("cluster"))
+						},
+					},
+					{
+						Name:  "expand-vpn-cidr",
+						Usage: "grows a cluster's VPN CIDR to a new range that fully contains the existing one; talks to the management API directly",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "cluster",
+								Usage: "cluster name",
+							},
+							&cli.StringFlag{
+								Name:  "namespace",
+								Usage: "cluster namespace",
+								Value: "default",
+							},
+							&cli.StringFlag{
+								Name:     "cidr",
+								Required: true,
+								Usage:    "new VPN CIDR, must fully contain the existing one",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return cluster.ExpandVPNCIDR(c.String("namespace"), c.String("cluster"), c.String("cidr"))
+						},
+					},
 					{
 						Name:  "version",
 						Usage: "prints versioning information for the given cluster",
@@ -135,6 +311,269 @@ func main() {
 							},
 						},
 					},
+					{
+						Name:  "vpn",
+						Usage: "cluster VPN operations",
+						Subcommands: []*cli.Command{
+							{
+								Name:  "add-peer",
+								Usage: "mints a new on-demand VPN peer; prints resulting manifests in stdout, and the generated peer's private key in stderr",
+								Flags: []cli.Flag{
+									&cli.StringFlag{
+										Name:  "cluster",
+										Usage: "cluster name",
+									},
+									&cli.StringFlag{
+										Name:     "name",
+										Required: true,
+										Usage:    "name for the new VPN peer",
+									},
+									&cli.StringFlag{
+										Name:  "purpose",
+										Usage: "VPN peer purpose (worker, ingress, admin-access)",
+										Value: string(clusterv1alpha1.VPNPeerPurposeAdminAccess),
+									},
+									&cli.StringFlag{
+										Name:  "ttl",
+										Usage: "release this peer automatically after the given duration (e.g. 8h), instead of leaving it as standing access",
+									},
+								},
+								Action: func(c *cli.Context) error {
+									return cluster.AddVPNPeer(c.String("cluster"), c.String("name"), c.String("purpose"), c.String("ttl"))
+								},
+							},
+							{
+								Name:  "client-config",
+								Usage: "renders a ready to use wg-quick configuration for a VPN peer",
+								Flags: []cli.Flag{
+									&cli.StringFlag{
+										Name:  "cluster",
+										Usage: "cluster name",
+									},
+									&cli.StringFlag{
+										Name:     "peer",
+										Required: true,
+										Usage:    "name of the VPN peer to render the configuration for",
+									},
+								},
+								Action: func(c *cli.Context) error {
+									return cluster.VPNPeerClientConfig(c.String("cluster"), c.String("peer"))
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "hypervisor",
+				Usage: "hypervisor operations",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "list registered hypervisors, their capacity and current allocations",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output format (table, json)",
+								Value:   "table",
+							},
+							&cli.BoolFlag{
+								Name:  "live",
+								Usage: "query each hypervisor's CRI endpoint for its current health",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return hypervisor.List(c.String("output"), c.Bool("live"))
+						},
+					},
+					{
+						Name:  "describe",
+						Usage: "describe a single hypervisor's capacity and current allocations",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "hypervisor",
+								Required: true,
+								Usage:    "hypervisor name",
+							},
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output format (table, json)",
+								Value:   "table",
+							},
+							&cli.BoolFlag{
+								Name:  "live",
+								Usage: "query the hypervisor's CRI endpoint for its current health",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return hypervisor.Describe(c.String("hypervisor"), c.String("output"), c.Bool("live"))
+						},
+					},
+					{
+						Name:  "audit",
+						Usage: "connect to every registered hypervisor, report orphan and missing pods",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output format (table, json)",
+								Value:   "table",
+							},
+							&cli.BoolFlag{
+								Name:  "prune",
+								Usage: "delete orphan pods found on the hypervisors",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return hypervisor.Audit(c.String("output"), c.Bool("prune"))
+						},
+					},
+					{
+						Name:  "cordon",
+						Usage: "mark a hypervisor as unschedulable, so the placement scheduler stops placing new components on it; talks to the management API directly",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "hypervisor",
+								Required: true,
+								Usage:    "hypervisor name",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return hypervisor.Cordon(c.String("hypervisor"))
+						},
+					},
+					{
+						Name:  "uncordon",
+						Usage: "clear a hypervisor's unschedulable mark; talks to the management API directly",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "hypervisor",
+								Required: true,
+								Usage:    "hypervisor name",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return hypervisor.Uncordon(c.String("hypervisor"))
+						},
+					},
+					{
+						Name:  "drain",
+						Usage: "cordon a hypervisor and unschedule its current components, so they are placed on another hypervisor; talks to the management API directly",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "hypervisor",
+								Required: true,
+								Usage:    "hypervisor name",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return hypervisor.Drain(c.String("hypervisor"))
+						},
+					},
+				},
+			},
+			{
+				Name:  "placement",
+				Usage: "placement scheduler operations",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "simulate",
+						Usage: "simulate the placement scheduler against hypervisors and clusters read from stdin, without creating or updating anything",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "output",
+								Aliases: []string{"o"},
+								Usage:   "output format (table, json)",
+								Value:   "table",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return placement.Simulate(c.String("output"))
+						},
+					},
+				},
+			},
+			{
+				Name:  "query",
+				Usage: "query clusters across the fleet by Kubernetes version and hypervisor",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "kubernetes-version",
+						Usage: "only list clusters at this Kubernetes version",
+					},
+					&cli.StringFlag{
+						Name:  "hypervisor",
+						Usage: "only list clusters with a component scheduled on this hypervisor",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "output format (table, json)",
+						Value:   "table",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return query.Query(c.String("kubernetes-version"), c.String("hypervisor"), c.String("output"))
+				},
+			},
+			{
+				Name:  "bundle",
+				Usage: "build and push offline bundles for air-gapped installs",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "build",
+						Usage: "builds an offline bundle with all images required for a given Kubernetes version",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "kubernetes-version",
+								Required: true,
+								Usage:    "Kubernetes version to build the offline bundle for",
+							},
+							&cli.StringFlag{
+								Name:     "output",
+								Aliases:  []string{"o"},
+								Required: true,
+								Usage:    "path to write the offline bundle archive to",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return bundle.Build(c.String("kubernetes-version"), c.String("output"))
+						},
+					},
+					{
+						Name:  "push",
+						Usage: "pushes an offline bundle onto a hypervisor's CRI image store",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "bundle",
+								Required: true,
+								Usage:    "path to the offline bundle archive to push",
+							},
+							&cli.StringFlag{
+								Name:  "containerd-address",
+								Usage: "address of the containerd instance backing the hypervisor's CRI image store",
+								Value: "/run/containerd/containerd.sock",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return bundle.Push(c.String("bundle"), c.String("containerd-address"))
+						},
+					},
+				},
+			},
+			{
+				Name:  "apply",
+				Usage: "submit exported cluster and hypervisor manifests to the management API",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "server-dry-run",
+						Usage: "submit with a server-side dry run, so admission webhooks and validation run without persisting anything",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return apply.Apply(c.Bool("server-dry-run"))
 				},
 			},
 			{
@@ -157,12 +596,16 @@ func main() {
 						Usage: "time to wait between retries",
 						Value: 5 * time.Second,
 					},
+					&cli.StringFlag{
+						Name:  "lock-file",
+						Usage: "path to a lock file serializing concurrent \"oi reconcile\" invocations; disabled if not provided",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					flagSet := flag.FlagSet{}
 					klog.InitFlags(&flagSet)
 					flagSet.Set("v", strconv.Itoa(c.Int("verbosity")))
-					return cluster.Reconcile(c.Int("max-retries"), c.Duration("retry-wait-time"))
+					return cluster.Reconcile(c.Int("max-retries"), c.Duration("retry-wait-time"), c.String("lock-file"))
 				},
 			},
 			{
@@ -177,9 +620,17 @@ func main() {
 								Name:  "cluster",
 								Usage: "cluster name",
 							},
+							&cli.StringFlag{
+								Name:  "ttl",
+								Usage: "delete this token automatically after the given duration (e.g. 24h), instead of leaving it valid indefinitely",
+							},
+							&cli.IntFlag{
+								Name:  "usage-limit",
+								Usage: "delete this token automatically once it has bootstrapped this many nodes",
+							},
 						},
 						Action: func(c *cli.Context) error {
-							return jointoken.Inject(c.String("cluster"))
+							return jointoken.Inject(c.String("cluster"), c.String("ttl"), c.Int("usage-limit"))
 						},
 					},
 					{
@@ -239,6 +690,15 @@ func main() {
 								Name:  "extra-san",
 								Usage: "extra Subject Alternative Names (SAN's) for the Kubelet server certificate. You can provide this argument many times.",
 							},
+							&cli.StringFlag{
+								Name:  "preferred-address-family",
+								Usage: "preferred address family for the kubelet node IP, one of \"IPv4\" or \"IPv6\"; left to the kubelet to decide if not provided",
+							},
+							&cli.DurationFlag{
+								Name:  "max-clock-skew",
+								Usage: "maximum tolerated clock difference between this node and the management plane before refusing to join",
+								Value: constantsapi.DefaultMaxJoinClockSkew,
+							},
 						},
 						Action: func(c *cli.Context) error {
 							flagSet := flag.FlagSet{}
@@ -252,6 +712,57 @@ func main() {
 								c.String("container-runtime-endpoint"),
 								c.String("image-service-endpoint"),
 								c.StringSlice("extra-san"),
+								c.String("preferred-address-family"),
+								c.Duration("max-clock-skew"),
+							)
+						},
+					},
+					{
+						Name:  "leave",
+						Usage: "decommissions a node from an existing cluster",
+						Flags: []cli.Flag{
+							&cli.IntFlag{
+								Name:    "verbosity",
+								Aliases: []string{"v"},
+								Usage:   "logging verbosity",
+								Value:   1,
+							},
+							&cli.StringFlag{
+								Name:     "nodename",
+								Required: true,
+								Usage:    "node name of this node when leaving",
+							},
+							&cli.StringFlag{
+								Name:     "apiserver-endpoint",
+								Required: true,
+								Usage:    "endpoint of the apiserver to leave from",
+							},
+							&cli.StringFlag{
+								Name:     "apiserver-ca-cert-file",
+								Required: true,
+								Usage:    "apiserver CA certificate to check the apiserver identity",
+							},
+							&cli.StringFlag{
+								Name:     "leave-token",
+								Required: true,
+								Usage:    "token to use for leaving",
+							},
+							&cli.DurationFlag{
+								Name:  "timeout",
+								Usage: "maximum time to wait for the leave request to complete",
+								Value: 5 * time.Minute,
+							},
+						},
+						Action: func(c *cli.Context) error {
+							flagSet := flag.FlagSet{}
+							klog.InitFlags(&flagSet)
+							flagSet.Set("v", strconv.Itoa(c.Int("verbosity")))
+							return node.Leave(
+								c.String("nodename"),
+								c.String("apiserver-endpoint"),
+								c.String("apiserver-ca-cert-file"),
+								c.String("leave-token"),
+								c.Duration("timeout"),
 							)
 						},
 					},
@@ -327,3 +838,13 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// defaultKubeConfigPath returns $HOME/.kube/config, mirroring
+// kubectl's default kubeconfig location
+func defaultKubeConfigPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".kube", "config")
+}
@@ -22,21 +22,29 @@ import (
 	"time"
 
 	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	clusterapi "github.com/oneinfra/oneinfra/internal/pkg/cluster"
 	"github.com/oneinfra/oneinfra/internal/pkg/component"
 	componentapi "github.com/oneinfra/oneinfra/internal/pkg/component"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// ClusterController manages Component resources from Cluster resources
+// ClusterController manages Component resources from Cluster
+// resources, as well as the Service and EndpointSlice that expose
+// each cluster's apiserver endpoint inside the management cluster
 type ClusterController struct {
 	client.Client
 	Scheme *runtime.Scheme
+	Scope  Scope
 }
 
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=discovery.k8s.io,resources=endpointslices,verbs=get;list;watch;create;update;patch;delete
+
 // Reconcile reconciles the Component resources that belong to a
 // Cluster resource
 func (r *ClusterController) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -61,7 +69,12 @@ func (r *ClusterController) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	// Reconcile cluster deletion
 
 	if cluster.DeletionTimestamp != nil {
-		return r.reconcileDeletion(ctx, req, currentClusterComponents)
+		return r.reconcileDeletion(ctx, req, cluster, currentClusterComponents)
+	}
+
+	if err := r.reconcileAPIServerEndpoint(ctx, cluster); err != nil {
+		klog.Errorf("could not reconcile apiserver endpoint for cluster %q: %v", req, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 	}
 
 	// Reconcile control plane components
@@ -155,12 +168,16 @@ func (r *ClusterController) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	return ctrl.Result{}, nil
 }
 
-func (r *ClusterController) reconcileDeletion(ctx context.Context, req ctrl.Request, components component.List) (ctrl.Result, error) {
+func (r *ClusterController) reconcileDeletion(ctx context.Context, req ctrl.Request, cluster *clusterapi.Cluster, components component.List) (ctrl.Result, error) {
 	for _, component := range components {
 		if err := r.Delete(ctx, component.Export()); err != nil {
 			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
 		}
 	}
+	if err := r.reconcileAPIServerEndpointDeletion(ctx, cluster); err != nil {
+		klog.Errorf("could not delete apiserver endpoint for cluster %q: %v", req, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -168,6 +185,6 @@ func (r *ClusterController) reconcileDeletion(ctx context.Context, req ctrl.Requ
 func (r *ClusterController) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("cluster-controller").
-		For(&clusterv1alpha1.Cluster{}).
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(r.Scope.Predicate())).
 		Complete(r)
 }
@@ -0,0 +1,180 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	clientapi "sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterapi "github.com/oneinfra/oneinfra/internal/pkg/cluster"
+)
+
+// endpointSliceServiceNameLabel is the well-known label an
+// EndpointSlice must carry to be associated with a Service; it is not
+// exported by the vendored discovery/v1beta1 package, which only
+// gained the constant in v1
+const endpointSliceServiceNameLabel = "kubernetes.io/service-name"
+
+// apiServerServiceName returns the name of the Service and
+// EndpointSlice that make a cluster's apiserver endpoint reachable
+// from workloads running in the management cluster
+func apiServerServiceName(clusterName string) string {
+	return fmt.Sprintf("%s-apiserver", clusterName)
+}
+
+// reconcileAPIServerEndpoint creates or updates a headless Service and
+// a matching EndpointSlice pointing at cluster's apiserver ingress, so
+// management cluster workloads (CI, controllers) can reach tenant
+// clusters through a stable in-cluster DNS name instead of having to
+// track placement changes themselves. It is a no-op until the cluster
+// reconciler has computed an APIServerEndpoint
+func (r *ClusterController) reconcileAPIServerEndpoint(ctx context.Context, cluster *clusterapi.Cluster) error {
+	if cluster.APIServerEndpoint == "" {
+		return nil
+	}
+	endpoint, err := url.Parse(cluster.APIServerEndpoint)
+	if err != nil {
+		return err
+	}
+	host, portString, err := net.SplitHostPort(endpoint.Host)
+	if err != nil {
+		return err
+	}
+	port, err := net.LookupPort("tcp", portString)
+	if err != nil {
+		return err
+	}
+	serviceName := apiServerServiceName(cluster.Name)
+	if err := r.reconcileAPIServerService(ctx, cluster, serviceName, port); err != nil {
+		return err
+	}
+	return r.reconcileAPIServerEndpointSlice(ctx, cluster, serviceName, host, port)
+}
+
+func (r *ClusterController) reconcileAPIServerService(ctx context.Context, cluster *clusterapi.Cluster, serviceName string, port int) error {
+	desiredService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: cluster.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{
+					Name:     "https",
+					Protocol: corev1.ProtocolTCP,
+					Port:     int32(port),
+				},
+			},
+		},
+	}
+	var currentService corev1.Service
+	err := r.Get(ctx, clientapi.ObjectKey{Namespace: cluster.Namespace, Name: serviceName}, &currentService)
+	if apierrors.IsNotFound(err) {
+		if err := r.Create(ctx, desiredService); err != nil {
+			klog.Errorf("could not create apiserver service for cluster %q: %v", cluster.Name, err)
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	currentService.Spec.Ports = desiredService.Spec.Ports
+	if err := r.Update(ctx, &currentService); err != nil {
+		klog.Errorf("could not update apiserver service for cluster %q: %v", cluster.Name, err)
+		return err
+	}
+	return nil
+}
+
+func (r *ClusterController) reconcileAPIServerEndpointSlice(ctx context.Context, cluster *clusterapi.Cluster, serviceName, host string, port int) error {
+	port32 := int32(port)
+	desiredEndpointSlice := &discoveryv1beta1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      serviceName,
+			Namespace: cluster.Namespace,
+			Labels: map[string]string{
+				endpointSliceServiceNameLabel: serviceName,
+			},
+		},
+		AddressType: discoveryv1beta1.AddressTypeIPv4,
+		Endpoints: []discoveryv1beta1.Endpoint{
+			{
+				Addresses: []string{host},
+			},
+		},
+		Ports: []discoveryv1beta1.EndpointPort{
+			{
+				Name: stringPtr("https"),
+				Port: &port32,
+			},
+		},
+	}
+	var currentEndpointSlice discoveryv1beta1.EndpointSlice
+	err := r.Get(ctx, clientapi.ObjectKey{Namespace: cluster.Namespace, Name: serviceName}, &currentEndpointSlice)
+	if apierrors.IsNotFound(err) {
+		if err := r.Create(ctx, desiredEndpointSlice); err != nil {
+			klog.Errorf("could not create apiserver endpoint slice for cluster %q: %v", cluster.Name, err)
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	currentEndpointSlice.AddressType = desiredEndpointSlice.AddressType
+	currentEndpointSlice.Endpoints = desiredEndpointSlice.Endpoints
+	currentEndpointSlice.Ports = desiredEndpointSlice.Ports
+	if err := r.Update(ctx, &currentEndpointSlice); err != nil {
+		klog.Errorf("could not update apiserver endpoint slice for cluster %q: %v", cluster.Name, err)
+		return err
+	}
+	return nil
+}
+
+// reconcileAPIServerEndpointDeletion deletes the Service and
+// EndpointSlice for cluster, if they exist
+func (r *ClusterController) reconcileAPIServerEndpointDeletion(ctx context.Context, cluster *clusterapi.Cluster) error {
+	serviceName := apiServerServiceName(cluster.Name)
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: cluster.Namespace},
+	}
+	if err := r.Delete(ctx, service); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	endpointSlice := &discoveryv1beta1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{Name: serviceName, Namespace: cluster.Namespace},
+	}
+	if err := r.Delete(ctx, endpointSlice); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -33,6 +34,7 @@ import (
 type ClusterInitializer struct {
 	client.Client
 	Scheme            *runtime.Scheme
+	Scope             Scope
 	clusterReconciler *reconciler.ClusterReconciler
 }
 
@@ -54,12 +56,24 @@ func (r *ClusterInitializer) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	}
 
 	delete(cluster.Labels, constants.OneInfraClusterUninitializedCertificates)
+	cluster.MarkProvisioningPhase(clusterv1alpha1.ProvisioningPhaseCertificatesReady)
 
-	if err := r.Update(ctx, cluster.Export()); err != nil {
+	exportedCluster, err := cluster.Export()
+	if err != nil {
+		klog.Errorf("could not export cluster %q: %v", cluster.Name, err)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	if err := r.Update(ctx, exportedCluster); err != nil {
 		klog.Errorf("could not update cluster %q spec: %v", cluster.Name, err)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	if err := r.Status().Update(ctx, exportedCluster); err != nil {
+		klog.Errorf("could not update cluster %q status: %v", cluster.Name, err)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -67,6 +81,6 @@ func (r *ClusterInitializer) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 func (r *ClusterInitializer) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("cluster-initializer").
-		For(&clusterv1alpha1.Cluster{}).
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(r.Scope.Predicate())).
 		Complete(r)
 }
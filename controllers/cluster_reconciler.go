@@ -24,17 +24,24 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	clusterapi "github.com/oneinfra/oneinfra/internal/pkg/cluster"
 	clusterreconciler "github.com/oneinfra/oneinfra/internal/pkg/cluster/reconciler"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/conditions"
+	"github.com/oneinfra/oneinfra/internal/pkg/dashboard"
 	"github.com/oneinfra/oneinfra/internal/pkg/reconciler"
 )
 
 // ClusterReconciler reconciles a Cluster object
 type ClusterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme    *runtime.Scheme
+	Scope     Scope
+	Dashboard *dashboard.Aggregator
 }
 
 // +kubebuilder:rbac:groups=cluster.oneinfra.ereslibre.es,resources=components,verbs=get;list;watch;create;update;patch;delete
@@ -43,6 +50,8 @@ type ClusterReconciler struct {
 // +kubebuilder:rbac:groups=cluster.oneinfra.ereslibre.es,resources=clusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=infra.oneinfra.ereslibre.es,resources=hypervisors,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infra.oneinfra.ereslibre.es,resources=hypervisors/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=infra.oneinfra.ereslibre.es,resources=hypervisorpools,verbs=get;list;watch
+// +kubebuilder:rbac:groups=cluster.oneinfra.ereslibre.es,resources=freezewindows,verbs=get;list;watch
 
 // Reconcile reconciles the cluster resources
 func (r *ClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -66,15 +75,27 @@ func (r *ClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	cluster = clusterMap[cluster.Name]
 
 	res := ctrl.Result{}
+	reconcileStart := time.Now()
 
 	if cluster.DeletionTimestamp == nil {
 		if err := clusterReconciler.Reconcile(clusterreconciler.OptionalReconcile{}, cluster); err != nil {
 			klog.Errorf("failed to reconcile cluster %q: %v", req, err)
 			res = ctrl.Result{Requeue: true}
 		} else {
-			if err := r.Status().Update(ctx, cluster.Export()); err != nil {
-				klog.Errorf("could not update cluster %q: %v", cluster.Name, err)
+			r.markProvisioningPhases(clusterReconciler, cluster)
+			exportedCluster, err := cluster.Export()
+			if err != nil {
+				klog.Errorf("could not export cluster %q: %v", cluster.Name, err)
 				res = ctrl.Result{Requeue: true}
+			} else {
+				if err := r.Update(ctx, exportedCluster); err != nil {
+					klog.Errorf("could not update cluster %q: %v", cluster.Name, err)
+					res = ctrl.Result{Requeue: true}
+				}
+				if err := r.Status().Update(ctx, exportedCluster); err != nil {
+					klog.Errorf("could not update cluster %q status: %v", cluster.Name, err)
+					res = ctrl.Result{Requeue: true}
+				}
 			}
 		}
 	} else {
@@ -83,7 +104,11 @@ func (r *ClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			res = ctrl.Result{Requeue: true}
 		} else {
 			if cluster != nil {
-				if err := r.Update(ctx, cluster.Export()); err != nil {
+				exportedCluster, err := cluster.Export()
+				if err != nil {
+					klog.Errorf("could not export cluster %q: %v", cluster.Name, err)
+					res = ctrl.Result{Requeue: true}
+				} else if err := r.Update(ctx, exportedCluster); err != nil {
 					klog.Errorf("could not update cluster %q: %v", cluster.Name, err)
 					res = ctrl.Result{Requeue: true}
 				}
@@ -93,6 +118,10 @@ func (r *ClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		}
 	}
 
+	if r.Dashboard != nil {
+		r.recordDashboardSample(clusterReconciler, cluster, reconcileStart)
+	}
+
 	cluster.RefreshCachedSpecs()
 
 	if err := reconciler.UpdateResources(ctx, clusterReconciler, r); err != nil {
@@ -102,10 +131,51 @@ func (r *ClusterReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	return res, nil
 }
 
+// markProvisioningPhases records when cluster first reaches each
+// provisioning phase it has reached by now, so ProvisioningTimestamps
+// can be used to compute time-to-cluster and find the slowest phase
+// across the fleet
+func (r *ClusterReconciler) markProvisioningPhases(clusterReconciler *clusterreconciler.ClusterReconciler, cluster *clusterapi.Cluster) {
+	clusterComponents := clusterReconciler.ComponentList().WithCluster(cluster.Namespace, cluster.Name)
+	for _, clusterComponent := range clusterComponents {
+		if !clusterComponent.Conditions.IsCondition(component.ComponentReady, conditions.ConditionTrue) {
+			continue
+		}
+		switch clusterComponent.Role {
+		case component.ControlPlaneRole:
+			cluster.MarkProvisioningPhase(clusterv1alpha1.ProvisioningPhaseControlPlaneReady)
+		case component.ControlPlaneIngressRole:
+			cluster.MarkProvisioningPhase(clusterv1alpha1.ProvisioningPhaseIngressReady)
+		}
+	}
+	if cluster.Conditions.IsCondition(clusterapi.ReconcileSucceeded, conditions.ConditionTrue) {
+		cluster.MarkProvisioningPhase(clusterv1alpha1.ProvisioningPhaseClusterReady)
+	}
+}
+
+// recordDashboardSample records a health, latency and capacity
+// observation for cluster into r.Dashboard
+func (r *ClusterReconciler) recordDashboardSample(clusterReconciler *clusterreconciler.ClusterReconciler, cluster *clusterapi.Cluster, reconcileStart time.Time) {
+	clusterComponents := clusterReconciler.ComponentList().WithCluster(cluster.Namespace, cluster.Name)
+	readyComponentCount := 0
+	for _, clusterComponent := range clusterComponents {
+		if clusterComponent.Conditions.IsCondition(component.ComponentReady, conditions.ConditionTrue) {
+			readyComponentCount++
+		}
+	}
+	r.Dashboard.Record(cluster.Namespace, cluster.Name, dashboard.Sample{
+		Timestamp:              reconcileStart,
+		Ready:                  cluster.Conditions.IsCondition(clusterapi.ReconcileSucceeded, conditions.ConditionTrue),
+		ReconcileLatencyMillis: time.Since(reconcileStart).Milliseconds(),
+		ComponentCount:         len(clusterComponents),
+		ReadyComponentCount:    readyComponentCount,
+	})
+}
+
 // SetupWithManager sets up the cluster reconciler with mgr manager
 func (r *ClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("cluster-reconciler").
-		For(&clusterv1alpha1.Cluster{}).
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(r.Scope.Predicate())).
 		Complete(r)
 }
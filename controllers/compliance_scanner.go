@@ -0,0 +1,86 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/metrics"
+)
+
+// ComplianceRescanInterval is how often an already scanned cluster is
+// re-scanned for certificate and configuration compliance
+const ComplianceRescanInterval = time.Hour
+
+// ComplianceScanner periodically scans Cluster resources for
+// certificate and configuration compliance, recording findings on
+// their Compliance status and as a Prometheus gauge
+type ComplianceScanner struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Scope  Scope
+}
+
+// Reconcile runs a compliance scan against a single Cluster resource,
+// and unconditionally requeues after ComplianceRescanInterval so this
+// controller keeps re-scanning clusters on a schedule, rather than
+// only when they are otherwise mutated
+func (r *ComplianceScanner) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+
+	cluster, err := getCluster(ctx, r, req)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		klog.Errorf("could not get cluster %q: %v", req, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	cluster.Compliance = cluster.EvaluateCompliance()
+	metrics.SetClusterComplianceIssues(cluster.Namespace, cluster.Name, len(cluster.Compliance.Issues))
+
+	exportedCluster, err := cluster.Export()
+	if err != nil {
+		klog.Errorf("could not export cluster %q: %v", cluster.Name, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	if err := r.Status().Update(ctx, exportedCluster); err != nil {
+		klog.Errorf("could not update cluster %q compliance status: %v", cluster.Name, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: ComplianceRescanInterval}, nil
+}
+
+// SetupWithManager sets up the compliance scanner controller with mgr
+// manager
+func (r *ComplianceScanner) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("compliance-scanner").
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(r.Scope.Predicate())).
+		Complete(r)
+}
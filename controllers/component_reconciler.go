@@ -26,6 +26,7 @@ import (
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -39,6 +40,7 @@ import (
 type ComponentReconciler struct {
 	client.Client
 	Scheme         *runtime.Scheme
+	Scope          Scope
 	ConnectionPool infra.HypervisorConnectionPool
 }
 
@@ -163,6 +165,6 @@ func (r *ComponentReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 func (r *ComponentReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("component-controller").
-		For(&clusterv1alpha1.Component{}).
+		For(&clusterv1alpha1.Component{}, builder.WithPredicates(r.Scope.Predicate())).
 		Complete(r)
 }
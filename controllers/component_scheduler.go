@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
@@ -35,6 +36,7 @@ import (
 type ComponentScheduler struct {
 	client.Client
 	Scheme        *runtime.Scheme
+	Scope         Scope
 	hypervisorMap infra.HypervisorMap
 }
 
@@ -66,9 +68,14 @@ func (r *ComponentScheduler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	if err != nil {
 		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
 	}
-
-	privateHypervisors := r.hypervisorMap.PrivateList()
-	publicHypervisors := r.hypervisorMap.PublicList()
+	hypervisorPools, err := listHypervisorPools(ctx, r)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
+	clusters, err := listClusters(ctx, r)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, err
+	}
 
 	res := ctrl.Result{}
 
@@ -79,13 +86,32 @@ func (r *ComponentScheduler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 			klog.Errorf("could not convert versioned component to internal component: %v", err)
 			continue
 		}
+		schedulableHypervisors := r.hypervisorMap.WithPassingPreflight().WithoutCordoned()
+		hypervisorPoolName := ""
+		if cluster, exists := clusters[component.ClusterName]; exists {
+			hypervisorPoolName = cluster.HypervisorPool
+			if component.Role == componentapi.ControlPlaneIngressRole && cluster.IngressHypervisorPool != "" {
+				hypervisorPoolName = cluster.IngressHypervisorPool
+			}
+		}
+		schedulingStrategy := infra.SpreadSchedulingStrategy
+		var placementWebhook *infra.PlacementWebhook
+		if hypervisorPoolName != "" {
+			if hypervisorPool, exists := hypervisorPools[hypervisorPoolName]; exists {
+				schedulableHypervisors = schedulableHypervisors.WithNames(hypervisorPool.HypervisorNames)
+				if hypervisorPool.SchedulingStrategy != "" {
+					schedulingStrategy = hypervisorPool.SchedulingStrategy
+				}
+				placementWebhook = hypervisorPool.PlacementWebhook
+			}
+		}
 		switch component.Role {
 		case componentapi.ControlPlaneRole:
-			hypervisorList = privateHypervisors
+			hypervisorList = schedulableHypervisors.PrivateList()
 		case componentapi.ControlPlaneIngressRole:
-			hypervisorList = publicHypervisors
+			hypervisorList = schedulableHypervisors.PublicList()
 		}
-		scheduledHypervisor, err := hypervisorList.Sample()
+		scheduledHypervisor, err := hypervisorList.Select(schedulingStrategy, placementWebhook)
 		if err != nil {
 			if component.Name == req.Name && component.Namespace == req.Namespace {
 				res = ctrl.Result{RequeueAfter: 10 * time.Second}
@@ -107,6 +133,6 @@ func (r *ComponentScheduler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 func (r *ComponentScheduler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("component-scheduler").
-		For(&clusterv1alpha1.Component{}).
+		For(&clusterv1alpha1.Component{}, builder.WithPredicates(r.Scope.Predicate())).
 		Complete(r)
 }
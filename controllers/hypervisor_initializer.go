@@ -0,0 +1,82 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+)
+
+// HypervisorInitializer runs the host preflight checks against a
+// newly registered Hypervisor object
+type HypervisorInitializer struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Scope  Scope
+}
+
+// Reconcile runs the host preflight checks on hypervisors that have
+// not been probed yet
+func (r *HypervisorInitializer) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+
+	var versionedHypervisor infrav1alpha1.Hypervisor
+	if err := r.Get(ctx, req.NamespacedName, &versionedHypervisor); err != nil {
+		return ctrl.Result{}, nil
+	}
+
+	if len(versionedHypervisor.Status.PreflightChecks) > 0 {
+		return ctrl.Result{}, nil
+	}
+
+	hypervisor, err := infra.NewHypervisorFromv1alpha1(&versionedHypervisor, nil)
+	if err != nil {
+		klog.Errorf("could not convert hypervisor %q to internal type: %v", versionedHypervisor.Name, err)
+		return ctrl.Result{}, nil
+	}
+
+	if _, err := hypervisor.RunPreflightChecks("", "", ""); err != nil {
+		klog.Errorf("could not run preflight checks on hypervisor %q: %v", hypervisor.Name, err)
+		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	}
+
+	exportedHypervisor := hypervisor.Export()
+	exportedHypervisor.ResourceVersion = versionedHypervisor.ResourceVersion
+	if err := r.Status().Update(ctx, exportedHypervisor); err != nil {
+		klog.Errorf("could not update hypervisor %q preflight check status: %v", hypervisor.Name, err)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the hypervisor initializer with mgr manager
+func (r *HypervisorInitializer) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("hypervisor-initializer").
+		For(&infrav1alpha1.Hypervisor{}, builder.WithPredicates(r.Scope.Predicate())).
+		Complete(r)
+}
@@ -0,0 +1,133 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	componentapi "github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/component/components"
+	"github.com/oneinfra/oneinfra/internal/pkg/metrics"
+	"github.com/oneinfra/oneinfra/internal/pkg/reconciler"
+)
+
+// IngressStatsRescanInterval is how often an already scanned
+// cluster's control plane ingress haproxy statistics are refreshed
+const IngressStatsRescanInterval = time.Minute
+
+// IngressStatsScanner periodically collects haproxy backend
+// statistics from a cluster's control plane ingress, recording
+// findings on its Ingress status and as Prometheus gauges
+type IngressStatsScanner struct {
+	client.Client
+	Scheme *runtime.Scheme
+	Scope  Scope
+}
+
+// Reconcile collects haproxy backend statistics from a single
+// cluster's control plane ingress, and unconditionally requeues
+// after IngressStatsRescanInterval so this controller keeps
+// refreshing them on a schedule, rather than only when the cluster is
+// otherwise mutated
+func (r *IngressStatsScanner) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+
+	cluster, err := getCluster(ctx, r, req)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		klog.Errorf("could not get cluster %q: %v", req, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	componentReconciler, err := newComponentReconciler(ctx, r, cluster, nil)
+	if err != nil {
+		klog.Errorf("could not create a component reconciler for cluster %q: %v", req, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	ingressComponents := componentReconciler.ComponentList().
+		WithCluster(cluster.Namespace, cluster.Name).
+		WithRole(componentapi.ControlPlaneIngressRole)
+
+	backends := []clusterv1alpha1.IngressBackendStatus{}
+	for _, ingressComponent := range ingressComponents {
+		if ingressComponent.DeletionTimestamp != nil || ingressComponent.HypervisorName == "" {
+			continue
+		}
+		ingress := components.ControlPlaneIngress{}
+		ingressBackends, err := ingress.CollectStats(
+			&reconciler.Inquirer{
+				ReconciledComponent: ingressComponent,
+				Reconciler:          componentReconciler,
+			},
+		)
+		if err != nil {
+			klog.Errorf("could not collect ingress statistics for component %q in cluster %q: %v", ingressComponent.Name, req, err)
+			continue
+		}
+		backends = append(backends, ingressBackends...)
+	}
+
+	for _, backend := range backends {
+		metrics.SetClusterIngressBackendStats(
+			cluster.Namespace,
+			cluster.Name,
+			backend.Component,
+			backend.Up,
+			backend.CurrentSessions,
+			backend.ErrorResponses,
+		)
+	}
+
+	cluster.Ingress = &clusterv1alpha1.IngressStatus{
+		LastCollected: metav1.Now(),
+		Backends:      backends,
+	}
+
+	exportedCluster, err := cluster.Export()
+	if err != nil {
+		klog.Errorf("could not export cluster %q: %v", cluster.Name, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+	if err := r.Status().Update(ctx, exportedCluster); err != nil {
+		klog.Errorf("could not update cluster %q ingress status: %v", cluster.Name, err)
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: IngressStatsRescanInterval}, nil
+}
+
+// SetupWithManager sets up the ingress stats scanner controller with
+// mgr manager
+func (r *IngressStatsScanner) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("ingress-stats-scanner").
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(r.Scope.Predicate())).
+		Complete(r)
+}
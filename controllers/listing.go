@@ -51,6 +51,40 @@ func listHypervisors(ctx context.Context, client clientapi.Client, connectionPoo
 	return res, nil
 }
 
+func listHypervisorPools(ctx context.Context, client clientapi.Client) (infra.HypervisorPoolMap, error) {
+	var hypervisorPoolList infrav1alpha1.HypervisorPoolList
+	if err := client.List(ctx, &hypervisorPoolList); err != nil {
+		return infra.HypervisorPoolMap{}, err
+	}
+	res := infra.HypervisorPoolMap{}
+	for _, hypervisorPool := range hypervisorPoolList.Items {
+		internalHypervisorPool, err := infra.NewHypervisorPoolFromv1alpha1(&hypervisorPool)
+		if err != nil {
+			klog.Errorf("could not convert hypervisor pool to internal type: %v", err)
+			continue
+		}
+		res[internalHypervisorPool.Name] = internalHypervisorPool
+	}
+	return res, nil
+}
+
+func listFreezeWindows(ctx context.Context, client clientapi.Client) (clusterapi.FreezeWindowList, error) {
+	var freezeWindowList clusterv1alpha1.FreezeWindowList
+	if err := client.List(ctx, &freezeWindowList); err != nil {
+		return clusterapi.FreezeWindowList{}, err
+	}
+	res := clusterapi.FreezeWindowList{}
+	for _, freezeWindow := range freezeWindowList.Items {
+		internalFreezeWindow, err := clusterapi.NewFreezeWindowFromv1alpha1(&freezeWindow)
+		if err != nil {
+			klog.Errorf("could not convert freeze window to internal type: %v", err)
+			continue
+		}
+		res = append(res, internalFreezeWindow)
+	}
+	return res, nil
+}
+
 func listClusters(ctx context.Context, client clientapi.Client) (clusterapi.Map, error) {
 	var clusterList clusterv1alpha1.ClusterList
 	if err := client.List(ctx, &clusterList); err != nil {
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
@@ -33,6 +34,7 @@ import (
 type NodeJoinRequestReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	Scope  Scope
 }
 
 // Reconcile reconciles the node join requests for the given cluster
@@ -61,6 +63,6 @@ func (r *NodeJoinRequestReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 func (r *NodeJoinRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("node-join-request-reconciler").
-		For(&clusterv1alpha1.Cluster{}).
+		For(&clusterv1alpha1.Cluster{}, builder.WithPredicates(r.Scope.Predicate())).
 		Complete(r)
 }
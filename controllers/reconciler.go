@@ -59,9 +59,15 @@ func newClusterReconciler(ctx context.Context, client clientapi.Client, cluster
 		klog.Errorf("could not list components: %v", err)
 		return nil, err
 	}
+	freezeWindows, err := listFreezeWindows(ctx, client)
+	if err != nil {
+		klog.Errorf("could not list freeze windows: %v", err)
+		return nil, err
+	}
 	return clusterreconciler.NewClusterReconciler(
 		hypervisorMap,
 		clusterapi.Map{cluster.Name: cluster},
 		componentList,
+		freezeWindows,
 	), nil
 }
@@ -0,0 +1,68 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package controllers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Scope restricts which objects a manager instance's controllers
+// will reconcile, so that several manager instances sharing the same
+// CRD schema can each own a disjoint subset of clusters and
+// hypervisors (sharded or per-team manager deployments)
+type Scope struct {
+	// Namespaces restricts reconciliation to objects in one of these
+	// namespaces. Empty means no namespace restriction.
+	Namespaces []string
+
+	// LabelSelector restricts reconciliation to objects matching this
+	// label selector. Nil or empty means no label restriction.
+	LabelSelector labels.Selector
+}
+
+// Predicate returns a controller-runtime predicate enforcing this
+// scope against a reconciled object's namespace and labels
+func (scope Scope) Predicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return scope.matches(e.Meta) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return scope.matches(e.MetaNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return scope.matches(e.Meta) },
+		GenericFunc: func(e event.GenericEvent) bool { return scope.matches(e.Meta) },
+	}
+}
+
+func (scope Scope) matches(object metav1.Object) bool {
+	if len(scope.Namespaces) > 0 {
+		namespaceMatches := false
+		for _, namespace := range scope.Namespaces {
+			if object.GetNamespace() == namespace {
+				namespaceMatches = true
+				break
+			}
+		}
+		if !namespaceMatches {
+			return false
+		}
+	}
+	if scope.LabelSelector != nil && !scope.LabelSelector.Empty() && !scope.LabelSelector.Matches(labels.Set(object.GetLabels())) {
+		return false
+	}
+	return true
+}
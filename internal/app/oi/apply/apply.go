@@ -0,0 +1,95 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package apply submits exported Cluster and Hypervisor manifests to
+// the management API
+package apply
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// Apply submits the Cluster and Hypervisor manifests read from
+// stdin to the management API, printing the outcome of each. When
+// serverDryRun is true, resources are submitted with a server-side
+// dry run, so admission webhooks and validation run without
+// anything being persisted, letting users test spec changes before
+// committing them
+func Apply(serverDryRun bool) error {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "could not load a configuration to talk to the management API")
+	}
+	scheme := runtime.NewScheme()
+	if err := clusterv1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := infrav1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	managementClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return errors.Wrap(err, "could not create a client to talk to the management API")
+	}
+	return manifests.WithStdinResourcesSilent(
+		func(hypervisors infra.HypervisorMap, clusters cluster.Map, _ component.List) error {
+			createOptions := []client.CreateOption{}
+			if serverDryRun {
+				createOptions = append(createOptions, client.DryRunAll)
+			}
+			succeeded := true
+			for _, hypervisor := range hypervisors {
+				if err := managementClient.Create(context.TODO(), hypervisor.Export(), createOptions...); err != nil {
+					succeeded = false
+					fmt.Printf("hypervisor %q: rejected: %v\n", hypervisor.Name, err)
+					continue
+				}
+				fmt.Printf("hypervisor %q: accepted\n", hypervisor.Name)
+			}
+			for _, clusterObj := range clusters {
+				exportedCluster, err := clusterObj.Export()
+				if err != nil {
+					succeeded = false
+					fmt.Printf("cluster %q: could not export: %v\n", clusterObj.Name, err)
+					continue
+				}
+				if err := managementClient.Create(context.TODO(), exportedCluster, createOptions...); err != nil {
+					succeeded = false
+					fmt.Printf("cluster %q: rejected: %v\n", clusterObj.Name, err)
+					continue
+				}
+				fmt.Printf("cluster %q: accepted, tracking id %s\n", clusterObj.Name, exportedCluster.UID)
+			}
+			if !succeeded {
+				return errors.New("one or more resources failed admission or validation")
+			}
+			return nil
+		},
+	)
+}
@@ -0,0 +1,42 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package bundle
+
+import (
+	"fmt"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/bundle"
+)
+
+// Build builds an offline bundle for kubernetesVersion at outputPath
+func Build(kubernetesVersion, outputPath string) error {
+	if err := bundle.Build(kubernetesVersion, outputPath); err != nil {
+		return err
+	}
+	fmt.Printf("offline bundle for Kubernetes version %q was successfully built at %q\n", kubernetesVersion, outputPath)
+	return nil
+}
+
+// Push pushes the offline bundle at bundlePath onto the hypervisor
+// reachable at containerdAddress
+func Push(bundlePath, containerdAddress string) error {
+	if err := bundle.Push(bundlePath, containerdAddress); err != nil {
+		return err
+	}
+	fmt.Printf("offline bundle %q was successfully pushed\n", bundlePath)
+	return nil
+}
@@ -0,0 +1,91 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/clusterclass"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+	constantsapi "github.com/oneinfra/oneinfra/pkg/constants"
+)
+
+// Apply decomposes the ClusterClass document at path into the
+// underlying Cluster and Component resources, in the same way
+// Inject does for its individual flags
+func Apply(path string) error {
+	clusterClass, err := clusterclass.NewClusterClassFromFile(path)
+	if err != nil {
+		return err
+	}
+	if err := clusterClass.Validate(); err != nil {
+		return err
+	}
+	kubernetesVersion := clusterClass.KubernetesVersion
+	if kubernetesVersion == "" {
+		kubernetesVersion = constants.ReleaseData.DefaultKubernetesVersion
+	}
+	vpnEnabled := false
+	vpnCIDR := constantsapi.DefaultVPNCIDR
+	if clusterClass.VPN != nil {
+		vpnEnabled = clusterClass.VPN.Enabled
+		if clusterClass.VPN.CIDR != "" {
+			vpnCIDR = clusterClass.VPN.CIDR
+		}
+	}
+	return manifests.WithStdinResources(
+		func(hypervisors infra.HypervisorMap, clusters cluster.Map, components component.List) (component.List, error) {
+			newCluster, err := cluster.NewCluster(clusterClass.Name, kubernetesVersion, clusterClass.ControlPlaneReplicas, vpnEnabled, vpnCIDR, clusterClass.APIServerExtraSANs)
+			if err != nil {
+				return component.List{}, err
+			}
+			newCluster.HypervisorPool = clusterClass.HypervisorPool
+			clusters[clusterClass.Name] = newCluster
+			privateHypervisorList := hypervisors.PrivateList()
+			for i := 1; i <= newCluster.ControlPlaneReplicas; i++ {
+				component, err := component.NewComponentWithRandomHypervisor(
+					clusterClass.Name,
+					fmt.Sprintf("%s-control-plane-%d", clusterClass.Name, i),
+					component.ControlPlaneRole,
+					privateHypervisorList,
+				)
+				if err != nil {
+					klog.Fatalf("could not create new component: %v", err)
+				}
+				components = append(components, component)
+			}
+			publicHypervisorList := hypervisors.PublicList()
+			component, err := component.NewComponentWithRandomHypervisor(
+				clusterClass.Name,
+				fmt.Sprintf("%s-control-plane-ingress", clusterClass.Name),
+				component.ControlPlaneIngressRole,
+				publicHypervisorList,
+			)
+			if err != nil {
+				klog.Fatalf("could not create new ingress component: %v", err)
+			}
+			components = append(components, component)
+			return components, nil
+		},
+	)
+}
@@ -0,0 +1,136 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// argoCDTLSClientConfig mirrors the tlsClientConfig fragment of the
+// Argo CD cluster Secret config field
+type argoCDTLSClientConfig struct {
+	CAData   string `json:"caData"`
+	CertData string `json:"certData"`
+	KeyData  string `json:"keyData"`
+}
+
+// argoCDClusterConfig mirrors the Argo CD cluster Secret config field
+type argoCDClusterConfig struct {
+	TLSClientConfig argoCDTLSClientConfig `json:"tlsClientConfig"`
+}
+
+// ConnectionInfo prints a Kubernetes Secret with the admin connection
+// details (endpoint, CA and a client certificate) for cluster
+// clusterName, rendered in the requested format so common CD tools
+// can consume it directly, instead of glue scripts extracting these
+// details from a kubeconfig file
+func ConnectionInfo(clusterName, format string) error {
+	return manifests.WithStdinResourcesSilent(
+		func(_ infra.HypervisorMap, clusters cluster.Map, _ component.List) error {
+			return manifests.WithNamedCluster(clusterName, clusters, func(cluster *cluster.Cluster) error {
+				kubeConfig, err := cluster.AdminKubeConfig()
+				if err != nil {
+					return err
+				}
+				restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeConfig))
+				if err != nil {
+					return errors.Wrapf(err, "could not parse generated kubeconfig for cluster %q", clusterName)
+				}
+				var secret *corev1.Secret
+				switch format {
+				case "argocd":
+					secret = argoCDConnectionInfoSecret(clusterName, restConfig)
+				case "flux":
+					secret = fluxConnectionInfoSecret(clusterName, kubeConfig)
+				default:
+					return errors.Errorf("unknown connection info format %q, expected one of: argocd, flux", format)
+				}
+				manifest, err := yaml.Marshal(secret)
+				if err != nil {
+					return errors.Wrapf(err, "could not marshal connection info for cluster %q", clusterName)
+				}
+				fmt.Print(string(manifest))
+				return nil
+			})
+		},
+	)
+}
+
+// argoCDConnectionInfoSecret renders an Argo CD cluster Secret, as
+// documented in
+// https://argo-cd.readthedocs.io/en/stable/operator-manual/declarative-setup/#clusters
+func argoCDConnectionInfoSecret(clusterName string, restConfig *rest.Config) *corev1.Secret {
+	clusterConfig := argoCDClusterConfig{
+		TLSClientConfig: argoCDTLSClientConfig{
+			CAData:   base64.StdEncoding.EncodeToString(restConfig.CAData),
+			CertData: base64.StdEncoding.EncodeToString(restConfig.CertData),
+			KeyData:  base64.StdEncoding.EncodeToString(restConfig.KeyData),
+		},
+	}
+	encodedConfig, _ := json.Marshal(clusterConfig)
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-cluster-secret", clusterName),
+			Labels: map[string]string{
+				"argocd.argoproj.io/secret-type": "cluster",
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			"name":   clusterName,
+			"server": restConfig.Host,
+			"config": string(encodedConfig),
+		},
+	}
+}
+
+// fluxConnectionInfoSecret renders a Flux Kubeconfig Secret, as
+// documented in
+// https://fluxcd.io/flux/components/kustomize/kustomizations/#remote-clusters--cluster-api
+func fluxConnectionInfoSecret(clusterName, kubeConfig string) *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-kubeconfig", clusterName),
+		},
+		Type: corev1.SecretTypeOpaque,
+		StringData: map[string]string{
+			"value": kubeConfig,
+		},
+	}
+}
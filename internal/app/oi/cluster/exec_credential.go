@@ -0,0 +1,83 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/certificates"
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// ExecCredential mints a fresh client certificate for commonName and
+// organization, signed by clusterName's API server client CA, and
+// prints it to stdout as a client.authentication.k8s.io ExecCredential
+// object. A kubeconfig can point its user's exec block at this
+// command instead of embedding a client certificate directly, so a
+// fresh, short-lived certificate is minted on every kubectl
+// invocation rather than a long-lived one sitting in a file handed to
+// a human
+func ExecCredential(clusterName, commonName string, organization []string) error {
+	return manifests.WithStdinResourcesSilent(
+		func(_ infra.HypervisorMap, clusters cluster.Map, _ component.List) error {
+			return manifests.WithNamedCluster(clusterName, clusters, func(cluster *cluster.Cluster) error {
+				certificatePEM, privateKeyPEM, err := cluster.CertificateAuthorities.APIServerClient.CreateCertificate(commonName, organization, []string{})
+				if err != nil {
+					return err
+				}
+				clientCertificate := &certificates.Certificate{Certificate: certificatePEM, PrivateKey: privateKeyPEM}
+				expirationTimestamp, err := clientCertificate.NotAfter()
+				if err != nil {
+					return err
+				}
+				execCredential, err := marshalExecCredential(certificatePEM, privateKeyPEM, expirationTimestamp)
+				if err != nil {
+					return err
+				}
+				fmt.Println(execCredential)
+				return nil
+			})
+		},
+	)
+}
+
+func marshalExecCredential(clientCertificate, clientKey string, expirationTimestamp time.Time) (string, error) {
+	execCredential := clientauthenticationv1beta1.ExecCredential{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clientauthenticationv1beta1.SchemeGroupVersion.String(),
+			Kind:       "ExecCredential",
+		},
+		Status: &clientauthenticationv1beta1.ExecCredentialStatus{
+			ExpirationTimestamp:   &metav1.Time{Time: expirationTimestamp},
+			ClientCertificateData: clientCertificate,
+			ClientKeyData:         clientKey,
+		},
+	}
+	encoded, err := json.Marshal(execCredential)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
@@ -0,0 +1,103 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+// historyEntry is the read-only representation of a single cluster
+// history entry, as printed by `oi cluster history`
+type historyEntry struct {
+	Timestamp string `json:"timestamp"`
+	Operation string `json:"operation"`
+	Outcome   string `json:"outcome"`
+	Message   string `json:"message,omitempty"`
+}
+
+// History prints the lifecycle history recorded for the cluster
+// named clusterName in namespace, querying the management API
+// directly since this history outlives Kubernetes' own Event TTL
+func History(namespace, clusterName, format string) error {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "could not load a configuration to talk to the management API")
+	}
+	scheme := runtime.NewScheme()
+	if err := clusterv1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	managementClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return errors.Wrap(err, "could not create a client to talk to the management API")
+	}
+	var versionedCluster clusterv1alpha1.Cluster
+	if err := managementClient.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: clusterName}, &versionedCluster); err != nil {
+		return errors.Wrapf(err, "could not get cluster %q", clusterName)
+	}
+	entries := make([]historyEntry, 0, len(versionedCluster.Status.History))
+	for _, entry := range versionedCluster.Status.History {
+		entries = append(entries, historyEntry{
+			Timestamp: entry.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
+			Operation: entry.Operation,
+			Outcome:   entry.Outcome,
+			Message:   entry.Message,
+		})
+	}
+	return printHistory(entries, format)
+}
+
+func printHistory(entries []historyEntry, format string) error {
+	switch format {
+	case "table":
+		printHistoryTable(entries)
+		return nil
+	case "json":
+		return printHistoryJSON(entries)
+	default:
+		return errors.Errorf("unknown format %q, expected one of: table, json", format)
+	}
+}
+
+func printHistoryJSON(entries []historyEntry) error {
+	encoded, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+func printHistoryTable(entries []historyEntry) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "TIMESTAMP\tOPERATION\tOUTCOME\tMESSAGE")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", entry.Timestamp, entry.Operation, entry.Outcome, entry.Message)
+	}
+	writer.Flush()
+}
@@ -18,6 +18,11 @@ package cluster
 
 import (
 	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
 	"github.com/oneinfra/oneinfra/internal/pkg/component"
@@ -40,3 +45,62 @@ func AdminKubeConfig(clusterName string) error {
 		},
 	)
 }
+
+// MergeKubeConfig generates an administrative kubeconfig file for
+// cluster clusterName and merges it into the kubeconfig file located
+// at kubeConfigPath, naming the merged cluster, user and context
+// contextName (defaulting to clusterName when empty). If setCurrent
+// is true, the merged context is set as the current context
+func MergeKubeConfig(clusterName, kubeConfigPath, contextName string, setCurrent bool) error {
+	return manifests.WithStdinResourcesSilent(
+		func(_ infra.HypervisorMap, clusters cluster.Map, _ component.List) error {
+			return manifests.WithNamedCluster(clusterName, clusters, func(cluster *cluster.Cluster) error {
+				kubeConfig, err := cluster.AdminKubeConfig()
+				if err != nil {
+					return err
+				}
+				generatedConfig, err := clientcmd.Load([]byte(kubeConfig))
+				if err != nil {
+					return errors.Errorf("could not parse generated kubeconfig for cluster %q: %v", clusterName, err)
+				}
+				if contextName == "" {
+					contextName = clusterName
+				}
+				targetConfig, err := loadOrNewKubeConfig(kubeConfigPath)
+				if err != nil {
+					return err
+				}
+				mergeContext(generatedConfig, targetConfig, clusterName, contextName)
+				if setCurrent {
+					targetConfig.CurrentContext = contextName
+				}
+				return clientcmd.WriteToFile(*targetConfig, kubeConfigPath)
+			})
+		},
+	)
+}
+
+// loadOrNewKubeConfig loads the kubeconfig at kubeConfigPath, or
+// returns a new, empty one when the file does not exist
+func loadOrNewKubeConfig(kubeConfigPath string) (*clientcmdapi.Config, error) {
+	if _, err := os.Stat(kubeConfigPath); os.IsNotExist(err) {
+		return clientcmdapi.NewConfig(), nil
+	}
+	config, err := clientcmd.LoadFromFile(kubeConfigPath)
+	if err != nil {
+		return nil, errors.Errorf("could not load kubeconfig %q: %v", kubeConfigPath, err)
+	}
+	return config, nil
+}
+
+// mergeContext copies the cluster, user and context named
+// generatedName from generatedConfig into targetConfig, renaming them
+// to contextName
+func mergeContext(generatedConfig, targetConfig *clientcmdapi.Config, generatedName, contextName string) {
+	targetConfig.Clusters[contextName] = generatedConfig.Clusters[generatedName]
+	targetConfig.AuthInfos[contextName] = generatedConfig.AuthInfos[generatedName]
+	context := generatedConfig.Contexts[generatedName].DeepCopy()
+	context.Cluster = contextName
+	context.AuthInfo = contextName
+	targetConfig.Contexts[contextName] = context
+}
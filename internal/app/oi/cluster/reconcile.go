@@ -25,18 +25,29 @@ import (
 	clusterreconciler "github.com/oneinfra/oneinfra/internal/pkg/cluster/reconciler"
 	"github.com/oneinfra/oneinfra/internal/pkg/component"
 	componentreconciler "github.com/oneinfra/oneinfra/internal/pkg/component/reconciler"
+	"github.com/oneinfra/oneinfra/internal/pkg/filelock"
 	"github.com/oneinfra/oneinfra/internal/pkg/infra"
 	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
 	"github.com/oneinfra/oneinfra/internal/pkg/reconciler"
 	"github.com/pkg/errors"
 )
 
-// Reconcile reconciles all clusters
-func Reconcile(maxRetries int, retryWaitTime time.Duration) error {
+// Reconcile reconciles all clusters. When lockFile is not empty, an
+// exclusive advisory lock on it is held for the duration of the
+// reconcile, so two concurrent "oi reconcile" invocations sharing the
+// same lock file serialize instead of racing over the same resources
+func Reconcile(maxRetries int, retryWaitTime time.Duration, lockFile string) error {
+	if lockFile != "" {
+		lock, err := filelock.Acquire(lockFile)
+		if err != nil {
+			return errors.Wrap(err, "could not acquire reconcile lock")
+		}
+		defer lock.Release()
+	}
 	return manifests.WithStdinResources(
 		func(hypervisors infra.HypervisorMap, clusters cluster.Map, components component.List) (component.List, error) {
 			componentReconciler := componentreconciler.NewComponentReconciler(hypervisors, clusters, components)
-			clusterReconciler := clusterreconciler.NewClusterReconciler(hypervisors, clusters, components)
+			clusterReconciler := clusterreconciler.NewClusterReconciler(hypervisors, clusters, components, cluster.FreezeWindowList{})
 			var componentReconcileErrs, clusterReconcileErrs reconciler.ReconcileErrors
 			for i := 0; i < maxRetries; i++ {
 				componentReconcileErrs = componentReconciler.Reconcile()
@@ -0,0 +1,138 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+// provisioningPhaseStatus is the read-only representation of a
+// single provisioning phase and when it was reached, as printed by
+// `oi cluster status`
+type provisioningPhaseStatus struct {
+	Phase     string `json:"phase"`
+	Timestamp string `json:"timestamp"`
+}
+
+// clusterStatus is the read-only representation of a cluster's
+// tracking identifier and provisioning progress, as printed by `oi
+// cluster status`
+type clusterStatus struct {
+	TrackingID  string                    `json:"trackingID"`
+	Ready       bool                      `json:"ready"`
+	Provisioned []provisioningPhaseStatus `json:"provisioned"`
+}
+
+// Status prints the tracking identifier and provisioning progress
+// recorded for the cluster named clusterName in namespace, querying
+// the management API directly. Cluster creation through `oi apply`
+// returns as soon as the Cluster resource is accepted; this command
+// lets callers follow up on the certificate generation, control
+// plane bring up and ingress setup that the manager then carries out
+// asynchronously. When wait is true, it polls until the cluster
+// reaches ProvisioningPhaseClusterReady instead of reporting once
+func Status(namespace, clusterName, format string, wait bool) error {
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		return errors.Wrap(err, "could not load a configuration to talk to the management API")
+	}
+	scheme := runtime.NewScheme()
+	if err := clusterv1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	managementClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return errors.Wrap(err, "could not create a client to talk to the management API")
+	}
+	for {
+		status, ready, err := getClusterStatus(managementClient, namespace, clusterName)
+		if err != nil {
+			return err
+		}
+		if !wait || ready {
+			return printClusterStatus(status, format)
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func getClusterStatus(managementClient client.Client, namespace, clusterName string) (clusterStatus, bool, error) {
+	var versionedCluster clusterv1alpha1.Cluster
+	if err := managementClient.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: clusterName}, &versionedCluster); err != nil {
+		return clusterStatus{}, false, errors.Wrapf(err, "could not get cluster %q", clusterName)
+	}
+	provisioned := make([]provisioningPhaseStatus, 0, len(versionedCluster.Status.ProvisioningTimestamps))
+	ready := false
+	for phase, timestamp := range versionedCluster.Status.ProvisioningTimestamps {
+		provisioned = append(provisioned, provisioningPhaseStatus{
+			Phase:     string(phase),
+			Timestamp: timestamp.Format("2006-01-02T15:04:05Z07:00"),
+		})
+		if phase == clusterv1alpha1.ProvisioningPhaseClusterReady {
+			ready = true
+		}
+	}
+	return clusterStatus{
+		TrackingID:  string(versionedCluster.UID),
+		Ready:       ready,
+		Provisioned: provisioned,
+	}, ready, nil
+}
+
+func printClusterStatus(status clusterStatus, format string) error {
+	switch format {
+	case "table":
+		printClusterStatusTable(status)
+		return nil
+	case "json":
+		return printClusterStatusJSON(status)
+	default:
+		return errors.Errorf("unknown format %q, expected one of: table, json", format)
+	}
+}
+
+func printClusterStatusJSON(status clusterStatus) error {
+	encoded, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+func printClusterStatusTable(status clusterStatus) {
+	fmt.Printf("TRACKING ID\t%s\n", status.TrackingID)
+	fmt.Printf("READY\t%t\n", status.Ready)
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "PHASE\tTIMESTAMP")
+	for _, phase := range status.Provisioned {
+		fmt.Fprintf(writer, "%s\t%s\n", phase.Phase, phase.Timestamp)
+	}
+	writer.Flush()
+}
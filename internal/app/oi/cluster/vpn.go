@@ -0,0 +1,88 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// VPNPeerClientConfig prints a ready to use wg-quick configuration for
+// the named VPN peer of clusterName, so a human operator can join the
+// cluster VPN for debugging without hand-assembling one
+func VPNPeerClientConfig(clusterName, peerName string) error {
+	return manifests.WithStdinResourcesSilent(
+		func(_ infra.HypervisorMap, clusters cluster.Map, _ component.List) error {
+			return manifests.WithNamedCluster(clusterName, clusters, func(cluster *cluster.Cluster) error {
+				clientConfig, err := cluster.VPNPeerClientConfig(peerName)
+				if err != nil {
+					return err
+				}
+				fmt.Print(clientConfig)
+				return nil
+			})
+		},
+	)
+}
+
+// AddVPNPeer mints a new on-demand VPN peer for clusterName with the
+// given purpose (worker, ingress, admin-access), subject to the
+// cluster's per-purpose VPN.PeerQuotas; prints resulting manifests in
+// stdout, and the generated peer's private key in stderr. When ttl is
+// non-empty (e.g. "8h"), the peer is minted with an expiry, and
+// `oneinfra` releases it automatically once it elapses, so access
+// granted for debugging never becomes permanent by oversight
+func AddVPNPeer(clusterName, peerName, purpose, ttl string) error {
+	var ttlDuration *time.Duration
+	if ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			return errors.Wrap(err, "invalid --ttl value")
+		}
+		ttlDuration = &parsed
+	}
+	return manifests.WithStdinResources(
+		func(_ infra.HypervisorMap, clusters cluster.Map, components component.List) (component.List, error) {
+			var vpnPeer *cluster.VPNPeer
+			err := manifests.WithNamedCluster(clusterName, clusters, func(cluster *cluster.Cluster) error {
+				if cluster.VPN == nil || !cluster.VPN.Enabled {
+					return errors.Errorf("cluster %q does not have the VPN enabled", cluster.Name)
+				}
+				peer, err := cluster.GenerateVPNPeer(peerName, clusterv1alpha1.VPNPeerPurpose(purpose), ttlDuration)
+				if err != nil {
+					return err
+				}
+				vpnPeer = peer
+				return nil
+			})
+			if err != nil {
+				return component.List{}, err
+			}
+			fmt.Fprintln(os.Stderr, vpnPeer.PrivateKey)
+			return components, nil
+		},
+	)
+}
@@ -0,0 +1,195 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package hypervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// orphanPod represents a pod sandbox found running on an hypervisor
+// that does not match any declared component
+type orphanPod struct {
+	Hypervisor       string `json:"hypervisor"`
+	SandboxID        string `json:"sandboxID"`
+	ClusterNamespace string `json:"clusterNamespace"`
+	Cluster          string `json:"cluster"`
+	Component        string `json:"component"`
+}
+
+// missingPod represents a declared component that could not be
+// found running on the hypervisor it is assigned to
+type missingPod struct {
+	Hypervisor       string `json:"hypervisor"`
+	ClusterNamespace string `json:"clusterNamespace"`
+	Cluster          string `json:"cluster"`
+	Component        string `json:"component"`
+}
+
+// unreachableHypervisor represents an hypervisor that could not be
+// reached while auditing it
+type unreachableHypervisor struct {
+	Hypervisor string `json:"hypervisor"`
+	Error      string `json:"error"`
+}
+
+// auditReport is the machine readable result of an `oi hypervisor
+// audit` run
+type auditReport struct {
+	Orphans     []orphanPod             `json:"orphans"`
+	Missing     []missingPod            `json:"missing"`
+	Unreachable []unreachableHypervisor `json:"unreachable"`
+}
+
+// componentKey identifies a declared component by the triple an
+// owned pod's bookkeeping labels are compared against
+type componentKey struct {
+	clusterNamespace string
+	clusterName      string
+	componentName    string
+}
+
+// Audit connects to every registered hypervisor, lists the pods it
+// finds running that carry oneinfra's own bookkeeping labels, and
+// cross-references them with the declared components to report
+// orphan pods (running, but no longer declared) and missing pods
+// (declared, but not found running). When prune is true, orphan pods
+// are deleted from their hypervisor after being reported
+func Audit(format string, prune bool) error {
+	return manifests.WithStdinResourcesSilent(
+		func(hypervisors infra.HypervisorMap, _ cluster.Map, components component.List) error {
+			report := auditReport{
+				Orphans:     []orphanPod{},
+				Missing:     []missingPod{},
+				Unreachable: []unreachableHypervisor{},
+			}
+			declaredByHypervisor := map[string]map[componentKey]bool{}
+			for _, comp := range components {
+				key := componentKey{
+					clusterNamespace: comp.Namespace,
+					clusterName:      comp.ClusterName,
+					componentName:    comp.Name,
+				}
+				if declaredByHypervisor[comp.HypervisorName] == nil {
+					declaredByHypervisor[comp.HypervisorName] = map[componentKey]bool{}
+				}
+				declaredByHypervisor[comp.HypervisorName][key] = true
+			}
+			hypervisorNames := make([]string, 0, len(hypervisors))
+			for name := range hypervisors {
+				hypervisorNames = append(hypervisorNames, name)
+			}
+			sort.Strings(hypervisorNames)
+			for _, hypervisorName := range hypervisorNames {
+				hypervisor := hypervisors[hypervisorName]
+				ownedPods, err := hypervisor.ListOwnedPods()
+				if err != nil {
+					klog.Errorf("could not list owned pods on hypervisor %q: %v", hypervisorName, err)
+					report.Unreachable = append(report.Unreachable, unreachableHypervisor{
+						Hypervisor: hypervisorName,
+						Error:      err.Error(),
+					})
+					continue
+				}
+				found := map[componentKey]bool{}
+				for _, ownedPod := range ownedPods {
+					key := componentKey{
+						clusterNamespace: ownedPod.ClusterNamespace,
+						clusterName:      ownedPod.ClusterName,
+						componentName:    ownedPod.ComponentName,
+					}
+					found[key] = true
+					if declaredByHypervisor[hypervisorName][key] {
+						continue
+					}
+					report.Orphans = append(report.Orphans, orphanPod{
+						Hypervisor:       hypervisorName,
+						SandboxID:        ownedPod.SandboxID,
+						ClusterNamespace: ownedPod.ClusterNamespace,
+						Cluster:          ownedPod.ClusterName,
+						Component:        ownedPod.ComponentName,
+					})
+					if prune {
+						klog.Infof("pruning orphan pod %q from hypervisor %q", ownedPod.SandboxID, hypervisorName)
+						if err := hypervisor.DeletePodWithID(ownedPod.SandboxID); err != nil {
+							klog.Errorf("could not prune orphan pod %q from hypervisor %q: %v", ownedPod.SandboxID, hypervisorName, err)
+						}
+					}
+				}
+				for key := range declaredByHypervisor[hypervisorName] {
+					if found[key] {
+						continue
+					}
+					report.Missing = append(report.Missing, missingPod{
+						Hypervisor:       hypervisorName,
+						ClusterNamespace: key.clusterNamespace,
+						Cluster:          key.clusterName,
+						Component:        key.componentName,
+					})
+				}
+			}
+			return printAuditReport(report, format)
+		},
+	)
+}
+
+func printAuditReport(report auditReport, format string) error {
+	switch format {
+	case "table":
+		printAuditTable(report)
+		return nil
+	case "json":
+		return printAuditJSON(report)
+	default:
+		return errors.Errorf("unknown format %q, expected one of: table, json", format)
+	}
+}
+
+func printAuditJSON(report auditReport) error {
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+func printAuditTable(report auditReport) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "KIND\tHYPERVISOR\tCLUSTER NAMESPACE\tCLUSTER\tCOMPONENT\tDETAIL")
+	for _, orphan := range report.Orphans {
+		fmt.Fprintf(writer, "orphan\t%s\t%s\t%s\t%s\t%s\n", orphan.Hypervisor, orphan.ClusterNamespace, orphan.Cluster, orphan.Component, orphan.SandboxID)
+	}
+	for _, missing := range report.Missing {
+		fmt.Fprintf(writer, "missing\t%s\t%s\t%s\t%s\t\n", missing.Hypervisor, missing.ClusterNamespace, missing.Cluster, missing.Component)
+	}
+	for _, unreachable := range report.Unreachable {
+		fmt.Fprintf(writer, "unreachable\t%s\t\t\t\t%s\n", unreachable.Hypervisor, unreachable.Error)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,60 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package hypervisor
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
+)
+
+// Cordon marks the hypervisor named hypervisorName as unschedulable,
+// so the placement scheduler stops placing new components on it.
+// Components already scheduled there keep running; use Drain to move
+// them elsewhere
+func Cordon(hypervisorName string) error {
+	return setUnschedulable(hypervisorName, true)
+}
+
+// Uncordon clears the hypervisor named hypervisorName's unschedulable
+// mark, making it eligible again for new component placement
+func Uncordon(hypervisorName string) error {
+	return setUnschedulable(hypervisorName, false)
+}
+
+func setUnschedulable(hypervisorName string, unschedulable bool) error {
+	managementClient, err := newManagementClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	var versionedHypervisor infrav1alpha1.Hypervisor
+	if err := managementClient.Get(ctx, client.ObjectKey{Name: hypervisorName}, &versionedHypervisor); err != nil {
+		return errors.Wrapf(err, "could not get hypervisor %q", hypervisorName)
+	}
+	if versionedHypervisor.Spec.Unschedulable == unschedulable {
+		return nil
+	}
+	versionedHypervisor.Spec.Unschedulable = unschedulable
+	if err := managementClient.Update(ctx, &versionedHypervisor); err != nil {
+		return errors.Wrapf(err, "could not update hypervisor %q", hypervisorName)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package hypervisor
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// Describe prints the registered capacity, current allocations and
+// health of the hypervisor named hypervisorName, in the requested
+// format. When live is true, a live CRI query is performed against
+// the hypervisor to report its current health
+func Describe(hypervisorName, format string, live bool) error {
+	return manifests.WithStdinResourcesSilent(
+		func(hypervisors infra.HypervisorMap, _ cluster.Map, components component.List) error {
+			hypervisor, exists := hypervisors[hypervisorName]
+			if !exists {
+				return errors.Errorf("hypervisor %q not found", hypervisorName)
+			}
+			return printViews([]view{newView(hypervisor, components, live)}, format)
+		},
+	)
+}
@@ -0,0 +1,64 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package hypervisor
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+// Drain cordons the hypervisor named hypervisorName, then clears the
+// Hypervisor field of every component currently scheduled on it, so
+// the component scheduler assigns each of them a new hypervisor on
+// its next reconcile. A drained control plane replica does not carry
+// its on-disk state (e.g. etcd data) along with it: it comes back up
+// as a fresh replica on its new hypervisor and rejoins the cluster
+// the same way a replica recovering from a lost hypervisor already
+// does, rather than through a dedicated state migration step
+func Drain(hypervisorName string) error {
+	if err := Cordon(hypervisorName); err != nil {
+		return err
+	}
+	managementClient, err := newManagementClient()
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	var componentList clusterv1alpha1.ComponentList
+	if err := managementClient.List(ctx, &componentList); err != nil {
+		return errors.Wrap(err, "could not list components")
+	}
+	drainedComponents := 0
+	for i := range componentList.Items {
+		scheduledComponent := &componentList.Items[i]
+		if scheduledComponent.Spec.Hypervisor != hypervisorName {
+			continue
+		}
+		scheduledComponent.Spec.Hypervisor = ""
+		if err := managementClient.Update(ctx, scheduledComponent); err != nil {
+			klog.Errorf("could not unschedule component %q from hypervisor %q: %v", scheduledComponent.Name, hypervisorName, err)
+			continue
+		}
+		drainedComponents++
+	}
+	klog.Infof("drained %d component(s) from hypervisor %q; they will be rescheduled onto another hypervisor", drainedComponents, hypervisorName)
+	return nil
+}
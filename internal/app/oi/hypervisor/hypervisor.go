@@ -0,0 +1,165 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package hypervisor implements the `oi hypervisor` commands: the
+// read-only list, describe and audit views, and the cordon, uncordon
+// and drain commands that mutate live Hypervisor and Component
+// resources through the management API
+package hypervisor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+)
+
+// allocation represents a single component scheduled on an
+// hypervisor, as reflected by a view
+type allocation struct {
+	ClusterNamespace string `json:"clusterNamespace"`
+	Cluster          string `json:"cluster"`
+	Component        string `json:"component"`
+	Role             string `json:"role"`
+}
+
+// portAllocation represents a single exposed port allocated out of
+// an hypervisor's port range
+type portAllocation struct {
+	ClusterNamespace string `json:"clusterNamespace"`
+	Cluster          string `json:"cluster"`
+	Component        string `json:"component"`
+	Port             int    `json:"port"`
+}
+
+// view is the read-only representation of an hypervisor's
+// registered capacity, current allocations and health, as printed
+// by the `oi hypervisor` commands
+type view struct {
+	Name              string           `json:"name"`
+	Public            bool             `json:"public"`
+	IPAddress         string           `json:"ipAddress"`
+	PortRangeLow      int              `json:"portRangeLow"`
+	PortRangeHigh     int              `json:"portRangeHigh"`
+	AllocatedPorts    []portAllocation `json:"allocatedPorts"`
+	FreedPorts        []int            `json:"freedPorts"`
+	AllowedCPUSet     string           `json:"allowedCPUSet,omitempty"`
+	AllowedMemoryMB   int64            `json:"allowedMemoryMB,omitempty"`
+	Allocations       []allocation     `json:"allocations"`
+	Healthy           *bool            `json:"healthy,omitempty"`
+	HealthCheckFailed string           `json:"healthCheckFailed,omitempty"`
+}
+
+// newView builds the read-only view for hypervisor, with its
+// current component allocations taken from components. When live is
+// true, a live CRI query is performed against the hypervisor to
+// populate its health, instead of leaving it unset
+func newView(hypervisor *infra.Hypervisor, components component.List, live bool) view {
+	summary := hypervisor.Summary()
+	res := view{
+		Name:            summary.Name,
+		Public:          summary.Public,
+		IPAddress:       summary.IPAddress,
+		PortRangeLow:    summary.PortRangeLow,
+		PortRangeHigh:   summary.PortRangeHigh,
+		FreedPorts:      summary.FreedPorts,
+		AllowedCPUSet:   summary.AllowedCPUSet,
+		AllowedMemoryMB: summary.AllowedMemoryMB,
+		AllocatedPorts:  []portAllocation{},
+		Allocations:     []allocation{},
+	}
+	for _, allocatedPort := range summary.AllocatedPorts {
+		res.AllocatedPorts = append(res.AllocatedPorts, portAllocation{
+			ClusterNamespace: allocatedPort.ClusterNamespace,
+			Cluster:          allocatedPort.Cluster,
+			Component:        allocatedPort.Component,
+			Port:             allocatedPort.Port,
+		})
+	}
+	for _, comp := range components {
+		if comp.HypervisorName != summary.Name {
+			continue
+		}
+		res.Allocations = append(res.Allocations, allocation{
+			ClusterNamespace: comp.Namespace,
+			Cluster:          comp.ClusterName,
+			Component:        comp.Name,
+			Role:             string(comp.Role),
+		})
+	}
+	if live {
+		healthy, err := hypervisor.Health()
+		if err != nil {
+			res.HealthCheckFailed = err.Error()
+		} else {
+			res.Healthy = &healthy
+		}
+	}
+	return res
+}
+
+// printViews prints views in the requested format (table or json)
+func printViews(views []view, format string) error {
+	switch format {
+	case "table":
+		printTable(views)
+		return nil
+	case "json":
+		return printJSON(views)
+	default:
+		return errors.Errorf("unknown format %q, expected one of: table, json", format)
+	}
+}
+
+func printJSON(views []view) error {
+	encoded, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+func printTable(views []view) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tPUBLIC\tIP ADDRESS\tPORT RANGE\tALLOCATED PORTS\tCOMPONENTS\tHEALTHY")
+	for _, v := range views {
+		healthy := "unknown"
+		if v.HealthCheckFailed != "" {
+			healthy = "unreachable"
+		} else if v.Healthy != nil {
+			healthy = fmt.Sprintf("%t", *v.Healthy)
+		}
+		fmt.Fprintf(
+			writer,
+			"%s\t%t\t%s\t%d-%d\t%d\t%d\t%s\n",
+			v.Name,
+			v.Public,
+			v.IPAddress,
+			v.PortRangeLow,
+			v.PortRangeHigh,
+			len(v.AllocatedPorts),
+			len(v.Allocations),
+			healthy,
+		)
+	}
+	writer.Flush()
+}
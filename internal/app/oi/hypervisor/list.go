@@ -0,0 +1,47 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package hypervisor
+
+import (
+	"sort"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// List prints the registered capacity, current allocations and
+// health of every hypervisor, in the requested format. When live is
+// true, a live CRI query is performed against each hypervisor to
+// report its current health
+func List(format string, live bool) error {
+	return manifests.WithStdinResourcesSilent(
+		func(hypervisors infra.HypervisorMap, _ cluster.Map, components component.List) error {
+			names := make([]string, 0, len(hypervisors))
+			for name := range hypervisors {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			views := make([]view, 0, len(names))
+			for _, name := range names {
+				views = append(views, newView(hypervisors[name], components, live))
+			}
+			return printViews(views, format)
+		},
+	)
+}
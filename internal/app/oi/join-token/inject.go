@@ -19,25 +19,45 @@ package jointoken
 import (
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	bootstraptokenutil "k8s.io/cluster-bootstrap/token/util"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
 	"github.com/oneinfra/oneinfra/internal/pkg/component"
 	"github.com/oneinfra/oneinfra/internal/pkg/infra"
 	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
 )
 
-// Inject injects a join token into the provided cluster spec
-func Inject(clusterName string) error {
+// Inject injects a join token into the provided cluster spec. When
+// ttl is non-empty (e.g. "24h") or usageLimit is positive, the token
+// is created with that expiry and/or usage limit, instead of
+// remaining valid indefinitely
+func Inject(clusterName, ttl string, usageLimit int) error {
+	var ttlDuration *metav1.Duration
+	if ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			return errors.Wrap(err, "invalid --ttl value")
+		}
+		ttlDuration = &metav1.Duration{Duration: parsed}
+	}
 	return manifests.WithStdinResources(
 		func(_ infra.HypervisorMap, clusters cluster.Map, components component.List) (component.List, error) {
 			bootstrapToken, err := bootstraptokenutil.GenerateBootstrapToken()
 			if err != nil {
 				return component.List{}, err
 			}
+			joinToken := clusterv1alpha1.JoinToken{
+				Token:      bootstrapToken,
+				TTL:        ttlDuration,
+				UsageLimit: usageLimit,
+			}
 			err = manifests.WithNamedCluster(clusterName, clusters, func(cluster *cluster.Cluster) error {
-				cluster.DesiredJoinTokens = append(cluster.DesiredJoinTokens, bootstrapToken)
+				cluster.DesiredJoinTokens = append(cluster.DesiredJoinTokens, joinToken)
 				return nil
 			})
 			if err != nil {
@@ -19,17 +19,18 @@ package node
 import (
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/oneinfra/oneinfra/internal/pkg/node"
 )
 
 // Join joins a node to an existing cluster
-func Join(nodename, apiServerEndpoint, caCertFile, token, containerRuntimeEndpoint, imageServiceEndpoint string, extraSANs []string) error {
+func Join(nodename, apiServerEndpoint, caCertFile, token, containerRuntimeEndpoint, imageServiceEndpoint string, extraSANs []string, preferredAddressFamily string, maxClockSkew time.Duration) error {
 	caCert, err := ioutil.ReadFile(caCertFile)
 	if err != nil {
 		return err
 	}
-	err = node.Join(nodename, apiServerEndpoint, string(caCert), token, containerRuntimeEndpoint, imageServiceEndpoint, extraSANs)
+	err = node.Join(nodename, apiServerEndpoint, string(caCert), token, containerRuntimeEndpoint, imageServiceEndpoint, extraSANs, preferredAddressFamily, maxClockSkew)
 	if err == nil {
 		fmt.Printf("worker node %q was successfully configured\n", nodename)
 	}
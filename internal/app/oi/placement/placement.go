@@ -0,0 +1,110 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package placement implements the `oi placement` commands: tools
+// that reason about the component placement scheduler without
+// mutating any live resource
+package placement
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+)
+
+// simulatedPlacement reports where the scheduler would place a
+// single currently unscheduled component, as simulated by Simulate
+type simulatedPlacement struct {
+	ClusterNamespace string `json:"clusterNamespace"`
+	Cluster          string `json:"cluster"`
+	Component        string `json:"component"`
+	Role             string `json:"role"`
+	Hypervisor       string `json:"hypervisor,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// simulatedHypervisorCapacity reports an hypervisor's component load
+// and whether it still has room for more, after a simulated
+// placement run
+type simulatedHypervisorCapacity struct {
+	Hypervisor string `json:"hypervisor"`
+	Load       int    `json:"load"`
+	HasRoom    bool   `json:"hasRoom"`
+}
+
+// simulationReport is the result of a placement simulation run, as
+// printed by `oi placement simulate`
+type simulationReport struct {
+	Placements []simulatedPlacement          `json:"placements"`
+	Capacity   []simulatedHypervisorCapacity `json:"capacity"`
+}
+
+// printSimulationReport prints report in the requested format (table
+// or json)
+func printSimulationReport(report simulationReport, format string) error {
+	switch format {
+	case "table":
+		printSimulationTable(report)
+		return nil
+	case "json":
+		return printSimulationJSON(report)
+	default:
+		return errors.Errorf("unknown format %q, expected one of: table, json", format)
+	}
+}
+
+func printSimulationJSON(report simulationReport) error {
+	encoded, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+func printSimulationTable(report simulationReport) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "CLUSTER NAMESPACE\tCLUSTER\tCOMPONENT\tROLE\tHYPERVISOR\tERROR")
+	for _, placement := range report.Placements {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\t%s\n",
+			placement.ClusterNamespace,
+			placement.Cluster,
+			placement.Component,
+			placement.Role,
+			placement.Hypervisor,
+			placement.Error,
+		)
+	}
+	writer.Flush()
+	fmt.Println()
+	writer = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "HYPERVISOR\tLOAD\tHAS ROOM")
+	for _, capacity := range report.Capacity {
+		fmt.Fprintf(
+			writer,
+			"%s\t%d\t%t\n",
+			capacity.Hypervisor,
+			capacity.Load,
+			capacity.HasRoom,
+		)
+	}
+	writer.Flush()
+}
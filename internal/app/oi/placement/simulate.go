@@ -0,0 +1,105 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package placement
+
+import (
+	"sort"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	componentapi "github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// Simulate runs the same selection logic as the component scheduler
+// against the hypervisors, clusters and components piped on stdin,
+// without updating anything, and reports where every currently
+// unscheduled component would land and how much capacity would
+// remain on each hypervisor afterwards. This is meant for capacity
+// planning: pipe in the hypervisors already registered in the fleet
+// together with the clusters being planned, and see where they would
+// be placed before committing them
+func Simulate(format string) error {
+	return manifests.WithStdinResourcesSilent(
+		func(hypervisors infra.HypervisorMap, _ cluster.Map, components component.List) error {
+			schedulableHypervisors := hypervisors.WithPassingPreflight().WithoutCordoned()
+			report := simulationReport{
+				Placements: []simulatedPlacement{},
+				Capacity:   []simulatedHypervisorCapacity{},
+			}
+			for _, comp := range components {
+				if comp.HypervisorName != "" {
+					continue
+				}
+				report.Placements = append(
+					report.Placements,
+					simulatePlacement(schedulableHypervisors, comp),
+				)
+			}
+			names := make([]string, 0, len(hypervisors))
+			for name := range hypervisors {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				hypervisor := hypervisors[name]
+				report.Capacity = append(report.Capacity, simulatedHypervisorCapacity{
+					Hypervisor: hypervisor.Name,
+					Load:       hypervisor.Load(),
+					HasRoom:    hypervisor.HasRoom(),
+				})
+			}
+			return printSimulationReport(report, format)
+		},
+	)
+}
+
+// simulatePlacement selects an hypervisor for comp out of
+// schedulableHypervisors the same way the component scheduler would,
+// and reserves a port on it so later components considered in the
+// same simulation run see an accurate, increased load. The scheduler
+// itself does not take an hypervisor pool into account here, since
+// stdin manifests carry no hypervisor pool resource; a component
+// bound to a pool is simulated as if it could land on any
+// schedulable hypervisor of the matching visibility
+func simulatePlacement(schedulableHypervisors infra.HypervisorMap, comp *component.Component) simulatedPlacement {
+	placement := simulatedPlacement{
+		ClusterNamespace: comp.Namespace,
+		Cluster:          comp.ClusterName,
+		Component:        comp.Name,
+		Role:             string(comp.Role),
+	}
+	var hypervisorList infra.HypervisorList
+	switch comp.Role {
+	case componentapi.ControlPlaneRole:
+		hypervisorList = schedulableHypervisors.PrivateList()
+	case componentapi.ControlPlaneIngressRole:
+		hypervisorList = schedulableHypervisors.PublicList()
+	}
+	scheduledHypervisor, err := hypervisorList.Select(infra.SpreadSchedulingStrategy, nil)
+	if err != nil {
+		placement.Error = err.Error()
+		return placement
+	}
+	if _, err := scheduledHypervisor.RequestPort(comp.Namespace, comp.ClusterName, comp.Name); err != nil {
+		placement.Error = err.Error()
+		return placement
+	}
+	placement.Hypervisor = scheduledHypervisor.Name
+	return placement
+}
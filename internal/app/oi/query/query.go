@@ -0,0 +1,153 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package query implements the read-only `oi query` command, which
+// answers fleet inventory questions (which clusters are on a given
+// Kubernetes version, which clusters have components scheduled on a
+// given hypervisor) over the cluster, hypervisor and component
+// manifests piped in on stdin
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
+	"github.com/oneinfra/oneinfra/internal/pkg/manifests"
+)
+
+// view is the read-only representation of a cluster matching the
+// requested filters, as printed by the `oi query` command
+type view struct {
+	Name              string   `json:"name"`
+	Namespace         string   `json:"namespace"`
+	KubernetesVersion string   `json:"kubernetesVersion"`
+	HypervisorPool    string   `json:"hypervisorPool,omitempty"`
+	Hypervisors       []string `json:"hypervisors"`
+}
+
+// Query prints every cluster taken from the manifests on stdin that
+// matches all of the given filters, in the requested format. An
+// empty filter value matches every cluster. kubernetesVersion is
+// matched against each cluster's effective Kubernetes version
+// (honouring a paused upgrade strategy, see
+// cluster.EffectiveKubernetesVersion), and hypervisorName is matched
+// against the hypervisors any of the cluster's components are
+// currently scheduled on
+func Query(kubernetesVersion, hypervisorName, format string) error {
+	return manifests.WithStdinResourcesSilent(
+		func(_ infra.HypervisorMap, clusters cluster.Map, components component.List) error {
+			names := make([]string, 0, len(clusters))
+			for name := range clusters {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			views := make([]view, 0, len(names))
+			for _, name := range names {
+				matchedCluster := clusters[name]
+				if kubernetesVersion != "" && matchedCluster.EffectiveKubernetesVersion() != kubernetesVersion {
+					continue
+				}
+				hypervisors := clusterHypervisors(matchedCluster.Name, components)
+				if hypervisorName != "" && !containsString(hypervisors, hypervisorName) {
+					continue
+				}
+				views = append(views, view{
+					Name:              matchedCluster.Name,
+					Namespace:         matchedCluster.Namespace,
+					KubernetesVersion: matchedCluster.EffectiveKubernetesVersion(),
+					HypervisorPool:    matchedCluster.HypervisorPool,
+					Hypervisors:       hypervisors,
+				})
+			}
+			return printViews(views, format)
+		},
+	)
+}
+
+// clusterHypervisors returns the sorted, deduplicated list of
+// hypervisor names any component belonging to clusterName is
+// currently scheduled on
+func clusterHypervisors(clusterName string, components component.List) []string {
+	seen := map[string]bool{}
+	for _, comp := range components {
+		if comp.ClusterName != clusterName || comp.HypervisorName == "" {
+			continue
+		}
+		seen[comp.HypervisorName] = true
+	}
+	hypervisors := make([]string, 0, len(seen))
+	for hypervisorName := range seen {
+		hypervisors = append(hypervisors, hypervisorName)
+	}
+	sort.Strings(hypervisors)
+	return hypervisors
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, candidate := range haystack {
+		if candidate == needle {
+			return true
+		}
+	}
+	return false
+}
+
+// printViews prints views in the requested format (table or json)
+func printViews(views []view, format string) error {
+	switch format {
+	case "table":
+		printTable(views)
+		return nil
+	case "json":
+		return printJSON(views)
+	default:
+		return errors.Errorf("unknown format %q, expected one of: table, json", format)
+	}
+}
+
+func printJSON(views []view) error {
+	encoded, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+func printTable(views []view) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAMESPACE\tNAME\tKUBERNETES VERSION\tHYPERVISOR POOL\tHYPERVISORS")
+	for _, v := range views {
+		fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%s\t%s\n",
+			v.Namespace,
+			v.Name,
+			v.KubernetesVersion,
+			v.HypervisorPool,
+			fmt.Sprintf("%v", v.Hypervisors),
+		)
+	}
+	writer.Flush()
+}
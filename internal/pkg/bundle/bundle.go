@@ -0,0 +1,189 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package bundle builds and pushes offline bundles, archives
+// containing every container image oneinfra needs to bring up a
+// cluster at a given Kubernetes version as OCI archives, so a fully
+// air-gapped install can transfer them once and load them onto its
+// hypervisors without reaching out to any registry
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
+	"github.com/oneinfra/oneinfra/internal/pkg/component/components"
+	"github.com/oneinfra/oneinfra/internal/pkg/node"
+)
+
+const manifestFileName = "manifest.json"
+
+// Manifest describes the contents of an offline bundle: the
+// Kubernetes version it was built for, and the full list of images
+// it packages
+type Manifest struct {
+	KubernetesVersion string   `json:"kubernetesVersion"`
+	Images            []string `json:"images"`
+}
+
+// RequiredImages returns every container image oneinfra needs to
+// bring up a cluster at kubernetesVersion: the control plane images,
+// the CoreDNS and kube-proxy addon images, and the kubelet-installer
+// image used to install the kubelet binary on joining nodes
+func RequiredImages(kubernetesVersion string) ([]string, error) {
+	images, err := components.RequiredImages(kubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+	addonImages, err := cluster.AddonImages(kubernetesVersion)
+	if err != nil {
+		return nil, err
+	}
+	images = append(images, addonImages...)
+	images = append(images, node.KubeletInstallerImage(kubernetesVersion))
+	return images, nil
+}
+
+// Build downloads every image RequiredImages returns for
+// kubernetesVersion as an OCI archive, and packages them together
+// with a manifest describing the bundle into a single gzipped tar
+// archive at outputPath, suitable for transferring into an
+// air-gapped environment and later loading onto hypervisors with
+// Push
+func Build(kubernetesVersion, outputPath string) error {
+	images, err := RequiredImages(kubernetesVersion)
+	if err != nil {
+		return err
+	}
+	workDir, err := ioutil.TempDir("", "oneinfra-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+	imagesDir := filepath.Join(workDir, "images")
+	if err := os.MkdirAll(imagesDir, 0755); err != nil {
+		return err
+	}
+	for _, image := range images {
+		klog.Infof("pulling image %q into the bundle", image)
+		if err := pullImage(image, imageArchivePath(imagesDir, image)); err != nil {
+			return errors.Wrapf(err, "could not pull image %q", image)
+		}
+	}
+	manifestContents, err := json.MarshalIndent(
+		Manifest{KubernetesVersion: kubernetesVersion, Images: images},
+		"", "  ",
+	)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(workDir, manifestFileName), manifestContents, 0644); err != nil {
+		return err
+	}
+	return archiveDirectory(workDir, outputPath)
+}
+
+// Push loads every image packaged in the bundle archive at
+// bundlePath onto the hypervisor whose CRI image store is reachable
+// through containerdAddress
+func Push(bundlePath, containerdAddress string) error {
+	workDir, err := ioutil.TempDir("", "oneinfra-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+	if err := extractArchive(bundlePath, workDir); err != nil {
+		return err
+	}
+	manifest, err := readManifest(workDir)
+	if err != nil {
+		return err
+	}
+	imagesDir := filepath.Join(workDir, "images")
+	for _, image := range manifest.Images {
+		klog.Infof("loading image %q onto the hypervisor", image)
+		if err := loadImage(imageArchivePath(imagesDir, image), containerdAddress); err != nil {
+			return errors.Wrapf(err, "could not load image %q", image)
+		}
+	}
+	return nil
+}
+
+func readManifest(workDir string) (*Manifest, error) {
+	manifestContents, err := ioutil.ReadFile(filepath.Join(workDir, manifestFileName))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read bundle manifest")
+	}
+	manifest := Manifest{}
+	if err := json.Unmarshal(manifestContents, &manifest); err != nil {
+		return nil, errors.Wrap(err, "could not parse bundle manifest")
+	}
+	return &manifest, nil
+}
+
+// imageArchivePath returns the path an image is stored at within an
+// offline bundle's working directory, named after the image
+// reference itself so Build and Push agree on where to find it
+func imageArchivePath(imagesDir, image string) string {
+	sanitizedImage := strings.NewReplacer("/", "_", ":", "_").Replace(image)
+	return filepath.Join(imagesDir, fmt.Sprintf("%s.tar", sanitizedImage))
+}
+
+// pullImage downloads image from its registry into an OCI archive at
+// destPath, through skopeo, since oneinfra does not vendor an OCI
+// client library
+func pullImage(image, destPath string) error {
+	return runCommand(exec.Command(
+		"skopeo", "copy",
+		fmt.Sprintf("docker://%s", image),
+		fmt.Sprintf("oci-archive:%s", destPath),
+	))
+}
+
+// loadImage imports the OCI archive at archivePath into the image
+// store of the containerd instance listening at containerdAddress,
+// through ctr, containerd's bundled client. The CRI image service
+// API oneinfra otherwise talks to has no RPC to import an already
+// downloaded image archive
+func loadImage(archivePath, containerdAddress string) error {
+	return runCommand(exec.Command(
+		"ctr", "--address", containerdAddress, "-n", "k8s.io",
+		"images", "import", archivePath,
+	))
+}
+
+func archiveDirectory(workDir, outputPath string) error {
+	return runCommand(exec.Command("tar", "-czf", outputPath, "-C", workDir, "."))
+}
+
+func extractArchive(archivePath, destDir string) error {
+	return runCommand(exec.Command("tar", "-xzf", archivePath, "-C", destDir))
+}
+
+func runCommand(cmd *exec.Cmd) error {
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -31,21 +31,26 @@ import (
 	"k8s.io/klog/v2"
 
 	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
 	"github.com/oneinfra/oneinfra/internal/pkg/crypto"
-	"github.com/oneinfra/oneinfra/pkg/constants"
+	constantsapi "github.com/oneinfra/oneinfra/pkg/constants"
 )
 
 // Certificate represents a certificate
 type Certificate struct {
 	Certificate string
 	PrivateKey  string
+	Provenance  *commonv1alpha1.Provenance
 	certificate *x509.Certificate
 	privateKey  *rsa.PrivateKey
 }
 
-// NewCertificateAuthority creates a new certificate authority
+// NewCertificateAuthority creates a new certificate authority. Its
+// NotBefore is backdated by DefaultCertificateClockSkewTolerance, so
+// it is already valid on a host whose clock lags slightly behind the
+// one that generated it
 func NewCertificateAuthority(authorityName string) (*Certificate, error) {
-	privateKey, err := crypto.NewPrivateKey(constants.DefaultCAKeyBitSize)
+	privateKey, err := crypto.NewPrivateKey(constantsapi.DefaultCAKeyBitSize)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +69,7 @@ func NewCertificateAuthority(authorityName string) (*Certificate, error) {
 			StreetAddress: []string{"Some StreetAddress"},
 			PostalCode:    []string{"Some PostalCode"},
 		},
-		NotBefore:             time.Now(),
+		NotBefore:             time.Now().Add(-constantsapi.DefaultCertificateClockSkewTolerance),
 		NotAfter:              time.Now().AddDate(10, 0, 0),
 		IsCA:                  true,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
@@ -86,6 +91,7 @@ func NewCertificateAuthority(authorityName string) (*Certificate, error) {
 	return &Certificate{
 		Certificate: caPEM.String(),
 		PrivateKey:  privateKey.PrivateKey,
+		Provenance:  commonv1alpha1.NewProvenance(constants.BuildVersion),
 		certificate: &caCertificate,
 		privateKey:  privateKey.Key(),
 	}, nil
@@ -99,6 +105,7 @@ func NewCertificateFromv1alpha1(certificate *commonv1alpha1.Certificate) *Certif
 	res := &Certificate{
 		Certificate: certificate.Certificate,
 		PrivateKey:  certificate.PrivateKey,
+		Provenance:  certificate.Provenance,
 	}
 	if err := res.init(); err != nil {
 		klog.Warningf("error when decoding certificate authority: %v", err)
@@ -111,9 +118,14 @@ func (certificate *Certificate) Export() *commonv1alpha1.Certificate {
 	if certificate == nil {
 		return nil
 	}
+	provenance := certificate.Provenance
+	if provenance == nil {
+		provenance = commonv1alpha1.NewProvenance(constants.BuildVersion)
+	}
 	return &commonv1alpha1.Certificate{
 		Certificate: certificate.Certificate,
 		PrivateKey:  certificate.PrivateKey,
+		Provenance:  provenance,
 	}
 }
 
@@ -144,7 +156,64 @@ func (certificate *Certificate) init() error {
 	return nil
 }
 
-// CreateCertificate generates a new certificate and key signed with the current CA
+// HasSANs returns whether this certificate already covers all the
+// provided SANs, so callers can tell a cached certificate from one
+// that needs to be rolled to include newly requested SANs
+func (certificate *Certificate) HasSANs(extraSANs []string) bool {
+	if err := certificate.init(); err != nil {
+		return false
+	}
+	knownSans := map[string]struct{}{}
+	for _, dnsName := range certificate.certificate.DNSNames {
+		knownSans[dnsName] = struct{}{}
+	}
+	for _, ip := range certificate.certificate.IPAddresses {
+		knownSans[ip.String()] = struct{}{}
+	}
+	for _, extraSAN := range extraSANs {
+		if _, exists := knownSans[extraSAN]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpiresWithin reports whether this certificate's expiration falls
+// within threshold from now, so callers can rotate it ahead of
+// expiry instead of waiting for it to actually become invalid. A
+// certificate that cannot be decoded is reported as expiring, so
+// callers rotate it rather than keep serving something unusable
+func (certificate *Certificate) ExpiresWithin(threshold time.Duration) bool {
+	if err := certificate.init(); err != nil {
+		return true
+	}
+	return time.Now().Add(threshold).After(certificate.certificate.NotAfter)
+}
+
+// NotAfter returns when this certificate expires
+func (certificate *Certificate) NotAfter() (time.Time, error) {
+	if err := certificate.init(); err != nil {
+		return time.Time{}, err
+	}
+	return certificate.certificate.NotAfter, nil
+}
+
+// KeyBitSize returns this certificate's private key size in bits, so
+// callers can flag certificates signed with a weaker key than the
+// fleet's policy allows. It returns 0 when this certificate does not
+// carry a private key (e.g. a peer certificate received over the
+// wire) or when it cannot be decoded
+func (certificate *Certificate) KeyBitSize() int {
+	if err := certificate.init(); err != nil || certificate.privateKey == nil {
+		return 0
+	}
+	return certificate.privateKey.N.BitLen()
+}
+
+// CreateCertificate generates a new certificate and key signed with
+// the current CA. Its NotBefore is backdated by
+// DefaultCertificateClockSkewTolerance, so it is already valid on a
+// host whose clock lags slightly behind the one that generated it
 func (certificate *Certificate) CreateCertificate(commonName string, organization []string, extraSANs []string) (string, string, error) {
 	klog.V(2).Infof("creating certificate with common name %q", commonName)
 	serialNumber, err := rand.Int(rand.Reader, (&big.Int{}).Exp(big.NewInt(2), big.NewInt(159), nil))
@@ -169,7 +238,7 @@ func (certificate *Certificate) CreateCertificate(commonName string, organizatio
 		}
 		knownSans[extraSAN] = struct{}{}
 	}
-	privateKey, err := rsa.GenerateKey(rand.Reader, constants.DefaultKeyBitSize)
+	privateKey, err := crypto.NewPrivateKey(constantsapi.DefaultKeyBitSize)
 	if err != nil {
 		return "", "", err
 	}
@@ -186,13 +255,13 @@ func (certificate *Certificate) CreateCertificate(commonName string, organizatio
 		},
 		DNSNames:     sansHosts,
 		IPAddresses:  sansIps,
-		NotBefore:    time.Now(),
+		NotBefore:    time.Now().Add(-constantsapi.DefaultCertificateClockSkewTolerance),
 		NotAfter:     time.Now().AddDate(1, 0, 0),
 		SubjectKeyId: []byte{1, 2, 3, 4, 6},
 		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
 		KeyUsage:     x509.KeyUsageDigitalSignature,
 	}
-	certificateBytes, err := x509.CreateCertificate(rand.Reader, &newCertificate, certificate.certificate, &privateKey.PublicKey, certificate.privateKey)
+	certificateBytes, err := x509.CreateCertificate(rand.Reader, &newCertificate, certificate.certificate, &privateKey.Key().PublicKey, certificate.privateKey)
 	if err != nil {
 		return "", "", err
 	}
@@ -204,13 +273,5 @@ func (certificate *Certificate) CreateCertificate(commonName string, organizatio
 	if err != nil {
 		return "", "", err
 	}
-	certificatePrivKeyPEM := new(bytes.Buffer)
-	err = pem.Encode(certificatePrivKeyPEM, &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
-	})
-	if err != nil {
-		return "", "", err
-	}
-	return certificatePEM.String(), certificatePrivKeyPEM.String(), nil
+	return certificatePEM.String(), privateKey.PrivateKey, nil
 }
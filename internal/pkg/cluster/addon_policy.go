@@ -0,0 +1,120 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+	releasecomponents "github.com/oneinfra/oneinfra/internal/pkg/release-components"
+)
+
+// addonImage validates image, the image reference that would be
+// used for the addonName addon (e.g. "coredns", "kube-proxy"),
+// against this cluster's AddonImagePolicy, returning the image
+// reference to actually apply. A configured required digest is
+// appended to the returned reference; an image outside the
+// configured allowed registries is rejected with an error, instead
+// of being applied anyway. A nil policy allows any image
+func (cluster *Cluster) addonImage(addonName, image string) (string, error) {
+	if cluster.AddonImagePolicy == nil {
+		return image, nil
+	}
+	if allowedRegistries := cluster.AddonImagePolicy.AllowedRegistries; len(allowedRegistries) > 0 {
+		registry := imageRegistry(image)
+		allowed := false
+		for _, allowedRegistry := range allowedRegistries {
+			if registry == allowedRegistry {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "", errors.Errorf("image %q for addon %q is not in an allowed registry", image, addonName)
+		}
+	}
+	if digest, hasDigest := cluster.AddonImagePolicy.RequiredDigests[addonName]; hasDigest {
+		return imageWithDigest(image, digest), nil
+	}
+	return image, nil
+}
+
+// addonUpgradeAllowed reports whether addonName (e.g. "coredns",
+// "kube-proxy") may be upgraded in place to track the image version
+// pinned for this cluster's Kubernetes version, as opposed to being
+// left alone at whatever version it was last reconciled with. A nil
+// policy, or one that does not list addonName in FrozenAddons,
+// allows upgrades
+func (cluster *Cluster) addonUpgradeAllowed(addonName string) bool {
+	if cluster.AddonImagePolicy == nil {
+		return true
+	}
+	for _, frozenAddon := range cluster.AddonImagePolicy.FrozenAddons {
+		if frozenAddon == addonName {
+			return false
+		}
+	}
+	return true
+}
+
+// AddonImages returns the default addon images (CoreDNS, kube-proxy)
+// pinned for kubernetesVersion, with no per-cluster registry
+// mirroring or digest pinning applied, for callers that need the
+// full addon image set for a Kubernetes version and are not
+// reconciling against one particular cluster (e.g. the offline
+// bundle builder)
+func AddonImages(kubernetesVersion string) ([]string, error) {
+	coreDNSVersion, err := constants.KubernetesComponentVersion(kubernetesVersion, releasecomponents.CoreDNS)
+	if err != nil {
+		return nil, err
+	}
+	kubeProxyVersion, err := constants.KubernetesComponentVersion(kubernetesVersion, releasecomponents.KubeProxy)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		fmt.Sprintf(coreDNSImage, coreDNSVersion),
+		fmt.Sprintf(kubeProxyImage, kubeProxyVersion),
+	}, nil
+}
+
+// imageRegistry returns the registry hostname an image reference
+// belongs to, defaulting to "docker.io" for references with no
+// explicit registry hostname
+func imageRegistry(image string) string {
+	slashIndex := strings.Index(image, "/")
+	if slashIndex == -1 {
+		return "docker.io"
+	}
+	firstSegment := image[:slashIndex]
+	if strings.ContainsAny(firstSegment, ".:") || firstSegment == "localhost" {
+		return firstSegment
+	}
+	return "docker.io"
+}
+
+// imageWithDigest replaces image's tag, if any, with a pin to digest
+func imageWithDigest(image, digest string) string {
+	repository := image
+	if colonIndex := strings.LastIndex(image, ":"); colonIndex > strings.LastIndex(image, "/") {
+		repository = image[:colonIndex]
+	}
+	return repository + "@" + digest
+}
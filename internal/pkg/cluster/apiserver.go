@@ -25,9 +25,57 @@ import (
 
 // KubeAPIServer represents the kube-apiserver component
 type KubeAPIServer struct {
-	CA             *certificates.Certificate
-	ServiceAccount *crypto.KeyPair
-	ExtraSANs      []string
+	CA                 *certificates.Certificate
+	ServiceAccount     *crypto.KeyPair
+	ExtraSANs          []string
+	AnonymousAuth      bool
+	EnableProfiling    bool
+	EnableInsecurePort bool
+	AuditLog           *AuditLog
+	EventTTL           string
+}
+
+// AuditLog configures audit event logging for a cluster's API server
+type AuditLog struct {
+	Policy  string
+	Webhook *AuditWebhookSink
+	Path    string
+}
+
+// AuditWebhookSink represents an external HTTPS sink audit events
+// are shipped to
+type AuditWebhookSink struct {
+	URL string
+}
+
+func newAuditLogFromv1alpha1(auditLog *clusterv1alpha1.AuditLog) *AuditLog {
+	if auditLog == nil {
+		return nil
+	}
+	res := &AuditLog{
+		Policy: auditLog.Policy,
+		Path:   auditLog.Path,
+	}
+	if auditLog.Webhook != nil {
+		res.Webhook = &AuditWebhookSink{URL: auditLog.Webhook.URL}
+	}
+	return res
+}
+
+// Export exports this audit log configuration to a versioned audit
+// log configuration
+func (auditLog *AuditLog) Export() *clusterv1alpha1.AuditLog {
+	if auditLog == nil {
+		return nil
+	}
+	res := &clusterv1alpha1.AuditLog{
+		Policy: auditLog.Policy,
+		Path:   auditLog.Path,
+	}
+	if auditLog.Webhook != nil {
+		res.Webhook = &clusterv1alpha1.AuditWebhookSink{URL: auditLog.Webhook.URL}
+	}
+	return res
 }
 
 func newKubeAPIServer(apiServerExtraSANs []string) (*KubeAPIServer, error) {
@@ -55,9 +103,14 @@ func newKubeAPIServerFromv1alpha1(kubeAPIServer *clusterv1alpha1.KubeAPIServer)
 		return nil, err
 	}
 	return &KubeAPIServer{
-		CA:             certificates.NewCertificateFromv1alpha1(kubeAPIServer.CA),
-		ServiceAccount: apiServerServiceAccountKey,
-		ExtraSANs:      kubeAPIServer.ExtraSANs,
+		CA:                 certificates.NewCertificateFromv1alpha1(kubeAPIServer.CA),
+		ServiceAccount:     apiServerServiceAccountKey,
+		ExtraSANs:          kubeAPIServer.ExtraSANs,
+		AnonymousAuth:      kubeAPIServer.AnonymousAuth,
+		EnableProfiling:    kubeAPIServer.EnableProfiling,
+		EnableInsecurePort: kubeAPIServer.EnableInsecurePort,
+		AuditLog:           newAuditLogFromv1alpha1(kubeAPIServer.AuditLog),
+		EventTTL:           kubeAPIServer.EventTTL,
 	}, nil
 }
 
@@ -67,8 +120,13 @@ func (kubeAPIServer *KubeAPIServer) Export() *clusterv1alpha1.KubeAPIServer {
 		return nil
 	}
 	return &clusterv1alpha1.KubeAPIServer{
-		CA:             kubeAPIServer.CA.Export(),
-		ServiceAccount: kubeAPIServer.ServiceAccount.Export(),
-		ExtraSANs:      kubeAPIServer.ExtraSANs,
+		CA:                 kubeAPIServer.CA.Export(),
+		ServiceAccount:     kubeAPIServer.ServiceAccount.Export(),
+		ExtraSANs:          kubeAPIServer.ExtraSANs,
+		AnonymousAuth:      kubeAPIServer.AnonymousAuth,
+		EnableProfiling:    kubeAPIServer.EnableProfiling,
+		EnableInsecurePort: kubeAPIServer.EnableInsecurePort,
+		AuditLog:           kubeAPIServer.AuditLog.Export(),
+		EventTTL:           kubeAPIServer.EventTTL,
 	}
 }
@@ -0,0 +1,70 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultAuditPolicyLevel is the audit level applied when a cluster
+// enables audit logging without specifying a policy level explicitly
+const defaultAuditPolicyLevel = "Metadata"
+
+// auditPolicy mirrors the subset of the audit.k8s.io/v1 Policy
+// schema that oneinfra renders
+type auditPolicy struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Rules      []auditPolicyRule `json:"rules"`
+}
+
+type auditPolicyRule struct {
+	Level string `json:"level"`
+}
+
+// AuditPolicyYAML renders this cluster's audit policy file, applying
+// every request at the level configured in APIServer.AuditLog.Policy
+func (cluster *Cluster) AuditPolicyYAML() (string, error) {
+	level := defaultAuditPolicyLevel
+	if cluster.APIServer.AuditLog != nil && cluster.APIServer.AuditLog.Policy != "" {
+		level = cluster.APIServer.AuditLog.Policy
+	}
+	policy := auditPolicy{
+		APIVersion: "audit.k8s.io/v1",
+		Kind:       "Policy",
+		Rules: []auditPolicyRule{
+			{Level: level},
+		},
+	}
+	encoded, err := yaml.Marshal(policy)
+	if err != nil {
+		return "", errors.Wrap(err, "could not marshal audit policy")
+	}
+	return string(encoded), nil
+}
+
+// AuditWebhookConfigYAML renders the kubeconfig-style file the API
+// server's audit webhook backend uses to locate the external sink
+// audit events are shipped to
+func (cluster *Cluster) AuditWebhookConfigYAML() (string, error) {
+	if cluster.APIServer.AuditLog == nil || cluster.APIServer.AuditLog.Webhook == nil {
+		return "", errors.New("cluster has no audit webhook sink configured")
+	}
+	kubeConfig := kubeConfigCommon(cluster.Name, cluster.APIServer.AuditLog.Webhook.URL, "")
+	return marshalKubeConfig(cluster.Name, kubeConfig)
+}
@@ -0,0 +1,154 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"github.com/pkg/errors"
+	versionapi "k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+// structuredAuthenticationConfigurationVersion is the first
+// Kubernetes version where the kube-apiserver accepts a structured
+// authentication configuration file through --authentication-config.
+// Older versions fall back to the legacy oidc-* flags.
+const structuredAuthenticationConfigurationVersion = "1.30.0"
+
+// authenticationConfiguration mirrors the subset of the
+// apiserver.config.k8s.io/v1beta1 AuthenticationConfiguration schema
+// that oneinfra renders
+type authenticationConfiguration struct {
+	APIVersion string                    `json:"apiVersion"`
+	Kind       string                    `json:"kind"`
+	JWT        []authenticationConfigJWT `json:"jwt,omitempty"`
+}
+
+type authenticationConfigJWT struct {
+	Issuer        authenticationConfigIssuer        `json:"issuer"`
+	ClaimMappings authenticationConfigClaimMappings `json:"claimMappings,omitempty"`
+}
+
+type authenticationConfigIssuer struct {
+	URL       string   `json:"url"`
+	Audiences []string `json:"audiences"`
+}
+
+type authenticationConfigClaimMappings struct {
+	Username authenticationConfigClaimOrPrefix `json:"username,omitempty"`
+	Groups   authenticationConfigClaimOrPrefix `json:"groups,omitempty"`
+}
+
+type authenticationConfigClaimOrPrefix struct {
+	Claim  string `json:"claim,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// UsesStructuredAuthenticationConfiguration returns whether this
+// cluster's Kubernetes version renders JWT authentication through the
+// structured AuthenticationConfiguration file, as opposed to the
+// legacy oidc-* flags
+func (cluster *Cluster) UsesStructuredAuthenticationConfiguration() (bool, error) {
+	version, err := versionapi.ParseSemantic(cluster.KubernetesVersion)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not parse version %q", cluster.KubernetesVersion)
+	}
+	versionCompare, err := version.Compare(structuredAuthenticationConfigurationVersion)
+	if err != nil {
+		return false, err
+	}
+	return versionCompare >= 0, nil
+}
+
+// insecurePortFlagRemovedVersion is the first Kubernetes version
+// where the kube-apiserver no longer accepts --insecure-port at all.
+// Older versions still accept it, defaulting to disabled.
+const insecurePortFlagRemovedVersion = "1.24.0"
+
+// SupportsInsecurePortFlag returns whether this cluster's Kubernetes
+// version still accepts the deprecated --insecure-port flag
+func (cluster *Cluster) SupportsInsecurePortFlag() (bool, error) {
+	version, err := versionapi.ParseSemantic(cluster.KubernetesVersion)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not parse version %q", cluster.KubernetesVersion)
+	}
+	versionCompare, err := version.Compare(insecurePortFlagRemovedVersion)
+	if err != nil {
+		return false, err
+	}
+	return versionCompare < 0, nil
+}
+
+// AuthenticationConfigurationYAML renders this cluster's JWT
+// authenticators as a structured AuthenticationConfiguration file
+func (cluster *Cluster) AuthenticationConfigurationYAML() (string, error) {
+	authenticationConfig := authenticationConfiguration{
+		APIVersion: "apiserver.config.k8s.io/v1beta1",
+		Kind:       "AuthenticationConfiguration",
+	}
+	for _, jwtAuthenticator := range cluster.Authentication.JWT {
+		authenticationConfig.JWT = append(authenticationConfig.JWT, authenticationConfigJWT{
+			Issuer: authenticationConfigIssuer{
+				URL:       jwtAuthenticator.Issuer.URL,
+				Audiences: jwtAuthenticator.Issuer.Audiences,
+			},
+			ClaimMappings: authenticationConfigClaimMappings{
+				Username: authenticationConfigClaimOrPrefix{
+					Claim:  jwtAuthenticator.ClaimMappings.Username.Claim,
+					Prefix: jwtAuthenticator.ClaimMappings.Username.Prefix,
+				},
+				Groups: authenticationConfigClaimOrPrefix{
+					Claim:  jwtAuthenticator.ClaimMappings.Groups.Claim,
+					Prefix: jwtAuthenticator.ClaimMappings.Groups.Prefix,
+				},
+			},
+		})
+	}
+	encoded, err := yaml.Marshal(authenticationConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "could not marshal authentication configuration")
+	}
+	return string(encoded), nil
+}
+
+// LegacyOIDCFlags renders this cluster's first JWT authenticator as
+// the legacy oidc-* kube-apiserver flags, since those only support a
+// single issuer
+func (cluster *Cluster) LegacyOIDCFlags() map[string]string {
+	if cluster.Authentication == nil || len(cluster.Authentication.JWT) == 0 {
+		return map[string]string{}
+	}
+	jwtAuthenticator := cluster.Authentication.JWT[0]
+	flags := map[string]string{
+		"oidc-issuer-url": jwtAuthenticator.Issuer.URL,
+	}
+	if len(jwtAuthenticator.Issuer.Audiences) > 0 {
+		flags["oidc-client-id"] = jwtAuthenticator.Issuer.Audiences[0]
+	}
+	if jwtAuthenticator.ClaimMappings.Username.Claim != "" {
+		flags["oidc-username-claim"] = jwtAuthenticator.ClaimMappings.Username.Claim
+	}
+	if jwtAuthenticator.ClaimMappings.Username.Prefix != "" {
+		flags["oidc-username-prefix"] = jwtAuthenticator.ClaimMappings.Username.Prefix
+	}
+	if jwtAuthenticator.ClaimMappings.Groups.Claim != "" {
+		flags["oidc-groups-claim"] = jwtAuthenticator.ClaimMappings.Groups.Claim
+	}
+	if jwtAuthenticator.ClaimMappings.Groups.Prefix != "" {
+		flags["oidc-groups-prefix"] = jwtAuthenticator.ClaimMappings.Groups.Prefix
+	}
+	return flags
+}
@@ -0,0 +1,119 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+// ReconcileBaseline seeds this cluster's configured baseline objects
+// (namespaces, PriorityClasses, and a default deny-all
+// NetworkPolicy), maintaining them idempotently on every reconcile.
+// A cluster with no Baseline configured has nothing to seed
+func (cluster *Cluster) ReconcileBaseline() error {
+	if cluster.Baseline == nil {
+		return nil
+	}
+	client, err := cluster.KubernetesClient()
+	if err != nil {
+		return err
+	}
+	for _, namespace := range cluster.Baseline.Namespaces {
+		if err := cluster.reconcileBaselineNamespace(client, namespace); err != nil {
+			return err
+		}
+	}
+	for _, priorityClass := range cluster.Baseline.PriorityClasses {
+		if err := cluster.reconcileBaselinePriorityClass(client, priorityClass); err != nil {
+			return err
+		}
+	}
+	if cluster.Baseline.DefaultDenyNetworkPolicyEnabled {
+		for _, namespace := range cluster.Baseline.Namespaces {
+			if err := cluster.reconcileBaselineDefaultDenyNetworkPolicy(client, namespace); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (cluster *Cluster) reconcileBaselineNamespace(client clientset.Interface, namespace string) error {
+	_, err := client.CoreV1().Namespaces().Create(
+		context.TODO(),
+		&v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil && apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (cluster *Cluster) reconcileBaselinePriorityClass(client clientset.Interface, priorityClass clusterv1alpha1.PriorityClass) error {
+	_, err := client.SchedulingV1().PriorityClasses().Create(
+		context.TODO(),
+		&schedulingv1.PriorityClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: priorityClass.Name,
+			},
+			Value:         priorityClass.Value,
+			GlobalDefault: priorityClass.GlobalDefault,
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil && apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (cluster *Cluster) reconcileBaselineDefaultDenyNetworkPolicy(client clientset.Interface, namespace string) error {
+	_, err := client.NetworkingV1().NetworkPolicies(namespace).Create(
+		context.TODO(),
+		&networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "default-deny-ingress",
+				Namespace: namespace,
+			},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{},
+				PolicyTypes: []networkingv1.PolicyType{
+					networkingv1.PolicyTypeIngress,
+				},
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil && apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
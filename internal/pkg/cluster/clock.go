@@ -0,0 +1,58 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// RemoteTime returns the current time as reported by the apiserver at
+// apiServerEndpoint, read off the Date header of a request
+// authenticated with kubeConfig. This lets a caller compare its own
+// clock against the management plane's without requiring the
+// apiserver to expose any oneinfra-specific endpoint
+func RemoteTime(kubeConfig, apiServerEndpoint string) (time.Time, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeConfig))
+	if err != nil {
+		return time.Time{}, err
+	}
+	transport, err := rest.TransportFor(restConfig)
+	if err != nil {
+		return time.Time{}, err
+	}
+	httpClient := http.Client{Transport: transport}
+	response, err := httpClient.Get(apiServerEndpoint + "/version")
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "could not reach the apiserver to read its clock")
+	}
+	defer response.Body.Close()
+	dateHeader := response.Header.Get("Date")
+	if dateHeader == "" {
+		return time.Time{}, errors.New("apiserver response did not include a Date header")
+	}
+	remoteTime, err := http.ParseTime(dateHeader)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, "could not parse the apiserver's Date header")
+	}
+	return remoteTime, nil
+}
@@ -17,26 +17,29 @@
 package cluster
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"encoding/json"
 	"fmt"
-	"math/big"
 	"net"
+	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
-	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
 
 	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
 
 	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
 	"github.com/oneinfra/oneinfra/internal/pkg/certificates"
 	"github.com/oneinfra/oneinfra/internal/pkg/conditions"
 	"github.com/oneinfra/oneinfra/internal/pkg/crypto"
+	"github.com/oneinfra/oneinfra/internal/pkg/metrics"
 	"github.com/oneinfra/oneinfra/pkg/constants"
 )
 
@@ -47,41 +50,85 @@ const (
 	// ReconcileSucceeded represents a condition type signaling that a
 	// reconcile has succeeded
 	ReconcileSucceeded conditions.ConditionType = "ReconcileSucceeded"
+	// UpgradeRolledBack represents a condition type signaling that a
+	// Kubernetes version upgrade failed to become ready and was
+	// automatically rolled back
+	UpgradeRolledBack conditions.ConditionType = "UpgradeRolledBack"
+	// CertificatesNeedingRotation represents a condition type signaling
+	// that one or more of this cluster's certificate authorities were
+	// rotated because they were approaching expiry
+	CertificatesNeedingRotation conditions.ConditionType = "CertificatesNeedingRotation"
 )
 
+// DefaultCertificateRotationThreshold is how far ahead of expiry a
+// cluster's certificates are rotated when CertificateRotationThreshold
+// is unset
+const DefaultCertificateRotationThreshold = 30 * 24 * time.Hour
+
 // Cluster represents a cluster
 type Cluster struct {
-	Name                   string
-	Namespace              string
-	ResourceVersion        string
-	Labels                 map[string]string
-	Annotations            map[string]string
-	Finalizers             []string
-	DeletionTimestamp      *metav1.Time
-	KubernetesVersion      string
-	ControlPlaneReplicas   int
-	CertificateAuthorities *CertificateAuthorities
-	EtcdServer             *EtcdServer
-	APIServer              *KubeAPIServer
-	ClientCertificates     map[string]*certificates.Certificate
-	StorageClientEndpoints map[string]string
-	StoragePeerEndpoints   map[string]string
-	VPN                    *VPN
-	VPNPeers               VPNPeerMap
-	APIServerEndpoint      string
-	VPNServerEndpoint      string
-	JoinKey                *crypto.KeyPair
-	DesiredJoinTokens      []string
-	CurrentJoinTokens      []string
-	Conditions             conditions.ConditionList
-	ClusterCIDR            string
-	ServiceCIDR            string
-	NodeCIDRMaskSize       int
-	NodeCIDRMaskSizeIPv4   int
-	NodeCIDRMaskSizeIPv6   int
-	clientSet              clientset.Interface
-	extensionsClientSet    apiextensionsclientset.Interface
-	loadedContentsHash     string
+	Name                         string
+	Namespace                    string
+	ResourceVersion              string
+	Generation                   int64
+	Labels                       map[string]string
+	Annotations                  map[string]string
+	Finalizers                   []string
+	CreationTimestamp            metav1.Time
+	DeletionTimestamp            *metav1.Time
+	KubernetesVersion            string
+	ControlPlaneReplicas         int
+	CertificateAuthorities       *CertificateAuthorities
+	EtcdServer                   *EtcdServer
+	APIServer                    *KubeAPIServer
+	ClientCertificates           map[string]*certificates.Certificate
+	StorageClientEndpoints       map[string]string
+	StoragePeerEndpoints         map[string]string
+	VPN                          *VPN
+	VPNPeers                     VPNPeerMap
+	APIServerEndpoint            string
+	VPNServerEndpoint            string
+	JoinKey                      *crypto.KeyPair
+	JoinKeyCipherSuite           clusterv1alpha1.JoinKeyCipherSuite
+	JoinBoxKey                   *crypto.NaClBoxKeyPair
+	SigningKey                   *crypto.SigningKeyPair
+	DesiredJoinTokens            []clusterv1alpha1.JoinToken
+	CurrentJoinTokens            []clusterv1alpha1.JoinTokenStatus
+	Conditions                   conditions.ConditionList
+	ClusterCIDR                  string
+	ServiceCIDR                  string
+	NodeCIDRMaskSize             int
+	NodeCIDRMaskSizeIPv4         int
+	NodeCIDRMaskSizeIPv6         int
+	Proxy                        *commonv1alpha1.Proxy
+	HealthCheckTimeoutSeconds    int
+	HealthCheckIntervalSeconds   int
+	HypervisorPool               string
+	IngressHypervisorPool        string
+	UpgradeStrategyType          clusterv1alpha1.UpgradeStrategyType
+	UpgradeStrategyMaxSurge      int
+	UpgradeStrategyPaused        bool
+	Authentication               *clusterv1alpha1.Authentication
+	Components                   *clusterv1alpha1.Components
+	Size                         *clusterv1alpha1.ClusterSize
+	SizeOverrides                *clusterv1alpha1.SizeOverrides
+	IngressTuning                *clusterv1alpha1.IngressTuning
+	ImageDigests                 *clusterv1alpha1.ImageDigests
+	ImageSignaturePolicy         *commonv1alpha1.ImageSignaturePolicy
+	AddonImagePolicy             *clusterv1alpha1.AddonImagePolicy
+	ImageRegistry                string
+	Baseline                     *clusterv1alpha1.Baseline
+	Upgrade                      *clusterv1alpha1.ClusterUpgradeStatus
+	History                      ClusterHistoryEntryList
+	ProvisioningTimestamps       map[clusterv1alpha1.ProvisioningPhase]metav1.Time
+	CertificateRotationThreshold *metav1.Duration
+	ExtraEnv                     map[string]string
+	ExtraHostPathMounts          []clusterv1alpha1.HostPathMount
+	Compliance                   *clusterv1alpha1.ComplianceStatus
+	Ingress                      *clusterv1alpha1.IngressStatus
+	clientSet                    clientset.Interface
+	extensionsClientSet          apiextensionsclientset.Interface
+	loadedContentsHash           string
 }
 
 // Map represents a map of clusters
@@ -107,12 +154,12 @@ func NewCluster(clusterName, kubernetesVersion string, controlPlaneReplicas int,
 			return nil, err
 		}
 		res.VPN.CIDR = vpnCIDRNet
-		privateKey, err := wgtypes.GeneratePrivateKey()
+		privateKey, publicKey, err := vpnProviderFor(res.VPN.Backend).NewKeyPair()
 		if err != nil {
 			return nil, err
 		}
-		res.VPN.PrivateKey = privateKey.String()
-		res.VPN.PublicKey = privateKey.PublicKey().String()
+		res.VPN.PrivateKey = privateKey
+		res.VPN.PublicKey = publicKey
 	}
 	if err := res.InitializeCertificatesAndKeys(); err != nil {
 		return nil, err
@@ -127,10 +174,21 @@ func NewCluster(clusterName, kubernetesVersion string, controlPlaneReplicas int,
 
 // NewClusterFromv1alpha1 returns a cluster based on a versioned cluster
 func NewClusterFromv1alpha1(cluster *clusterv1alpha1.Cluster) (*Cluster, error) {
+	if err := decryptSecrets(cluster); err != nil {
+		return nil, err
+	}
 	joinKey, err := crypto.NewKeyPairFromv1alpha1(cluster.Spec.JoinKey)
 	if err != nil {
 		return nil, err
 	}
+	joinBoxKey, err := crypto.NewNaClBoxKeyPairFromv1alpha1(cluster.Spec.JoinBoxKey)
+	if err != nil {
+		return nil, err
+	}
+	signingKey, err := crypto.NewSigningKeyPairFromv1alpha1(cluster.Spec.SigningKey)
+	if err != nil {
+		return nil, err
+	}
 	if cluster.Spec.CertificateAuthorities == nil {
 		cluster.Spec.CertificateAuthorities = &clusterv1alpha1.CertificateAuthorities{}
 	}
@@ -147,34 +205,82 @@ func NewClusterFromv1alpha1(cluster *clusterv1alpha1.Cluster) (*Cluster, error)
 	if cluster.Spec.Networking == nil {
 		cluster.Spec.Networking = &clusterv1alpha1.ClusterNetworking{}
 	}
+	if cluster.Spec.HealthCheck == nil {
+		cluster.Spec.HealthCheck = &clusterv1alpha1.HealthCheck{}
+	}
+	if cluster.Spec.UpgradeStrategy == nil {
+		cluster.Spec.UpgradeStrategy = &clusterv1alpha1.UpgradeStrategy{
+			Type: clusterv1alpha1.UpgradeStrategyInPlace,
+		}
+	}
+	if cluster.Spec.Components == nil {
+		cluster.Spec.Components = &clusterv1alpha1.Components{
+			Scheduler:         true,
+			ControllerManager: true,
+		}
+	}
+	if cluster.Spec.UpgradeStrategy.Type == clusterv1alpha1.UpgradeStrategySurge && cluster.Spec.UpgradeStrategy.MaxSurge == 0 {
+		cluster.Spec.UpgradeStrategy.MaxSurge = 1
+	}
 	res := Cluster{
-		Name:                   cluster.Name,
-		Namespace:              cluster.Namespace,
-		ResourceVersion:        cluster.ResourceVersion,
-		Labels:                 cluster.Labels,
-		Annotations:            cluster.Annotations,
-		Finalizers:             cluster.Finalizers,
-		DeletionTimestamp:      cluster.DeletionTimestamp,
-		KubernetesVersion:      cluster.Spec.KubernetesVersion,
-		ControlPlaneReplicas:   cluster.Spec.ControlPlaneReplicas,
-		CertificateAuthorities: newCertificateAuthoritiesFromv1alpha1(cluster.Spec.CertificateAuthorities),
-		EtcdServer:             newEtcdServerFromv1alpha1(cluster.Spec.EtcdServer),
-		APIServer:              kubeAPIServer,
-		StorageClientEndpoints: cluster.Status.StorageClientEndpoints,
-		StoragePeerEndpoints:   cluster.Status.StoragePeerEndpoints,
-		VPN:                    newVPNFromv1alpha1(cluster.Spec.VPN),
-		VPNPeers:               newVPNPeersFromv1alpha1(cluster.Status.VPNPeers),
-		APIServerEndpoint:      cluster.Status.APIServerEndpoint,
-		VPNServerEndpoint:      cluster.Status.VPNServerEndpoint,
-		JoinKey:                joinKey,
-		DesiredJoinTokens:      cluster.Spec.JoinTokens,
-		CurrentJoinTokens:      cluster.Status.JoinTokens,
-		Conditions:             conditions.NewConditionListFromv1alpha1(cluster.Status.Conditions),
-		ClusterCIDR:            cluster.Spec.Networking.ClusterCIDR,
-		ServiceCIDR:            cluster.Spec.Networking.ServiceCIDR,
-		NodeCIDRMaskSize:       cluster.Spec.Networking.NodeCIDRMaskSize,
-		NodeCIDRMaskSizeIPv4:   cluster.Spec.Networking.NodeCIDRMaskSizeIPv4,
-		NodeCIDRMaskSizeIPv6:   cluster.Spec.Networking.NodeCIDRMaskSizeIPv6,
+		Name:                         cluster.Name,
+		Namespace:                    cluster.Namespace,
+		ResourceVersion:              cluster.ResourceVersion,
+		Generation:                   cluster.Generation,
+		Labels:                       cluster.Labels,
+		Annotations:                  cluster.Annotations,
+		Finalizers:                   cluster.Finalizers,
+		CreationTimestamp:            cluster.CreationTimestamp,
+		DeletionTimestamp:            cluster.DeletionTimestamp,
+		KubernetesVersion:            cluster.Spec.KubernetesVersion,
+		ControlPlaneReplicas:         cluster.Spec.ControlPlaneReplicas,
+		CertificateAuthorities:       newCertificateAuthoritiesFromv1alpha1(cluster.Spec.CertificateAuthorities),
+		EtcdServer:                   newEtcdServerFromv1alpha1(cluster.Spec.EtcdServer),
+		APIServer:                    kubeAPIServer,
+		StorageClientEndpoints:       cluster.Status.StorageClientEndpoints,
+		StoragePeerEndpoints:         cluster.Status.StoragePeerEndpoints,
+		VPN:                          newVPNFromv1alpha1(cluster.Spec.VPN),
+		VPNPeers:                     newVPNPeersFromv1alpha1(cluster.Status.VPNPeers),
+		APIServerEndpoint:            cluster.Status.APIServerEndpoint,
+		VPNServerEndpoint:            cluster.Status.VPNServerEndpoint,
+		JoinKey:                      joinKey,
+		JoinKeyCipherSuite:           cluster.Spec.JoinKeyCipherSuite,
+		JoinBoxKey:                   joinBoxKey,
+		SigningKey:                   signingKey,
+		DesiredJoinTokens:            cluster.Spec.JoinTokens,
+		CurrentJoinTokens:            cluster.Status.JoinTokens,
+		Conditions:                   conditions.NewConditionListFromv1alpha1(cluster.Status.Conditions),
+		ClusterCIDR:                  cluster.Spec.Networking.ClusterCIDR,
+		ServiceCIDR:                  cluster.Spec.Networking.ServiceCIDR,
+		NodeCIDRMaskSize:             cluster.Spec.Networking.NodeCIDRMaskSize,
+		NodeCIDRMaskSizeIPv4:         cluster.Spec.Networking.NodeCIDRMaskSizeIPv4,
+		NodeCIDRMaskSizeIPv6:         cluster.Spec.Networking.NodeCIDRMaskSizeIPv6,
+		Proxy:                        cluster.Spec.Proxy,
+		HealthCheckTimeoutSeconds:    cluster.Spec.HealthCheck.TimeoutSeconds,
+		HealthCheckIntervalSeconds:   cluster.Spec.HealthCheck.IntervalSeconds,
+		HypervisorPool:               cluster.Spec.HypervisorPool,
+		IngressHypervisorPool:        cluster.Spec.IngressHypervisorPool,
+		UpgradeStrategyType:          cluster.Spec.UpgradeStrategy.Type,
+		UpgradeStrategyMaxSurge:      cluster.Spec.UpgradeStrategy.MaxSurge,
+		UpgradeStrategyPaused:        cluster.Spec.UpgradeStrategy.Paused,
+		Authentication:               cluster.Spec.Authentication,
+		Components:                   cluster.Spec.Components,
+		Size:                         cluster.Spec.Size,
+		SizeOverrides:                cluster.Spec.SizeOverrides,
+		IngressTuning:                cluster.Spec.IngressTuning,
+		ImageDigests:                 cluster.Spec.ImageDigests,
+		ImageSignaturePolicy:         cluster.Spec.ImageSignaturePolicy,
+		AddonImagePolicy:             cluster.Spec.AddonImagePolicy,
+		ImageRegistry:                cluster.Spec.ImageRegistry,
+		Baseline:                     cluster.Spec.Baseline,
+		Upgrade:                      cluster.Status.Upgrade,
+		History:                      NewClusterHistoryEntryListFromv1alpha1(cluster.Status.History),
+		ProvisioningTimestamps:       cluster.Status.ProvisioningTimestamps,
+		CertificateRotationThreshold: cluster.Spec.CertificateRotationThreshold,
+		ExtraEnv:                     cluster.Spec.ExtraEnv,
+		ExtraHostPathMounts:          cluster.Spec.ExtraHostPathMounts,
+		Compliance:                   cluster.Status.Compliance,
+		Ingress:                      cluster.Status.Ingress,
 	}
 	res.ClientCertificates = map[string]*certificates.Certificate{}
 	for clientCertificateName, clientCertificate := range cluster.Status.ClientCertificates {
@@ -186,10 +292,10 @@ func NewClusterFromv1alpha1(cluster *clusterv1alpha1.Cluster) (*Cluster, error)
 	return &res, nil
 }
 
-// ClientCertificate returns a client certificate with the given name
+// ClientCertificate returns a client certificate with the given name,
+// re-issuing it when it is missing or approaching expiry
 func (cluster *Cluster) ClientCertificate(ca *certificates.Certificate, name, commonName string, organization []string, extraSANs []string) (*certificates.Certificate, error) {
-	// FIXME: not only check for existence, also that contents semantically match
-	if clientCertificate, exists := cluster.ClientCertificates[name]; exists {
+	if clientCertificate, exists := cluster.ClientCertificates[name]; exists && !clientCertificate.ExpiresWithin(cluster.RotationThreshold()) {
 		return clientCertificate, nil
 	}
 	certificate, privateKey, err := ca.CreateCertificate(commonName, organization, extraSANs)
@@ -204,16 +310,21 @@ func (cluster *Cluster) ClientCertificate(ca *certificates.Certificate, name, co
 	return clientCertificate, nil
 }
 
-// Export exports the cluster to a versioned cluster
-func (cluster *Cluster) Export() *clusterv1alpha1.Cluster {
+// Export exports the cluster to a versioned cluster. When secrets
+// encryption is configured, it also encrypts every private key and
+// key pair in the result; Export fails rather than returning them in
+// plain text if that encryption fails
+func (cluster *Cluster) Export() (*clusterv1alpha1.Cluster, error) {
 	res := &clusterv1alpha1.Cluster{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:              cluster.Name,
 			Namespace:         cluster.Namespace,
 			ResourceVersion:   cluster.ResourceVersion,
+			Generation:        cluster.Generation,
 			Labels:            cluster.Labels,
 			Annotations:       cluster.Annotations,
 			Finalizers:        cluster.Finalizers,
+			CreationTimestamp: cluster.CreationTimestamp,
 			DeletionTimestamp: cluster.DeletionTimestamp,
 		},
 		Spec: clusterv1alpha1.ClusterSpec{
@@ -224,7 +335,11 @@ func (cluster *Cluster) Export() *clusterv1alpha1.Cluster {
 			APIServer:              cluster.APIServer.Export(),
 			VPN:                    cluster.VPN.Export(),
 			JoinKey:                cluster.JoinKey.Export(),
+			JoinKeyCipherSuite:     cluster.JoinKeyCipherSuite,
+			JoinBoxKey:             cluster.JoinBoxKey.Export(),
+			SigningKey:             cluster.SigningKey.Export(),
 			JoinTokens:             cluster.DesiredJoinTokens,
+			Proxy:                  cluster.Proxy,
 			Networking: &clusterv1alpha1.ClusterNetworking{
 				ClusterCIDR:          cluster.ClusterCIDR,
 				ServiceCIDR:          cluster.ServiceCIDR,
@@ -232,6 +347,30 @@ func (cluster *Cluster) Export() *clusterv1alpha1.Cluster {
 				NodeCIDRMaskSizeIPv4: cluster.NodeCIDRMaskSizeIPv4,
 				NodeCIDRMaskSizeIPv6: cluster.NodeCIDRMaskSizeIPv6,
 			},
+			HealthCheck: &clusterv1alpha1.HealthCheck{
+				TimeoutSeconds:  cluster.HealthCheckTimeoutSeconds,
+				IntervalSeconds: cluster.HealthCheckIntervalSeconds,
+			},
+			HypervisorPool:        cluster.HypervisorPool,
+			IngressHypervisorPool: cluster.IngressHypervisorPool,
+			UpgradeStrategy: &clusterv1alpha1.UpgradeStrategy{
+				Type:     cluster.UpgradeStrategyType,
+				MaxSurge: cluster.UpgradeStrategyMaxSurge,
+				Paused:   cluster.UpgradeStrategyPaused,
+			},
+			Authentication:               cluster.Authentication,
+			Components:                   cluster.Components,
+			Size:                         cluster.Size,
+			SizeOverrides:                cluster.SizeOverrides,
+			IngressTuning:                cluster.IngressTuning,
+			ImageDigests:                 cluster.ImageDigests,
+			ImageSignaturePolicy:         cluster.ImageSignaturePolicy,
+			AddonImagePolicy:             cluster.AddonImagePolicy,
+			ImageRegistry:                cluster.ImageRegistry,
+			Baseline:                     cluster.Baseline,
+			CertificateRotationThreshold: cluster.CertificateRotationThreshold,
+			ExtraEnv:                     cluster.ExtraEnv,
+			ExtraHostPathMounts:          cluster.ExtraHostPathMounts,
 		},
 		Status: clusterv1alpha1.ClusterStatus{
 			StorageClientEndpoints: cluster.StorageClientEndpoints,
@@ -241,13 +380,21 @@ func (cluster *Cluster) Export() *clusterv1alpha1.Cluster {
 			VPNServerEndpoint:      cluster.VPNServerEndpoint,
 			JoinTokens:             cluster.CurrentJoinTokens,
 			Conditions:             cluster.Conditions.Export(),
+			Upgrade:                cluster.Upgrade,
+			History:                cluster.History.Export(),
+			ProvisioningTimestamps: cluster.ProvisioningTimestamps,
+			Compliance:             cluster.Compliance,
+			Ingress:                cluster.Ingress,
 		},
 	}
 	res.Status.ClientCertificates = map[string]commonv1alpha1.Certificate{}
 	for clientCertificateName, clientCertificate := range cluster.ClientCertificates {
 		res.Status.ClientCertificates[clientCertificateName] = *clientCertificate.Export()
 	}
-	return res
+	if err := encryptSecrets(res); err != nil {
+		return nil, errors.Wrapf(err, "could not encrypt secrets for cluster %q", cluster.Name)
+	}
+	return res, nil
 }
 
 // RefreshCachedSpecs refreshes the cached spec
@@ -273,7 +420,10 @@ func (cluster *Cluster) IsDirty() (bool, error) {
 
 // JSONSpecs returns the versioned specs of this cluster in JSON format
 func (cluster *Cluster) JSONSpecs() (string, error) {
-	clusterObject := cluster.Export()
+	clusterObject, err := cluster.Export()
+	if err != nil {
+		return "", err
+	}
 	clusterObject.Status.Conditions = commonv1alpha1.ConditionList{}
 	jsonSpecs, err := json.Marshal(clusterObject)
 	if err != nil {
@@ -290,22 +440,33 @@ func (cluster *Cluster) Specs() (string, error) {
 	}
 	info, _ := runtime.SerializerInfoForMediaType(serializer.NewCodecFactory(scheme).SupportedMediaTypes(), runtime.ContentTypeYAML)
 	encoder := serializer.NewCodecFactory(scheme).EncoderForVersion(info.Serializer, clusterv1alpha1.GroupVersion)
-	if encodedCluster, err := runtime.Encode(encoder, cluster.Export()); err == nil {
+	clusterObject, err := cluster.Export()
+	if err != nil {
+		return "", err
+	}
+	if encodedCluster, err := runtime.Encode(encoder, clusterObject); err == nil {
 		return string(encodedCluster), nil
 	}
 	return "", errors.Errorf("could not encode cluster %q", cluster.Name)
 }
 
-// GenerateVPNPeer generates a new VPN peer with name peerName
-func (cluster *Cluster) GenerateVPNPeer(peerName string) (*VPNPeer, error) {
+// GenerateVPNPeer generates a new VPN peer with name peerName for the
+// given purpose, returning an error if minting it would exceed the
+// purpose's quota in VPN.PeerQuotas. When ttl is non-nil, the peer is
+// stamped with an expiry time.Now()+ttl in the future, and
+// ReconcileExpiredVPNPeers releases it once that time has passed
+func (cluster *Cluster) GenerateVPNPeer(peerName string, purpose clusterv1alpha1.VPNPeerPurpose, ttl *time.Duration) (*VPNPeer, error) {
 	if vpnPeer, err := cluster.VPNPeer(peerName); err == nil {
 		return vpnPeer, nil
 	}
+	if err := cluster.checkVPNPeerQuota(purpose); err != nil {
+		return nil, err
+	}
 	controlPlaneIngressVPNIP, err := cluster.requestVPNIP()
 	if err != nil {
 		return nil, err
 	}
-	privateKey, err := wgtypes.GeneratePrivateKey()
+	privateKey, publicKey, err := vpnProviderFor(cluster.VPN.Backend).NewKeyPair()
 	if err != nil {
 		return nil, err
 	}
@@ -314,13 +475,59 @@ func (cluster *Cluster) GenerateVPNPeer(peerName string) (*VPNPeer, error) {
 	vpnPeer := &VPNPeer{
 		Name:       peerName,
 		Address:    ipAddressNet.String(),
-		PrivateKey: privateKey.String(),
-		PublicKey:  privateKey.PublicKey().String(),
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+		Purpose:    purpose,
+	}
+	if ttl != nil {
+		expiresAt := time.Now().Add(*ttl)
+		vpnPeer.ExpiresAt = &expiresAt
 	}
 	cluster.VPNPeers[peerName] = vpnPeer
 	return vpnPeer, nil
 }
 
+// ReconcileExpiredVPNPeers releases every VPN peer whose ExpiresAt has
+// elapsed, so a time-limited peer (e.g. one minted for operator access
+// through `oi cluster vpn add-peer --ttl`) cannot become permanent
+// standing access by oversight
+func (cluster *Cluster) ReconcileExpiredVPNPeers() error {
+	if cluster.VPN == nil || !cluster.VPN.Enabled {
+		return nil
+	}
+	now := time.Now()
+	for peerName, peer := range cluster.VPNPeers {
+		if peer.ExpiresAt == nil || peer.ExpiresAt.After(now) {
+			continue
+		}
+		klog.Infof("VPN peer %q for cluster %q expired at %s, releasing it", peerName, cluster.Name, peer.ExpiresAt)
+		if err := cluster.DeleteVPNPeer(peerName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVPNPeerQuota returns an error if purpose already has as many
+// VPN peers minted as its quota in VPN.PeerQuotas allows. Purposes
+// missing from PeerQuotas are left unbounded
+func (cluster *Cluster) checkVPNPeerQuota(purpose clusterv1alpha1.VPNPeerPurpose) error {
+	quota, hasQuota := cluster.VPN.PeerQuotas[purpose]
+	if !hasQuota {
+		return nil
+	}
+	used := 0
+	for _, peer := range cluster.VPNPeers {
+		if peer.Purpose == purpose {
+			used++
+		}
+	}
+	if used >= quota {
+		return errors.Errorf("VPN peer quota exceeded for purpose %q: %d/%d peers already minted", purpose, used, quota)
+	}
+	return nil
+}
+
 // VPNPeer returns the VPN peer with the provided name
 func (cluster *Cluster) VPNPeer(name string) (*VPNPeer, error) {
 	if vpnPeer, exists := cluster.VPNPeers[name]; exists {
@@ -329,6 +536,83 @@ func (cluster *Cluster) VPNPeer(name string) (*VPNPeer, error) {
 	return nil, errors.Errorf("vpn peer %q not found", name)
 }
 
+// DeleteVPNPeer removes the VPN peer with the provided name, freeing
+// its address for reuse by a future GenerateVPNPeer call
+func (cluster *Cluster) DeleteVPNPeer(name string) error {
+	if _, err := cluster.VPNPeer(name); err != nil {
+		return err
+	}
+	delete(cluster.VPNPeers, name)
+	return nil
+}
+
+// RotateVPNPeerKeys re-issues the key pair for the VPN peer with the
+// provided name, keeping its purpose and address unchanged
+func (cluster *Cluster) RotateVPNPeerKeys(name string) (*VPNPeer, error) {
+	vpnPeer, err := cluster.VPNPeer(name)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, publicKey, err := vpnProviderFor(cluster.VPN.Backend).NewKeyPair()
+	if err != nil {
+		return nil, err
+	}
+	vpnPeer.PrivateKey = privateKey
+	vpnPeer.PublicKey = publicKey
+	return vpnPeer, nil
+}
+
+// VPNPeerClientConfig renders a ready to use wg-quick configuration
+// for the named VPN peer, so it can be handed to a human operator to
+// join the cluster VPN without hand-assembling one
+func (cluster *Cluster) VPNPeerClientConfig(name string) (string, error) {
+	if cluster.VPN == nil || !cluster.VPN.Enabled {
+		return "", errors.Errorf("cluster %q does not have the VPN enabled", cluster.Name)
+	}
+	vpnPeer, err := cluster.VPNPeer(name)
+	if err != nil {
+		return "", err
+	}
+	tpl, err := template.New("").Parse(wireguardClientConfigTemplate)
+	if err != nil {
+		return "", err
+	}
+	var clientConfig bytes.Buffer
+	err = tpl.Execute(&clientConfig, struct {
+		Address         string
+		PrivateKey      string
+		ServerPublicKey string
+		ServerEndpoint  string
+		AllowedIPs      string
+	}{
+		Address:         vpnPeer.Address,
+		PrivateKey:      vpnPeer.PrivateKey,
+		ServerPublicKey: cluster.VPN.PublicKey,
+		ServerEndpoint:  cluster.VPNServerEndpoint,
+		AllowedIPs:      cluster.VPN.CIDR.String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return clientConfig.String(), nil
+}
+
+// MarkProvisioningPhase records the current time as when this cluster
+// first reached phase, if it had not reached it already, so the
+// timestamp reflects when the phase was reached, not every
+// subsequent reconcile that finds it still reached
+func (cluster *Cluster) MarkProvisioningPhase(phase clusterv1alpha1.ProvisioningPhase) {
+	if _, alreadyReached := cluster.ProvisioningTimestamps[phase]; alreadyReached {
+		return
+	}
+	if cluster.ProvisioningTimestamps == nil {
+		cluster.ProvisioningTimestamps = map[clusterv1alpha1.ProvisioningPhase]metav1.Time{}
+	}
+	reachedAt := metav1.Now()
+	cluster.ProvisioningTimestamps[phase] = reachedAt
+	metrics.ObserveProvisioningPhase(phase, cluster.CreationTimestamp.Time, reachedAt.Time)
+}
+
 // HasUninitializedCertificates returns whether this cluster has
 // uninitialized certificates
 func (cluster *Cluster) HasUninitializedCertificates() bool {
@@ -336,19 +620,36 @@ func (cluster *Cluster) HasUninitializedCertificates() bool {
 	return hasUninitializedCertificates
 }
 
-// requestVPNIP requests a VPN from the VPN CIDR
-func (cluster *Cluster) requestVPNIP() (string, error) {
-	assignedIP := big.NewInt(int64(len(cluster.VPNPeers) + 1))
-	vpnNetwork := big.NewInt(0).SetBytes(cluster.VPN.CIDR.IP.To16())
-	vpnAssignedIP := vpnNetwork.Add(vpnNetwork, assignedIP)
-	vpnAssignedIPSlice := vpnAssignedIP.Bytes()[2:]
-	if len(vpnAssignedIP.Bytes()) == net.IPv6len {
-		vpnAssignedIPSlice = vpnAssignedIP.Bytes()
+// EffectiveKubernetesVersion returns the Kubernetes version the
+// control plane components should actually run right now. This is
+// normally KubernetesVersion, but while the upgrade strategy is
+// paused and an upgrade is in progress (the control plane was last
+// observed ready under a different version), it holds at
+// Upgrade.LastReadyKubernetesVersion instead, so reconciling a
+// paused cluster stops driving any further component towards the
+// new version until it is resumed
+func (cluster *Cluster) EffectiveKubernetesVersion() string {
+	if !cluster.UpgradeStrategyPaused || cluster.Upgrade == nil || cluster.Upgrade.LastReadyKubernetesVersion == "" {
+		return cluster.KubernetesVersion
 	}
-	if !cluster.VPN.CIDR.Contains(net.IP(vpnAssignedIPSlice)) {
-		return "", errors.Errorf("not enough IP addresses to assign in the %q CIDR", cluster.VPN.CIDR)
+	return cluster.Upgrade.LastReadyKubernetesVersion
+}
+
+// requestVPNIP requests a VPN address from the VPN CIDR, using the
+// cluster's configured VPN backend to assign the first address not
+// already allocated to one of this cluster's current VPN peers. The
+// peers themselves, persisted in the exported v1alpha1 status, are
+// the allocation record: an address becomes free to reassign the
+// moment its peer is removed from VPNPeers, with no separate ledger
+// to keep in sync
+func (cluster *Cluster) requestVPNIP() (string, error) {
+	allocated := map[string]bool{}
+	for _, peer := range cluster.VPNPeers {
+		if ip, _, err := net.ParseCIDR(peer.Address); err == nil {
+			allocated[ip.String()] = true
+		}
 	}
-	return net.IP(vpnAssignedIPSlice).String(), nil
+	return vpnProviderFor(cluster.VPN.Backend).AssignAddress(cluster.VPN.CIDR, allocated)
 }
 
 // Specs returns the versioned specs of all clusters in this map
@@ -0,0 +1,89 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/certificates"
+	"github.com/oneinfra/oneinfra/internal/pkg/conditions"
+)
+
+// RotationThreshold returns how far ahead of expiry this cluster's
+// certificates should be rotated, defaulting to
+// DefaultCertificateRotationThreshold when unset
+func (cluster *Cluster) RotationThreshold() time.Duration {
+	if cluster.CertificateRotationThreshold == nil {
+		return DefaultCertificateRotationThreshold
+	}
+	return cluster.CertificateRotationThreshold.Duration
+}
+
+// RotateCertificates re-issues this cluster's certificate
+// authorities when they are approaching expiry, within
+// RotationThreshold. Leaf certificates signed by those authorities
+// (apiserver TLS, etcd TLS, kubelet client certs) are re-issued
+// lazily by their own ClientCertificate/ServerCertificate callers the
+// next time they are requested, using the same ExpiresWithin check.
+// It returns whether any certificate authority was rotated
+func (cluster *Cluster) RotateCertificates() (bool, error) {
+	return cluster.rotateCertificates(false)
+}
+
+// ForceRotateCertificates immediately re-issues all of this
+// cluster's certificate authorities, regardless of their expiry. It
+// is used by the `oi cluster rotate-ca` migration command, for
+// operators who need to rotate ahead of the automatic schedule (for
+// example, after a suspected key compromise). It returns whether any
+// certificate authority was rotated, which is always true unless the
+// cluster has no certificate authorities issued yet
+func (cluster *Cluster) ForceRotateCertificates() (bool, error) {
+	return cluster.rotateCertificates(true)
+}
+
+func (cluster *Cluster) rotateCertificates(force bool) (bool, error) {
+	rotated := false
+	for name, authority := range map[string]**certificates.Certificate{
+		"apiserver-client-authority":   &cluster.CertificateAuthorities.APIServerClient,
+		"certificate-signer-authority": &cluster.CertificateAuthorities.CertificateSigner,
+		"kubelet-authority":            &cluster.CertificateAuthorities.Kubelet,
+		"kubelet-client-authority":     &cluster.CertificateAuthorities.KubeletClient,
+		"etcd-client-authority":        &cluster.CertificateAuthorities.EtcdClient,
+		"etcd-peer-authority":          &cluster.CertificateAuthorities.EtcdPeer,
+		"etcd-authority":               &cluster.EtcdServer.CA,
+		"apiserver-authority":          &cluster.APIServer.CA,
+	} {
+		if *authority == nil || !(force || (*authority).ExpiresWithin(cluster.RotationThreshold())) {
+			continue
+		}
+		klog.Infof("rotating %q for cluster %q", name, cluster.Name)
+		newAuthority, err := certificates.NewCertificateAuthority(name)
+		if err != nil {
+			return rotated, err
+		}
+		*authority = newAuthority
+		rotated = true
+	}
+	if rotated {
+		cluster.Conditions.SetCondition(CertificatesNeedingRotation, conditions.ConditionTrue)
+	} else {
+		cluster.Conditions.SetCondition(CertificatesNeedingRotation, conditions.ConditionFalse)
+	}
+	return rotated, nil
+}
@@ -19,6 +19,7 @@ package cluster
 import (
 	"k8s.io/klog/v2"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	"github.com/oneinfra/oneinfra/internal/pkg/certificates"
 	"github.com/oneinfra/oneinfra/internal/pkg/crypto"
 	"github.com/oneinfra/oneinfra/pkg/constants"
@@ -37,7 +38,10 @@ func (cluster *Cluster) InitializeCertificatesAndKeys() error {
 	if err := cluster.initializeAPIServerCertificateAuthority(); err != nil {
 		return err
 	}
-	return cluster.initializeJoinKey()
+	if err := cluster.initializeJoinKey(); err != nil {
+		return err
+	}
+	return cluster.initializeSigningKey()
 }
 
 func (cluster *Cluster) initializeCertificateAuthorities() error {
@@ -72,6 +76,9 @@ func (cluster *Cluster) initializeCertificateAuthorities() error {
 		}
 		cluster.CertificateAuthorities.KubeletClient = kubeletClientAuthority
 	}
+	if cluster.EtcdServer != nil && cluster.EtcdServer.External != nil {
+		return nil
+	}
 	if cluster.CertificateAuthorities.EtcdClient == nil {
 		etcdClientAuthority, err := certificates.NewCertificateAuthority("etcd-client-authority")
 		if err != nil {
@@ -89,10 +96,17 @@ func (cluster *Cluster) initializeCertificateAuthorities() error {
 	return nil
 }
 
+// initializeEtcdServerCertificateAuthority generates the etcd server
+// certificate authority used to sign the certificates of a managed
+// etcd cluster. Skipped when an external etcd cluster is configured,
+// since there is no server certificate for oneinfra to sign
 func (cluster *Cluster) initializeEtcdServerCertificateAuthority() error {
 	if cluster.EtcdServer == nil {
 		cluster.EtcdServer = &EtcdServer{}
 	}
+	if cluster.EtcdServer.External != nil {
+		return nil
+	}
 	if cluster.EtcdServer.CA == nil {
 		etcdServerCA, err := certificates.NewCertificateAuthority("etcd-authority")
 		if err != nil {
@@ -125,6 +139,16 @@ func (cluster *Cluster) initializeAPIServerCertificateAuthority() error {
 }
 
 func (cluster *Cluster) initializeJoinKey() error {
+	if cluster.JoinKeyCipherSuite == clusterv1alpha1.JoinKeyCipherSuiteNaClBox {
+		if cluster.JoinBoxKey == nil {
+			joinBoxKey, err := crypto.NewNaClBoxKeyPair()
+			if err != nil {
+				return err
+			}
+			cluster.JoinBoxKey = joinBoxKey
+		}
+		return nil
+	}
 	if cluster.JoinKey == nil {
 		joinKey, err := crypto.NewPrivateKey(constants.DefaultKeyBitSize)
 		if err != nil {
@@ -134,3 +158,41 @@ func (cluster *Cluster) initializeJoinKey() error {
 	}
 	return nil
 }
+
+// effectiveJoinKeyCipherSuite returns this cluster's join key cipher
+// suite, defaulting to RSA-OAEP when unset so every node join request
+// records which cipher suite was actually used, even for clusters
+// that never set JoinKeyCipherSuite explicitly
+func (cluster *Cluster) effectiveJoinKeyCipherSuite() clusterv1alpha1.JoinKeyCipherSuite {
+	if cluster.JoinKeyCipherSuite == "" {
+		return clusterv1alpha1.JoinKeyCipherSuiteRSAOAEP
+	}
+	return cluster.JoinKeyCipherSuite
+}
+
+// JoinKeyProvider returns the crypto.KeyPairProvider that protects
+// node join payloads for this cluster, picked according to
+// JoinKeyCipherSuite
+func (cluster *Cluster) JoinKeyProvider() crypto.KeyPairProvider {
+	if cluster.JoinKeyCipherSuite == clusterv1alpha1.JoinKeyCipherSuiteNaClBox {
+		if cluster.JoinBoxKey == nil {
+			return nil
+		}
+		return cluster.JoinBoxKey
+	}
+	if cluster.JoinKey == nil {
+		return nil
+	}
+	return cluster.JoinKey
+}
+
+func (cluster *Cluster) initializeSigningKey() error {
+	if cluster.SigningKey == nil {
+		signingKey, err := crypto.NewSigningKeyPair()
+		if err != nil {
+			return err
+		}
+		cluster.SigningKey = signingKey
+	}
+	return nil
+}
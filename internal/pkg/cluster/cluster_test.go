@@ -19,63 +19,75 @@ package cluster
 import (
 	"fmt"
 	"net"
+	"reflect"
 	"testing"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
 )
 
 func TestRequestVPNIP(t *testing.T) {
 	var tests = []struct {
-		cidr        string
-		peers       VPNPeerMap
-		expectedIP  string
-		expectedErr bool
+		cidr          string
+		peers         int
+		skipAddresses []string
+		expectedIP    string
+		expectedErr   bool
 	}{
 		{
 			cidr:        "10.0.0.0/8",
-			peers:       vpnPeers(0),
+			peers:       0,
 			expectedIP:  "10.0.0.1",
 			expectedErr: false,
 		},
 		{
 			cidr:        "10.0.0.0/8",
-			peers:       vpnPeers(1),
+			peers:       1,
 			expectedIP:  "10.0.0.2",
 			expectedErr: false,
 		},
 		{
 			cidr:        "10.0.0.0/31",
-			peers:       vpnPeers(1),
+			peers:       1,
 			expectedErr: true,
 		},
 		{
 			cidr:        "10.0.0.0/24",
-			peers:       vpnPeers(255),
+			peers:       255,
 			expectedErr: true,
 		},
+		{
+			cidr:          "10.0.0.0/8",
+			peers:         2,
+			skipAddresses: []string{"10.0.0.1"},
+			expectedIP:    "10.0.0.1",
+			expectedErr:   false,
+		},
 		{
 			cidr:        "fd00::/8",
-			peers:       vpnPeers(0),
+			peers:       0,
 			expectedIP:  "fd00::1",
 			expectedErr: false,
 		},
 		{
 			cidr:        "fd00::/8",
-			peers:       vpnPeers(1),
+			peers:       1,
 			expectedIP:  "fd00::2",
 			expectedErr: false,
 		},
 		{
 			cidr:        "fd00::/127",
-			peers:       vpnPeers(1),
+			peers:       1,
 			expectedErr: true,
 		},
 	}
 	for _, tt := range tests {
-		t.Run(fmt.Sprintf("%s (peers: %d)", tt.cidr, len(tt.peers)), func(t *testing.T) {
+		t.Run(fmt.Sprintf("%s (peers: %d)", tt.cidr, tt.peers), func(t *testing.T) {
 			_, cidrNetwork, err := net.ParseCIDR(tt.cidr)
 			if err != nil {
 				t.Fatalf("could not parse CIDR %q", tt.cidr)
 			}
-			cluster := Cluster{VPN: &VPN{CIDR: cidrNetwork}, VPNPeers: tt.peers}
+			cluster := Cluster{VPN: &VPN{CIDR: cidrNetwork}, VPNPeers: vpnPeers(cidrNetwork, tt.peers, tt.skipAddresses)}
 			if ip, err := cluster.requestVPNIP(); (err != nil) != tt.expectedErr {
 				t.Errorf("got %v error, was expecting: %v", err, tt.expectedErr)
 			} else if ip != tt.expectedIP {
@@ -85,13 +97,98 @@ func TestRequestVPNIP(t *testing.T) {
 	}
 }
 
-func vpnPeers(peerNumber int) VPNPeerMap {
+// vpnPeers returns peerNumber VPN peers with addresses allocated
+// sequentially from cidr, skipping any address in skipAddresses, so
+// tests can simulate a peer having been deleted and its address
+// freed for reuse
+func vpnPeers(cidr *net.IPNet, peerNumber int, skipAddresses []string) VPNPeerMap {
+	skip := map[string]bool{}
+	for _, address := range skipAddresses {
+		skip[address] = true
+	}
 	res := VPNPeerMap{}
-	for i := 0; i < peerNumber; i++ {
-		peerName := fmt.Sprintf("peer-%d", i)
+	offset, assigned := int64(1), 0
+	for assigned < peerNumber {
+		address := vpnAddressAtOffset(cidr, offset)
+		offset++
+		if skip[address.String()] {
+			continue
+		}
+		peerName := fmt.Sprintf("peer-%d", assigned)
 		res[peerName] = &VPNPeer{
-			Name: peerName,
+			Name:    peerName,
+			Address: (&net.IPNet{IP: address, Mask: cidr.Mask}).String(),
 		}
+		assigned++
 	}
 	return res
 }
+
+// TestClusterRoundTrip guards against the v1alpha1 conversion
+// silently dropping a spec field: it populates a selection of the
+// fields that have been added to ClusterSpec since the original
+// conversion was written, round-trips it through Export and
+// NewClusterFromv1alpha1, and compares the fields that are expected
+// to survive verbatim. Only the internal to v1alpha1 round trip is
+// exercised here; the v1beta1 conversion is not covered by this
+// test. Fixtures are built field by field rather than through a
+// generic reflection-based fuzzer, since several fields (JoinKey,
+// SigningKey, VPN key material) are only well-formed when generated
+// through their own constructors
+func TestClusterRoundTrip(t *testing.T) {
+	cluster, err := NewCluster("round-trip-cluster", "1.20.1", 3, true, "10.0.0.0/16", []string{"extra-san"})
+	if err != nil {
+		t.Fatalf("could not create cluster: %v", err)
+	}
+	cluster.Namespace = "tenant-a"
+	cluster.ImageRegistry = "registry.example.com/mirror"
+	cluster.ImageDigests = &clusterv1alpha1.ImageDigests{
+		Etcd:              "sha256:aaaa",
+		APIServer:         "sha256:bbbb",
+		ControllerManager: "sha256:cccc",
+		Scheduler:         "sha256:dddd",
+	}
+	cluster.ImageSignaturePolicy = &commonv1alpha1.ImageSignaturePolicy{
+		PublicKeys: []string{"-----BEGIN PUBLIC KEY-----\nfake\n-----END PUBLIC KEY-----"},
+	}
+	cluster.AddonImagePolicy = &clusterv1alpha1.AddonImagePolicy{
+		AllowedRegistries: []string{"k8s.gcr.io", "registry.example.com"},
+	}
+	cluster.Baseline = &clusterv1alpha1.Baseline{
+		Namespaces: []string{"extra-namespace"},
+	}
+	cluster.Components = &clusterv1alpha1.Components{
+		Scheduler: true,
+	}
+	cluster.Authentication = &clusterv1alpha1.Authentication{
+		JWT: []clusterv1alpha1.JWTAuthenticator{
+			{Issuer: clusterv1alpha1.JWTIssuer{URL: "https://issuer.example.com", Audiences: []string{"oneinfra"}}},
+		},
+	}
+	exportedCluster, err := cluster.Export()
+	if err != nil {
+		t.Fatalf("could not export cluster: %v", err)
+	}
+	roundTripped, err := NewClusterFromv1alpha1(exportedCluster)
+	if err != nil {
+		t.Fatalf("could not round trip cluster: %v", err)
+	}
+	fields := []struct {
+		name          string
+		before, after interface{}
+	}{
+		{"ImageRegistry", cluster.ImageRegistry, roundTripped.ImageRegistry},
+		{"ImageDigests", cluster.ImageDigests, roundTripped.ImageDigests},
+		{"ImageSignaturePolicy", cluster.ImageSignaturePolicy, roundTripped.ImageSignaturePolicy},
+		{"AddonImagePolicy", cluster.AddonImagePolicy, roundTripped.AddonImagePolicy},
+		{"Baseline", cluster.Baseline, roundTripped.Baseline},
+		{"Components", cluster.Components, roundTripped.Components},
+		{"Authentication", cluster.Authentication, roundTripped.Authentication},
+		{"Namespace", cluster.Namespace, roundTripped.Namespace},
+	}
+	for _, field := range fields {
+		if !reflect.DeepEqual(field.before, field.after) {
+			t.Errorf("field %q did not survive the round trip: before %#v, after %#v", field.name, field.before, field.after)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/certificates"
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+)
+
+// MinimumCertificateKeyBitSize is the smallest RSA key size a
+// certificate authority or leaf certificate is allowed to carry
+// before it is flagged as non-compliant
+const MinimumCertificateKeyBitSize = 2048
+
+// EvaluateCompliance runs this cluster's certificate and
+// configuration compliance checks (certificate expiry, certificate
+// key size, insecure apiserver flags and Kubernetes version support)
+// and returns a fresh report. It does not mutate the cluster; callers
+// that want the report persisted must assign it to Compliance
+// themselves
+func (cluster *Cluster) EvaluateCompliance() *clusterv1alpha1.ComplianceStatus {
+	issues := []clusterv1alpha1.ComplianceIssue{}
+	issues = append(issues, cluster.certificateAuthorityComplianceIssues()...)
+	issues = append(issues, cluster.apiServerComplianceIssues()...)
+	issues = append(issues, cluster.kubernetesVersionComplianceIssues()...)
+	return &clusterv1alpha1.ComplianceStatus{
+		LastScanned: metav1.Now(),
+		Issues:      issues,
+	}
+}
+
+func (cluster *Cluster) certificateAuthorityComplianceIssues() []clusterv1alpha1.ComplianceIssue {
+	issues := []clusterv1alpha1.ComplianceIssue{}
+	if cluster.CertificateAuthorities == nil {
+		return issues
+	}
+	certificateAuthorities := map[string]*certificates.Certificate{
+		"apiserver-client":   cluster.CertificateAuthorities.APIServerClient,
+		"certificate-signer": cluster.CertificateAuthorities.CertificateSigner,
+		"kubelet":            cluster.CertificateAuthorities.Kubelet,
+		"kubelet-client":     cluster.CertificateAuthorities.KubeletClient,
+		"etcd-client":        cluster.CertificateAuthorities.EtcdClient,
+		"etcd-peer":          cluster.CertificateAuthorities.EtcdPeer,
+	}
+	for name, certificateAuthority := range certificateAuthorities {
+		if certificateAuthority == nil {
+			continue
+		}
+		if certificateAuthority.ExpiresWithin(cluster.RotationThreshold()) {
+			issues = append(issues, clusterv1alpha1.ComplianceIssue{
+				Check:   "certificate-expiring",
+				Subject: name,
+				Message: fmt.Sprintf("certificate authority %q is expired or approaching expiry", name),
+			})
+		}
+		if keyBitSize := certificateAuthority.KeyBitSize(); keyBitSize > 0 && keyBitSize < MinimumCertificateKeyBitSize {
+			issues = append(issues, clusterv1alpha1.ComplianceIssue{
+				Check:   "weak-key-size",
+				Subject: name,
+				Message: fmt.Sprintf("certificate authority %q is signed with a %d bit key, below the %d bit minimum", name, keyBitSize, MinimumCertificateKeyBitSize),
+			})
+		}
+	}
+	return issues
+}
+
+func (cluster *Cluster) apiServerComplianceIssues() []clusterv1alpha1.ComplianceIssue {
+	issues := []clusterv1alpha1.ComplianceIssue{}
+	if cluster.APIServer == nil {
+		return issues
+	}
+	if cluster.APIServer.AnonymousAuth {
+		issues = append(issues, clusterv1alpha1.ComplianceIssue{
+			Check:   "insecure-apiserver-flag",
+			Subject: "anonymousAuth",
+			Message: "apiserver anonymous authentication is enabled",
+		})
+	}
+	if cluster.APIServer.EnableProfiling {
+		issues = append(issues, clusterv1alpha1.ComplianceIssue{
+			Check:   "insecure-apiserver-flag",
+			Subject: "enableProfiling",
+			Message: "apiserver profiling endpoint is enabled",
+		})
+	}
+	if cluster.APIServer.EnableInsecurePort {
+		issues = append(issues, clusterv1alpha1.ComplianceIssue{
+			Check:   "insecure-apiserver-flag",
+			Subject: "enableInsecurePort",
+			Message: "apiserver insecure port is enabled",
+		})
+	}
+	return issues
+}
+
+func (cluster *Cluster) kubernetesVersionComplianceIssues() []clusterv1alpha1.ComplianceIssue {
+	if _, supported := constants.KubernetesVersions[cluster.EffectiveKubernetesVersion()]; supported {
+		return nil
+	}
+	return []clusterv1alpha1.ComplianceIssue{
+		{
+			Check:   "unsupported-kubernetes-version",
+			Subject: cluster.EffectiveKubernetesVersion(),
+			Message: fmt.Sprintf("Kubernetes version %q is not part of the supported version matrix", cluster.EffectiveKubernetesVersion()),
+		},
+	}
+}
@@ -0,0 +1,31 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+// SchedulerEnabled returns whether this cluster should run a
+// kube-scheduler instance. Defaults to true when no explicit
+// component toggle has been set.
+func (cluster *Cluster) SchedulerEnabled() bool {
+	return cluster.Components == nil || cluster.Components.Scheduler
+}
+
+// ControllerManagerEnabled returns whether this cluster should run a
+// kube-controller-manager instance. Defaults to true when no
+// explicit component toggle has been set.
+func (cluster *Cluster) ControllerManagerEnabled() bool {
+	return cluster.Components == nil || cluster.Components.ControllerManager
+}
@@ -23,6 +23,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	"github.com/oneinfra/oneinfra/pkg/constants"
 )
 
@@ -33,6 +34,15 @@ func (cluster *Cluster) ReconcileJoinPublicKeyConfigMap() error {
 	if err != nil {
 		return err
 	}
+	data := map[string]string{
+		constants.OneInfraJoinConfigMapSigningKey: cluster.SigningKey.PublicKey,
+	}
+	if cluster.JoinKeyCipherSuite == clusterv1alpha1.JoinKeyCipherSuiteNaClBox {
+		data[constants.OneInfraJoinConfigMapJoinKey] = cluster.JoinBoxKey.PublicKey
+		data[constants.OneInfraJoinConfigMapJoinKeyCipherSuite] = string(clusterv1alpha1.JoinKeyCipherSuiteNaClBox)
+	} else {
+		data[constants.OneInfraJoinConfigMapJoinKey] = cluster.JoinKey.PublicKey
+	}
 	_, err = client.CoreV1().ConfigMaps(constants.OneInfraNamespace).Create(
 		context.TODO(),
 		&v1.ConfigMap{
@@ -40,9 +50,7 @@ func (cluster *Cluster) ReconcileJoinPublicKeyConfigMap() error {
 				Name:      constants.OneInfraJoinConfigMap,
 				Namespace: constants.OneInfraNamespace,
 			},
-			Data: map[string]string{
-				constants.OneInfraJoinConfigMapJoinKey: cluster.JoinKey.PublicKey,
-			},
+			Data: data,
 		},
 		metav1.CreateOptions{})
 	if err != nil && apierrors.IsAlreadyExists(err) {
@@ -175,6 +175,10 @@ func (cluster *Cluster) reconcileCoreDNSDeployment(client clientset.Interface) e
 	if err != nil {
 		return err
 	}
+	coreDNSImage, err := cluster.addonImage("coredns", fmt.Sprintf(coreDNSImage, coreDNSVersion))
+	if err != nil {
+		return err
+	}
 	_, err = client.AppsV1().Deployments(metav1.NamespaceSystem).Create(
 		context.TODO(),
 		&appsv1.Deployment{
@@ -237,7 +241,7 @@ func (cluster *Cluster) reconcileCoreDNSDeployment(client clientset.Interface) e
 						Containers: []corev1.Container{
 							{
 								Name:  "coredns",
-								Image: fmt.Sprintf(coreDNSImage, coreDNSVersion),
+								Image: coreDNSImage,
 								Args:  []string{"-conf", "/etc/coredns/Corefile"},
 								VolumeMounts: []corev1.VolumeMount{
 									{
@@ -321,8 +325,29 @@ func (cluster *Cluster) reconcileCoreDNSDeployment(client clientset.Interface) e
 		metav1.CreateOptions{},
 	)
 	if err != nil && apierrors.IsAlreadyExists(err) {
+		return cluster.upgradeCoreDNSDeploymentImage(client, coreDNSImage)
+	}
+	return err
+}
+
+// upgradeCoreDNSDeploymentImage updates the already reconciled
+// CoreDNS deployment to coreDNSImage when it has drifted from it and
+// "coredns" is not a FrozenAddon, so cluster upgrades also roll
+// CoreDNS forward to the version pinned for the new Kubernetes
+// version
+func (cluster *Cluster) upgradeCoreDNSDeploymentImage(client clientset.Interface, coreDNSImage string) error {
+	if !cluster.addonUpgradeAllowed("coredns") {
+		return nil
+	}
+	deployment, err := client.AppsV1().Deployments(metav1.NamespaceSystem).Get(context.TODO(), "coredns", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if len(deployment.Spec.Template.Spec.Containers) == 0 || deployment.Spec.Template.Spec.Containers[0].Image == coreDNSImage {
 		return nil
 	}
+	deployment.Spec.Template.Spec.Containers[0].Image = coreDNSImage
+	_, err = client.AppsV1().Deployments(metav1.NamespaceSystem).Update(context.TODO(), deployment, metav1.UpdateOptions{})
 	return err
 }
 
@@ -48,9 +48,15 @@ func (cluster *Cluster) ReconcileCustomResourceDefinitions() error {
 		return err
 	}
 	if versionCompare < 0 {
-		return cluster.reconcileNodeJoinRequestsCRDLegacy(client)
+		if err := cluster.reconcileNodeJoinRequestsCRDLegacy(client); err != nil {
+			return err
+		}
+		return cluster.reconcileNodeLeaveRequestsCRDLegacy(client)
 	}
-	return cluster.reconcileNodeJoinRequestsCRD(client)
+	if err := cluster.reconcileNodeJoinRequestsCRD(client); err != nil {
+		return err
+	}
+	return cluster.reconcileNodeLeaveRequestsCRD(client)
 }
 
 func (cluster *Cluster) reconcileNodeJoinRequestsCRD(client apiextensionsclientset.Interface) error {
@@ -109,6 +115,104 @@ func (cluster *Cluster) reconcileNodeJoinRequestsCRD(client apiextensionsclients
 	return err
 }
 
+func (cluster *Cluster) reconcileNodeLeaveRequestsCRD(client apiextensionsclientset.Interface) error {
+	openAPISchema := extensionsv1.JSONSchemaProps{}
+	if err := yaml.Unmarshal([]byte(nodev1alpha1.NodeLeaveRequestOpenAPISchema), &openAPISchema); err != nil {
+		return err
+	}
+	_, err := client.ApiextensionsV1().CustomResourceDefinitions().Create(
+		context.TODO(),
+		&extensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("nodeleaverequests.%s", nodev1alpha1.GroupVersion.Group),
+			},
+			Spec: extensionsv1.CustomResourceDefinitionSpec{
+				Group: nodev1alpha1.GroupVersion.Group,
+				Names: extensionsv1.CustomResourceDefinitionNames{
+					Plural:     "nodeleaverequests",
+					Singular:   "nodeleaverequest",
+					ShortNames: []string{"nlr", "nlrs"},
+					Kind:       "NodeLeaveRequest",
+					ListKind:   "NodeLeaveRequestList",
+				},
+				Scope: extensionsv1.ClusterScoped,
+				Versions: []extensionsv1.CustomResourceDefinitionVersion{
+					{
+						Name:    nodev1alpha1.GroupVersion.Version,
+						Served:  true,
+						Storage: true,
+						Schema: &extensionsv1.CustomResourceValidation{
+							OpenAPIV3Schema: &openAPISchema,
+						},
+						Subresources: &extensionsv1.CustomResourceSubresources{
+							Status: &extensionsv1.CustomResourceSubresourceStatus{},
+						},
+						AdditionalPrinterColumns: []extensionsv1.CustomResourceColumnDefinition{
+							{
+								Name:     "Age",
+								Type:     "date",
+								JSONPath: ".metadata.creationTimestamp",
+							},
+						},
+					},
+				},
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil && apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+func (cluster *Cluster) reconcileNodeLeaveRequestsCRDLegacy(client apiextensionsclientset.Interface) error {
+	openAPISchema := extensionsv1beta1.JSONSchemaProps{}
+	if err := yaml.Unmarshal([]byte(nodev1alpha1.NodeLeaveRequestOpenAPISchema), &openAPISchema); err != nil {
+		return err
+	}
+	falseVar := false
+	_, err := client.ApiextensionsV1beta1().CustomResourceDefinitions().Create(
+		context.TODO(),
+		&extensionsv1beta1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("nodeleaverequests.%s", nodev1alpha1.GroupVersion.Group),
+			},
+			Spec: extensionsv1beta1.CustomResourceDefinitionSpec{
+				Group:   nodev1alpha1.GroupVersion.Group,
+				Version: nodev1alpha1.GroupVersion.Version,
+				Names: extensionsv1beta1.CustomResourceDefinitionNames{
+					Plural:     "nodeleaverequests",
+					Singular:   "nodeleaverequest",
+					ShortNames: []string{"nlr", "nlrs"},
+					Kind:       "NodeLeaveRequest",
+					ListKind:   "NodeLeaveRequestList",
+				},
+				Scope: extensionsv1beta1.ClusterScoped,
+				Validation: &extensionsv1beta1.CustomResourceValidation{
+					OpenAPIV3Schema: &openAPISchema,
+				},
+				Subresources: &extensionsv1beta1.CustomResourceSubresources{
+					Status: &extensionsv1beta1.CustomResourceSubresourceStatus{},
+				},
+				AdditionalPrinterColumns: []extensionsv1beta1.CustomResourceColumnDefinition{
+					{
+						Name:     "Age",
+						Type:     "date",
+						JSONPath: ".metadata.creationTimestamp",
+					},
+				},
+				PreserveUnknownFields: &falseVar,
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if err != nil && apierrors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
 func (cluster *Cluster) reconcileNodeJoinRequestsCRDLegacy(client apiextensionsclientset.Interface) error {
 	openAPISchema := extensionsv1beta1.JSONSchemaProps{}
 	if err := yaml.Unmarshal([]byte(nodev1alpha1.NodeJoinRequestOpenAPISchema), &openAPISchema); err != nil {
@@ -23,7 +23,67 @@ import (
 
 // EtcdServer represents the etcd component
 type EtcdServer struct {
-	CA *certificates.Certificate
+	CA            *certificates.Certificate
+	EventsEnabled bool
+	BackupPath    string
+	External      *ExternalEtcd
+	KineSQL       *KineSQLBackend
+}
+
+// KineSQLBackend represents a kine process backed by a SQL database,
+// used as an experimental alternative to a managed etcd
+type KineSQLBackend struct {
+	DataSourceName string
+}
+
+func newKineSQLBackendFromv1alpha1(kineSQL *clusterv1alpha1.KineSQLBackend) *KineSQLBackend {
+	if kineSQL == nil {
+		return nil
+	}
+	return &KineSQLBackend{
+		DataSourceName: kineSQL.DataSourceName,
+	}
+}
+
+// Export exports this kine SQL backend into a versioned kine SQL backend
+func (kineSQL *KineSQLBackend) Export() *clusterv1alpha1.KineSQLBackend {
+	if kineSQL == nil {
+		return nil
+	}
+	return &clusterv1alpha1.KineSQLBackend{
+		DataSourceName: kineSQL.DataSourceName,
+	}
+}
+
+// ExternalEtcd represents an etcd cluster external to oneinfra that
+// the control plane should use instead of a managed one
+type ExternalEtcd struct {
+	Endpoints         []string
+	ClientCertificate *certificates.Certificate
+	CA                string
+}
+
+func newExternalEtcdFromv1alpha1(externalEtcd *clusterv1alpha1.ExternalEtcd) *ExternalEtcd {
+	if externalEtcd == nil {
+		return nil
+	}
+	return &ExternalEtcd{
+		Endpoints:         externalEtcd.Endpoints,
+		ClientCertificate: certificates.NewCertificateFromv1alpha1(externalEtcd.ClientCertificate),
+		CA:                externalEtcd.CA,
+	}
+}
+
+// Export exports this external etcd into a versioned external etcd
+func (externalEtcd *ExternalEtcd) Export() *clusterv1alpha1.ExternalEtcd {
+	if externalEtcd == nil {
+		return nil
+	}
+	return &clusterv1alpha1.ExternalEtcd{
+		Endpoints:         externalEtcd.Endpoints,
+		ClientCertificate: externalEtcd.ClientCertificate.Export(),
+		CA:                externalEtcd.CA,
+	}
 }
 
 func newEtcdServer() (*EtcdServer, error) {
@@ -38,7 +98,11 @@ func newEtcdServer() (*EtcdServer, error) {
 
 func newEtcdServerFromv1alpha1(etcdServer *clusterv1alpha1.EtcdServer) *EtcdServer {
 	return &EtcdServer{
-		CA: certificates.NewCertificateFromv1alpha1(etcdServer.CA),
+		CA:            certificates.NewCertificateFromv1alpha1(etcdServer.CA),
+		EventsEnabled: etcdServer.EventsEnabled,
+		BackupPath:    etcdServer.BackupPath,
+		External:      newExternalEtcdFromv1alpha1(etcdServer.External),
+		KineSQL:       newKineSQLBackendFromv1alpha1(etcdServer.KineSQL),
 	}
 }
 
@@ -48,6 +112,10 @@ func (etcdServer *EtcdServer) Export() *clusterv1alpha1.EtcdServer {
 		return nil
 	}
 	return &clusterv1alpha1.EtcdServer{
-		CA: etcdServer.CA.Export(),
+		CA:            etcdServer.CA.Export(),
+		EventsEnabled: etcdServer.EventsEnabled,
+		BackupPath:    etcdServer.BackupPath,
+		External:      etcdServer.External.Export(),
+		KineSQL:       etcdServer.KineSQL.Export(),
 	}
 }
@@ -0,0 +1,83 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"time"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+// FreezeWindow represents a fleet-wide freeze window during which
+// controllers skip automated disruptive operations, except on
+// clusters it exempts
+type FreezeWindow struct {
+	Name           string
+	StartTime      time.Time
+	EndTime        time.Time
+	ExemptClusters map[string]map[string]bool
+}
+
+// FreezeWindowList represents a list of freeze windows
+type FreezeWindowList []*FreezeWindow
+
+// NewFreezeWindowFromv1alpha1 returns a freeze window based on a
+// versioned freeze window
+func NewFreezeWindowFromv1alpha1(freezeWindow *clusterv1alpha1.FreezeWindow) (*FreezeWindow, error) {
+	exemptClusters := map[string]map[string]bool{}
+	for _, exemptCluster := range freezeWindow.Spec.ExemptClusters {
+		if _, exists := exemptClusters[exemptCluster.Namespace]; !exists {
+			exemptClusters[exemptCluster.Namespace] = map[string]bool{}
+		}
+		exemptClusters[exemptCluster.Namespace][exemptCluster.Name] = true
+	}
+	return &FreezeWindow{
+		Name:           freezeWindow.Name,
+		StartTime:      freezeWindow.Spec.StartTime.Time,
+		EndTime:        freezeWindow.Spec.EndTime.Time,
+		ExemptClusters: exemptClusters,
+	}, nil
+}
+
+// active returns whether now falls within this freeze window
+func (freezeWindow *FreezeWindow) active(now time.Time) bool {
+	return now.After(freezeWindow.StartTime) && now.Before(freezeWindow.EndTime)
+}
+
+// exempts returns whether this freeze window exempts the cluster
+// named clusterName in clusterNamespace
+func (freezeWindow *FreezeWindow) exempts(clusterNamespace, clusterName string) bool {
+	return freezeWindow.ExemptClusters[clusterNamespace][clusterName]
+}
+
+// Blocks returns whether automated disruptive operations on the
+// cluster named clusterName in clusterNamespace should be skipped
+// right now, because at least one active freeze window in the list
+// does not exempt it
+func (freezeWindowList FreezeWindowList) Blocks(clusterNamespace, clusterName string) bool {
+	now := time.Now()
+	for _, freezeWindow := range freezeWindowList {
+		if !freezeWindow.active(now) {
+			continue
+		}
+		if freezeWindow.exempts(clusterNamespace, clusterName) {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+// MaxClusterHistoryEntries is the maximum number of history entries
+// retained per cluster; recording a new entry past this limit drops
+// the oldest one
+const MaxClusterHistoryEntries = 50
+
+// ClusterHistoryEntry represents a single lifecycle operation
+// recorded for a cluster
+type ClusterHistoryEntry struct {
+	Timestamp metav1.Time
+	Operation string
+	Outcome   string
+	Message   string
+}
+
+// ClusterHistoryEntryList represents a list of cluster history
+// entries
+type ClusterHistoryEntryList []ClusterHistoryEntry
+
+// NewClusterHistoryEntryListFromv1alpha1 creates a cluster history
+// entry list from a versioned one
+func NewClusterHistoryEntryListFromv1alpha1(history []clusterv1alpha1.ClusterHistoryEntry) ClusterHistoryEntryList {
+	res := ClusterHistoryEntryList{}
+	for _, entry := range history {
+		res = append(res, ClusterHistoryEntry{
+			Timestamp: entry.Timestamp,
+			Operation: entry.Operation,
+			Outcome:   entry.Outcome,
+			Message:   entry.Message,
+		})
+	}
+	return res
+}
+
+// Export exports the cluster history entry list to a versioned object
+func (history ClusterHistoryEntryList) Export() []clusterv1alpha1.ClusterHistoryEntry {
+	res := []clusterv1alpha1.ClusterHistoryEntry{}
+	for _, entry := range history {
+		res = append(res, clusterv1alpha1.ClusterHistoryEntry{
+			Timestamp: entry.Timestamp,
+			Operation: entry.Operation,
+			Outcome:   entry.Outcome,
+			Message:   entry.Message,
+		})
+	}
+	return res
+}
+
+// RecordHistory appends a new entry to this cluster's history,
+// dropping the oldest entry once MaxClusterHistoryEntries is exceeded
+func (cluster *Cluster) RecordHistory(operation, outcome, message string) {
+	cluster.History = append(cluster.History, ClusterHistoryEntry{
+		Timestamp: metav1.Now(),
+		Operation: operation,
+		Outcome:   outcome,
+		Message:   message,
+	})
+	if len(cluster.History) > MaxClusterHistoryEntries {
+		cluster.History = cluster.History[len(cluster.History)-MaxClusterHistoryEntries:]
+	}
+}
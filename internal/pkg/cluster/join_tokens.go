@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -31,6 +32,7 @@ import (
 	utiltokens "k8s.io/cluster-bootstrap/util/tokens"
 	"k8s.io/klog/v2"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	"github.com/oneinfra/oneinfra/pkg/constants"
 )
 
@@ -40,7 +42,6 @@ func (cluster *Cluster) ReconcileJoinTokens() error {
 	if err != nil {
 		return err
 	}
-	cluster.CurrentJoinTokens = []string{}
 	secretList, err := client.CoreV1().Secrets(metav1.NamespaceSystem).List(
 		context.TODO(),
 		metav1.ListOptions{
@@ -50,42 +51,125 @@ func (cluster *Cluster) ReconcileJoinTokens() error {
 	if err != nil {
 		return err
 	}
-	for _, secret := range secretList.Items {
-		cluster.CurrentJoinTokens = append(
-			cluster.CurrentJoinTokens,
-			tokenFromSecret(&secret),
-		)
-	}
+	cluster.CurrentJoinTokens = cluster.joinTokenStatuses(client, secretList.Items)
 	tokensSuccessfullyReconciled := true
+	if err := cluster.removeRevokedExpiredAndExhaustedTokens(client, secretList.Items); err != nil {
+		tokensSuccessfullyReconciled = false
+	}
 	if err := cluster.createNewTokens(client); err != nil {
 		tokensSuccessfullyReconciled = false
 	}
-	if err := cluster.removeExcessTokens(client); err != nil {
+	if err := cluster.removeExcessTokens(client, secretList.Items); err != nil {
 		tokensSuccessfullyReconciled = false
 	}
 	if tokensSuccessfullyReconciled {
-		cluster.CurrentJoinTokens = cluster.DesiredJoinTokens
 		return nil
 	}
 	return errors.New("some join tokens could not be successfully reconciled")
 }
 
-func (cluster *Cluster) newTokens() []string {
-	return substractTokens(cluster.DesiredJoinTokens, cluster.CurrentJoinTokens)
+// joinTokenStatuses builds the observed status for every live
+// bootstrap token Secret, including its expiry and, for tokens with a
+// UsageLimit set in the desired spec, how many uses remain
+func (cluster *Cluster) joinTokenStatuses(client clientset.Interface, secrets []corev1.Secret) []clusterv1alpha1.JoinTokenStatus {
+	desiredByToken := map[string]clusterv1alpha1.JoinToken{}
+	for _, joinToken := range cluster.DesiredJoinTokens {
+		desiredByToken[joinToken.Token] = joinToken
+	}
+	res := []clusterv1alpha1.JoinTokenStatus{}
+	for _, secret := range secrets {
+		token := tokenFromSecret(&secret)
+		status := clusterv1alpha1.JoinTokenStatus{
+			Token:         token,
+			UsesRemaining: -1,
+		}
+		if expiresAt, ok := tokenExpiration(&secret); ok {
+			metaExpiresAt := metav1.NewTime(expiresAt)
+			status.ExpiresAt = &metaExpiresAt
+		}
+		if joinToken, exists := desiredByToken[token]; exists && joinToken.UsageLimit > 0 {
+			tokenID, _, err := utiltokens.ParseToken(token)
+			if err != nil {
+				res = append(res, status)
+				continue
+			}
+			uses, err := bootstrapCSRCount(client, tokenID)
+			if err != nil {
+				klog.Warningf("could not count bootstrap uses for join token %q in cluster %q: %v", tokenID, cluster.Name, err)
+				res = append(res, status)
+				continue
+			}
+			status.UsesRemaining = joinToken.UsageLimit - uses
+		}
+		res = append(res, status)
+	}
+	return res
 }
 
-func (cluster *Cluster) excessTokens() []string {
-	return substractTokens(cluster.CurrentJoinTokens, cluster.DesiredJoinTokens)
+// bootstrapCSRCount returns how many CertificateSigningRequests were
+// submitted under the bootstrap identity minted for tokenID, used as
+// a proxy for how many times that token has already been consumed
+func bootstrapCSRCount(client clientset.Interface, tokenID string) (int, error) {
+	csrList, err := client.CertificatesV1beta1().CertificateSigningRequests().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return 0, err
+	}
+	bootstrapUsername := fmt.Sprintf("system:bootstrap:%s", tokenID)
+	uses := 0
+	for _, csr := range csrList.Items {
+		if csr.Spec.Username == bootstrapUsername {
+			uses++
+		}
+	}
+	return uses, nil
+}
+
+// tokenExpiration returns the expiration time encoded in secret's
+// BootstrapTokenExpirationKey data, if present and parseable
+func tokenExpiration(secret *corev1.Secret) (time.Time, bool) {
+	expiration := utilsecrets.GetData(secret, tokenapi.BootstrapTokenExpirationKey)
+	if expiration == "" {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, expiration)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+func (cluster *Cluster) newTokens(existing map[string]bool) []clusterv1alpha1.JoinToken {
+	res := []clusterv1alpha1.JoinToken{}
+	for _, joinToken := range cluster.DesiredJoinTokens {
+		if !existing[joinToken.Token] {
+			res = append(res, joinToken)
+		}
+	}
+	return res
 }
 
 func (cluster *Cluster) createNewTokens(client clientset.Interface) error {
+	existing := map[string]bool{}
+	for _, joinToken := range cluster.CurrentJoinTokens {
+		existing[joinToken.Token] = true
+	}
 	allSucceeded := true
-	for _, newToken := range cluster.newTokens() {
-		tokenID, tokenSecret, err := utiltokens.ParseToken(newToken)
+	for _, newToken := range cluster.newTokens(existing) {
+		tokenID, tokenSecret, err := utiltokens.ParseToken(newToken.Token)
 		if err != nil {
 			allSucceeded = false
 			continue
 		}
+		stringData := map[string]string{
+			tokenapi.BootstrapTokenDescriptionKey:      "oneinfra node join token",
+			tokenapi.BootstrapTokenIDKey:               tokenID,
+			tokenapi.BootstrapTokenSecretKey:           tokenSecret,
+			tokenapi.BootstrapTokenUsageAuthentication: "true",
+			tokenapi.BootstrapTokenExtraGroupsKey:      constants.OneInfraNodeJoinTokenExtraGroups,
+		}
+		if newToken.TTL != nil {
+			stringData[tokenapi.BootstrapTokenExpirationKey] = time.Now().Add(newToken.TTL.Duration).UTC().Format(time.RFC3339)
+		}
 		tokenSecretName := tokenutil.BootstrapTokenSecretName(tokenID)
 		_, err = client.CoreV1().Secrets(metav1.NamespaceSystem).Create(
 			context.TODO(),
@@ -94,14 +178,8 @@ func (cluster *Cluster) createNewTokens(client clientset.Interface) error {
 					Name:      tokenSecretName,
 					Namespace: metav1.NamespaceSystem,
 				},
-				StringData: map[string]string{
-					tokenapi.BootstrapTokenDescriptionKey:      "oneinfra node join token",
-					tokenapi.BootstrapTokenIDKey:               tokenID,
-					tokenapi.BootstrapTokenSecretKey:           tokenSecret,
-					tokenapi.BootstrapTokenUsageAuthentication: "true",
-					tokenapi.BootstrapTokenExtraGroupsKey:      constants.OneInfraNodeJoinTokenExtraGroups,
-				},
-				Type: corev1.SecretTypeBootstrapToken,
+				StringData: stringData,
+				Type:       corev1.SecretTypeBootstrapToken,
 			},
 			metav1.CreateOptions{},
 		)
@@ -117,23 +195,20 @@ func (cluster *Cluster) createNewTokens(client clientset.Interface) error {
 	return errors.New("not all new join tokens could be reconciled")
 }
 
-func (cluster *Cluster) removeExcessTokens(client clientset.Interface) error {
+func (cluster *Cluster) removeExcessTokens(client clientset.Interface, secrets []corev1.Secret) error {
+	desired := map[string]bool{}
+	for _, joinToken := range cluster.DesiredJoinTokens {
+		desired[joinToken.Token] = true
+	}
 	allSucceeded := true
-	for _, excessToken := range cluster.excessTokens() {
-		tokenID, _, err := utiltokens.ParseToken(excessToken)
-		if err != nil {
-			allSucceeded = false
+	for _, secret := range secrets {
+		token := tokenFromSecret(&secret)
+		if desired[token] {
 			continue
 		}
-		tokenSecretName := tokenutil.BootstrapTokenSecretName(tokenID)
-		err = client.CoreV1().Secrets(metav1.NamespaceSystem).Delete(
-			context.TODO(),
-			tokenSecretName,
-			metav1.DeleteOptions{},
-		)
-		if err != nil {
+		if err := deleteTokenSecret(client, &secret); err != nil {
 			allSucceeded = false
-			klog.Warningf("could not delete excess join token %q in cluster %q: %v", tokenSecretName, cluster.Name, err)
+			klog.Warningf("could not delete excess join token %q in cluster %q: %v", secret.Name, cluster.Name, err)
 		}
 	}
 	if allSucceeded {
@@ -142,18 +217,67 @@ func (cluster *Cluster) removeExcessTokens(client clientset.Interface) error {
 	return errors.New("not all excess join tokens could be deleted")
 }
 
-func substractTokens(list []string, listToSubstract []string) []string {
-	res := []string{}
-	toSubstract := map[string]struct{}{}
-	for _, token := range listToSubstract {
-		toSubstract[token] = struct{}{}
-	}
-	for _, token := range list {
-		if _, exists := toSubstract[token]; !exists {
-			res = append(res, token)
+// removeRevokedExpiredAndExhaustedTokens deletes the backing Secret of
+// every desired token marked Revoked, of every live token whose TTL
+// has already elapsed, and of every token whose UsageLimit has been
+// reached, instead of relying solely on the upstream bootstrap token
+// controller to garbage collect it
+func (cluster *Cluster) removeRevokedExpiredAndExhaustedTokens(client clientset.Interface, secrets []corev1.Secret) error {
+	revoked := map[string]bool{}
+	for _, joinToken := range cluster.DesiredJoinTokens {
+		if joinToken.Revoked {
+			revoked[joinToken.Token] = true
 		}
 	}
-	return res
+	exhausted := cluster.exhaustedTokens()
+	now := time.Now()
+	allSucceeded := true
+	for _, secret := range secrets {
+		token := tokenFromSecret(&secret)
+		expiresAt, hasExpiration := tokenExpiration(&secret)
+		expired := hasExpiration && expiresAt.Before(now)
+		if !revoked[token] && !expired && !exhausted[token] {
+			continue
+		}
+		if err := deleteTokenSecret(client, &secret); err != nil {
+			allSucceeded = false
+			klog.Warningf("could not delete revoked, expired or exhausted join token %q in cluster %q: %v", secret.Name, cluster.Name, err)
+		}
+	}
+	if allSucceeded {
+		return nil
+	}
+	return errors.New("not all revoked, expired or exhausted join tokens could be deleted")
+}
+
+// exhaustedTokens returns the set of desired tokens, identified by
+// their token value, that have a UsageLimit set and have already
+// reached it, based on cluster.CurrentJoinTokens. Gated on the
+// desired JoinToken's UsageLimit, rather than solely on
+// JoinTokenStatus.UsesRemaining, since UsesRemaining also uses -1 to
+// mean "no limit set", which a token that overshot its limit could
+// otherwise be confused with
+func (cluster *Cluster) exhaustedTokens() map[string]bool {
+	desiredByToken := map[string]clusterv1alpha1.JoinToken{}
+	for _, joinToken := range cluster.DesiredJoinTokens {
+		desiredByToken[joinToken.Token] = joinToken
+	}
+	exhausted := map[string]bool{}
+	for _, status := range cluster.CurrentJoinTokens {
+		joinToken, isDesired := desiredByToken[status.Token]
+		if isDesired && joinToken.UsageLimit > 0 && status.UsesRemaining <= 0 {
+			exhausted[status.Token] = true
+		}
+	}
+	return exhausted
+}
+
+func deleteTokenSecret(client clientset.Interface, secret *corev1.Secret) error {
+	return client.CoreV1().Secrets(metav1.NamespaceSystem).Delete(
+		context.TODO(),
+		secret.Name,
+		metav1.DeleteOptions{},
+	)
 }
 
 func tokenFromSecret(secret *corev1.Secret) string {
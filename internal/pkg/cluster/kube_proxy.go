@@ -30,6 +30,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	clientset "k8s.io/client-go/kubernetes"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+	releasecomponents "github.com/oneinfra/oneinfra/internal/pkg/release-components"
 )
 
 const (
@@ -57,6 +60,14 @@ func (cluster *Cluster) ReconcileKubeProxy() error {
 	trueVar := true
 	hostPathFileOrCreateVar := corev1.HostPathFileOrCreate
 	maxUnavailable := intstr.FromString("10%")
+	kubeProxyVersion, err := constants.KubernetesComponentVersion(cluster.KubernetesVersion, releasecomponents.KubeProxy)
+	if err != nil {
+		return err
+	}
+	kubeProxyImage, err := cluster.addonImage("kube-proxy", fmt.Sprintf(kubeProxyImage, kubeProxyVersion))
+	if err != nil {
+		return err
+	}
 	_, err = client.AppsV1().DaemonSets(metav1.NamespaceSystem).Create(
 		context.TODO(),
 		&appsv1.DaemonSet{
@@ -92,7 +103,7 @@ func (cluster *Cluster) ReconcileKubeProxy() error {
 						Containers: []corev1.Container{
 							{
 								Name:    "kube-proxy",
-								Image:   fmt.Sprintf(kubeProxyImage, cluster.KubernetesVersion),
+								Image:   kubeProxyImage,
 								Command: []string{"/bin/sh"},
 								Args:    []string{"-c", "kube-proxy"},
 								Env: []corev1.EnvVar{
@@ -168,8 +179,29 @@ func (cluster *Cluster) ReconcileKubeProxy() error {
 		metav1.CreateOptions{},
 	)
 	if err != nil && apierrors.IsAlreadyExists(err) {
+		return cluster.upgradeKubeProxyDaemonSetImage(client, kubeProxyImage)
+	}
+	return err
+}
+
+// upgradeKubeProxyDaemonSetImage updates the already reconciled
+// kube-proxy daemonset to kubeProxyImage when it has drifted from it
+// and "kube-proxy" is not a FrozenAddon, so cluster upgrades also
+// roll kube-proxy forward to the version pinned for the new
+// Kubernetes version
+func (cluster *Cluster) upgradeKubeProxyDaemonSetImage(client clientset.Interface, kubeProxyImage string) error {
+	if !cluster.addonUpgradeAllowed("kube-proxy") {
+		return nil
+	}
+	daemonSet, err := client.AppsV1().DaemonSets(metav1.NamespaceSystem).Get(context.TODO(), "kube-proxy", metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if len(daemonSet.Spec.Template.Spec.Containers) == 0 || daemonSet.Spec.Template.Spec.Containers[0].Image == kubeProxyImage {
 		return nil
 	}
+	daemonSet.Spec.Template.Spec.Containers[0].Image = kubeProxyImage
+	_, err = client.AppsV1().DaemonSets(metav1.NamespaceSystem).Update(context.TODO(), daemonSet, metav1.UpdateOptions{})
 	return err
 }
 
@@ -17,6 +17,9 @@
 package cluster
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/pkg/errors"
 
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,8 +29,28 @@ import (
 	"github.com/oneinfra/oneinfra/pkg/constants"
 )
 
+// kubeletConfigCache memoizes rendered kubelet configs keyed by
+// cluster and generation, so reconciling a cluster with many pending
+// node join requests renders the (identical) kubelet config for that
+// generation only once
+var kubeletConfigCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func kubeletConfigCacheKey(cluster *Cluster) string {
+	return fmt.Sprintf("%s/%s@%d", cluster.Namespace, cluster.Name, cluster.Generation)
+}
+
 // KubeletConfig returns a default kubelet config
 func (cluster *Cluster) KubeletConfig() (string, error) {
+	cacheKey := kubeletConfigCacheKey(cluster)
+	kubeletConfigCache.mu.Lock()
+	if cached, exists := kubeletConfigCache.entries[cacheKey]; exists {
+		kubeletConfigCache.mu.Unlock()
+		return cached, nil
+	}
+	kubeletConfigCache.mu.Unlock()
 	coreDNSServiceIP, err := cluster.CoreDNSServiceIP()
 	if err != nil {
 		return "", err
@@ -43,7 +66,17 @@ func (cluster *Cluster) KubeletConfig() (string, error) {
 			},
 		},
 	}
-	return marshalKubeletConfig(&kubeletConfig)
+	rendered, err := marshalKubeletConfig(&kubeletConfig)
+	if err != nil {
+		return "", err
+	}
+	kubeletConfigCache.mu.Lock()
+	if kubeletConfigCache.entries == nil {
+		kubeletConfigCache.entries = map[string]string{}
+	}
+	kubeletConfigCache.entries[cacheKey] = rendered
+	kubeletConfigCache.mu.Unlock()
+	return rendered, nil
 }
 
 func marshalKubeletConfig(kubeletConfig *kubeletconfigv1beta1.KubeletConfiguration) (string, error) {
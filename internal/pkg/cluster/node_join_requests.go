@@ -18,16 +18,97 @@ package cluster
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	nodev1alpha1 "github.com/oneinfra/oneinfra/apis/node/v1alpha1"
 	"github.com/oneinfra/oneinfra/internal/pkg/conditions"
 	nodejoinrequests "github.com/oneinfra/oneinfra/internal/pkg/node-join-requests"
 )
 
+// workerVPNPeerName returns the VPN peer name minted for the worker
+// node backed by the node join request named nodeJoinRequestName
+func workerVPNPeerName(nodeJoinRequestName string) string {
+	return fmt.Sprintf("worker-%s", nodeJoinRequestName)
+}
+
+// joinEndpointsFingerprint returns a fingerprint of the cluster state
+// that feeds a node join request status (apiserver endpoint, VPN
+// settings and CA bundles), so a previously issued join request can be
+// detected as stale when any of them change
+func (cluster *Cluster) joinEndpointsFingerprint() (string, error) {
+	fingerprinted := struct {
+		KubernetesVersion string
+		ImageRegistry     string
+		APIServerEndpoint string
+		VPNEnabled        bool
+		VPNCIDR           string
+		VPNPublicKey      string
+		VPNServerEndpoint string
+		KubeletCA         string
+		KubeletClientCA   string
+	}{
+		KubernetesVersion: cluster.KubernetesVersion,
+		ImageRegistry:     cluster.ImageRegistry,
+		APIServerEndpoint: cluster.APIServerEndpoint,
+	}
+	if cluster.VPN != nil {
+		fingerprinted.VPNEnabled = cluster.VPN.Enabled
+		fingerprinted.VPNPublicKey = cluster.VPN.PublicKey
+		if cluster.VPN.CIDR != nil {
+			fingerprinted.VPNCIDR = cluster.VPN.CIDR.String()
+		}
+	}
+	if fingerprinted.VPNEnabled {
+		fingerprinted.VPNServerEndpoint = cluster.VPNServerEndpoint
+	}
+	if cluster.CertificateAuthorities != nil {
+		if cluster.CertificateAuthorities.Kubelet != nil {
+			fingerprinted.KubeletCA = cluster.CertificateAuthorities.Kubelet.Certificate
+		}
+		if cluster.CertificateAuthorities.KubeletClient != nil {
+			fingerprinted.KubeletClientCA = cluster.CertificateAuthorities.KubeletClient.Certificate
+		}
+	}
+	marshaled, err := json.Marshal(fingerprinted)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha1.Sum(marshaled)), nil
+}
+
+// reconcileDeprovisionedWorkerVPNPeers releases the VPN peer minted
+// for each worker whose node join request no longer exists, so a
+// deprovisioned node's address and key material don't linger forever
+func (cluster *Cluster) reconcileDeprovisionedWorkerVPNPeers(nodeJoinRequestList *nodev1alpha1.NodeJoinRequestList) {
+	if cluster.VPN == nil || !cluster.VPN.Enabled {
+		return
+	}
+	liveNodeJoinRequests := map[string]bool{}
+	for _, nodeJoinRequest := range nodeJoinRequestList.Items {
+		liveNodeJoinRequests[nodeJoinRequest.Name] = true
+	}
+	for peerName, peer := range cluster.VPNPeers {
+		if peer.Purpose != clusterv1alpha1.VPNPeerPurposeWorker {
+			continue
+		}
+		nodeJoinRequestName := strings.TrimPrefix(peerName, "worker-")
+		if liveNodeJoinRequests[nodeJoinRequestName] {
+			continue
+		}
+		klog.Infof("node join request %q for cluster %q no longer exists, releasing its VPN peer", nodeJoinRequestName, cluster.Name)
+		if err := cluster.DeleteVPNPeer(peerName); err != nil {
+			klog.Errorf("could not release VPN peer %q for cluster %q: %v", peerName, cluster.Name, err)
+		}
+	}
+}
+
 // ReconcileNodeJoinRequests reconciles this cluster node join requests
 func (cluster *Cluster) ReconcileNodeJoinRequests() error {
 	scheme := runtime.NewScheme()
@@ -47,19 +128,33 @@ func (cluster *Cluster) ReconcileNodeJoinRequests() error {
 	if err != nil {
 		return err
 	}
+	cluster.reconcileDeprovisionedWorkerVPNPeers(&nodeJoinRequestList)
+	joinEndpointsFingerprint, err := cluster.joinEndpointsFingerprint()
+	if err != nil {
+		return err
+	}
+	joinKeyProvider := cluster.JoinKeyProvider()
 	for _, versionedNodeJoinRequest := range nodeJoinRequestList.Items {
-		nodeJoinRequest, err := nodejoinrequests.NewNodeJoinRequestFromv1alpha1(&versionedNodeJoinRequest, cluster.JoinKey)
+		nodeJoinRequest, err := nodejoinrequests.NewNodeJoinRequestFromv1alpha1(&versionedNodeJoinRequest, joinKeyProvider)
 		if err != nil {
 			klog.Errorf("cannot parse node join request %q public key: %v", versionedNodeJoinRequest.Name, err)
 			continue
 		}
-		if nodeJoinRequest.Conditions.IsCondition(nodejoinrequests.Issued, conditions.ConditionTrue) {
+		alreadyIssued := nodeJoinRequest.Conditions.IsCondition(nodejoinrequests.Issued, conditions.ConditionTrue)
+		if alreadyIssued && nodeJoinRequest.SourceFingerprint == joinEndpointsFingerprint {
 			continue
 		}
+		if alreadyIssued {
+			klog.Infof("cluster endpoints changed, re-issuing node join request %q", nodeJoinRequest.Name)
+		}
 		if err := cluster.fillNodeJoinRequestKubernetesVersion(nodeJoinRequest); err != nil {
 			klog.Errorf("cannot fill Kubernetes version for node join request %q: %v", nodeJoinRequest.Name, err)
 			continue
 		}
+		if err := cluster.fillNodeJoinRequestImageRegistry(nodeJoinRequest); err != nil {
+			klog.Errorf("cannot fill image registry for node join request %q: %v", nodeJoinRequest.Name, err)
+			continue
+		}
 		if err := cluster.fillNodeJoinRequestVPNAddressAndPeers(nodeJoinRequest); err != nil {
 			klog.Errorf("cannot fill VPN address and peers for node join request %q: %v", nodeJoinRequest.Name, err)
 			continue
@@ -80,6 +175,16 @@ func (cluster *Cluster) ReconcileNodeJoinRequests() error {
 			klog.Errorf("cannot fill kubelet client CA certificate for node join request %q: %v", nodeJoinRequest.Name, err)
 			continue
 		}
+		if err := cluster.fillNodeJoinRequestClusterCACertificate(nodeJoinRequest); err != nil {
+			klog.Errorf("cannot fill cluster CA certificate for node join request %q: %v", nodeJoinRequest.Name, err)
+			continue
+		}
+		nodeJoinRequest.SourceFingerprint = joinEndpointsFingerprint
+		nodeJoinRequest.CipherSuite = string(cluster.effectiveJoinKeyCipherSuite())
+		if err := cluster.signNodeJoinRequest(nodeJoinRequest); err != nil {
+			klog.Errorf("cannot sign node join request %q: %v", nodeJoinRequest.Name, err)
+			continue
+		}
 		nodeJoinRequest.Conditions.SetCondition(nodejoinrequests.Issued, conditions.ConditionTrue)
 		versionedNodeJoinRequest, err := nodeJoinRequest.Export()
 		if err != nil {
@@ -109,6 +214,18 @@ func (cluster *Cluster) fillNodeJoinRequestKubernetesVersion(nodeJoinRequest *no
 	return nil
 }
 
+func (cluster *Cluster) fillNodeJoinRequestImageRegistry(nodeJoinRequest *nodejoinrequests.NodeJoinRequest) error {
+	if cluster.ImageRegistry == "" {
+		return nil
+	}
+	imageRegistry, err := nodeJoinRequest.Encrypt(cluster.ImageRegistry)
+	if err != nil {
+		return err
+	}
+	nodeJoinRequest.ImageRegistry = imageRegistry
+	return nil
+}
+
 func (cluster *Cluster) fillNodeJoinRequestVPNAddressAndPeers(nodeJoinRequest *nodejoinrequests.NodeJoinRequest) error {
 	if cluster.VPN == nil || !cluster.VPN.Enabled {
 		return nil
@@ -119,7 +236,7 @@ func (cluster *Cluster) fillNodeJoinRequestVPNAddressAndPeers(nodeJoinRequest *n
 		return err
 	}
 	nodeJoinRequest.VPN.CIDR = vpnCIDR
-	vpnPeer, err := cluster.GenerateVPNPeer(fmt.Sprintf("worker-%s", nodeJoinRequest.Name))
+	vpnPeer, err := cluster.GenerateVPNPeer(workerVPNPeerName(nodeJoinRequest.Name), clusterv1alpha1.VPNPeerPurposeWorker, nil)
 	if err != nil {
 		return err
 	}
@@ -143,6 +260,11 @@ func (cluster *Cluster) fillNodeJoinRequestVPNAddressAndPeers(nodeJoinRequest *n
 		return err
 	}
 	nodeJoinRequest.VPN.EndpointPublicKey = endpointPublicKey
+	keepaliveSeconds := cluster.VPN.KeepaliveSeconds
+	if keepaliveSeconds == 0 {
+		keepaliveSeconds = wireguardDefaultKeepaliveSeconds
+	}
+	nodeJoinRequest.VPN.KeepaliveSeconds = keepaliveSeconds
 	return nil
 }
 
@@ -231,3 +353,33 @@ func (cluster *Cluster) fillNodeJoinRequestKubeletClientCACertificate(nodeJoinRe
 	nodeJoinRequest.KubeletClientCACertificate = kubeletClientCACertificate
 	return nil
 }
+
+// fillNodeJoinRequestClusterCACertificate fills in the certificate
+// authority that signed the apiserver serving certificate embedded in
+// KubeConfig, explicitly, so node-side components can verify the
+// apiserver against exactly this authority instead of relying on the
+// one implicitly embedded in KubeConfig
+func (cluster *Cluster) fillNodeJoinRequestClusterCACertificate(nodeJoinRequest *nodejoinrequests.NodeJoinRequest) error {
+	clusterCACertificate, err := nodeJoinRequest.Encrypt(cluster.APIServer.CA.Certificate)
+	if err != nil {
+		return err
+	}
+	nodeJoinRequest.ClusterCACertificate = clusterCACertificate
+	return nil
+}
+
+// signNodeJoinRequest signs the status fields already filled in
+// nodeJoinRequest with this cluster's signing key, so a joining node
+// can verify they were produced by this management plane and were
+// not tampered with in transit or at rest
+func (cluster *Cluster) signNodeJoinRequest(nodeJoinRequest *nodejoinrequests.NodeJoinRequest) error {
+	if cluster.SigningKey == nil {
+		return nil
+	}
+	payload, err := nodeJoinRequest.SignaturePayload()
+	if err != nil {
+		return err
+	}
+	nodeJoinRequest.Signature = cluster.SigningKey.Sign(payload)
+	return nil
+}
@@ -0,0 +1,165 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	nodev1alpha1 "github.com/oneinfra/oneinfra/apis/node/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/conditions"
+)
+
+// ReconcileNodeLeaveRequests reconciles this cluster node leave
+// requests, decommissioning the worker named by each one: draining
+// it of its pods through the managed apiserver, deleting its Node
+// object, and releasing its VPN peer. A NodeLeaveRequest is named
+// after the node it decommissions, the same convention
+// NodeJoinRequest uses for the node it is issued for.
+//
+// Revoking the worker's kubelet certificate is not performed: this
+// cluster's certificate authorities have no certificate revocation
+// list, so a kubelet client certificate cannot be individually
+// invalidated before it expires. Deleting the Node object and, when
+// VPN is enabled, releasing its VPN peer is the extent of the access
+// this management plane can actually withdraw
+func (cluster *Cluster) ReconcileNodeLeaveRequests() error {
+	scheme := runtime.NewScheme()
+	if err := nodev1alpha1.AddToScheme(scheme); err != nil {
+		return err
+	}
+	restClient, err := cluster.RESTClient(&nodev1alpha1.GroupVersion, scheme)
+	if err != nil {
+		return err
+	}
+	nodeLeaveRequestList := nodev1alpha1.NodeLeaveRequestList{}
+	err = restClient.
+		Get().
+		Resource("nodeleaverequests").
+		Do(context.TODO()).
+		Into(&nodeLeaveRequestList)
+	if err != nil {
+		return err
+	}
+	kubernetesClient, err := cluster.KubernetesClient()
+	if err != nil {
+		return err
+	}
+	for _, nodeLeaveRequest := range nodeLeaveRequestList.Items {
+		conditionList := conditions.NewConditionListFromv1alpha1(nodeLeaveRequest.Status.Conditions)
+		if conditionList.IsCondition(conditions.ConditionType(nodev1alpha1.Completed), conditions.ConditionTrue) {
+			continue
+		}
+		if err := cluster.decommissionNode(kubernetesClient, nodeLeaveRequest.Name); err != nil {
+			klog.Errorf("could not decommission node %q for cluster %q: %v", nodeLeaveRequest.Name, cluster.Name, err)
+			continue
+		}
+		conditionList.SetCondition(conditions.ConditionType(nodev1alpha1.Completed), conditions.ConditionTrue)
+		nodeLeaveRequest.Status.Conditions = conditionList.Export()
+		err = restClient.
+			Put().
+			Resource("nodeleaverequests").
+			Name(nodeLeaveRequest.Name).
+			SubResource("status").
+			Body(&nodeLeaveRequest).
+			Do(context.TODO()).
+			Error()
+		if err != nil {
+			klog.Errorf("cannot update node leave request status %q: %v", nodeLeaveRequest.Name, err)
+		}
+	}
+	return nil
+}
+
+// decommissionNode drains nodeName of its evictable pods, deletes its
+// Node object, and releases its VPN peer if this cluster has VPN
+// enabled
+func (cluster *Cluster) decommissionNode(kubernetesClient clientset.Interface, nodeName string) error {
+	if err := drainNode(kubernetesClient, nodeName); err != nil {
+		return err
+	}
+	err := kubernetesClient.CoreV1().Nodes().Delete(context.TODO(), nodeName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	if cluster.VPN == nil || !cluster.VPN.Enabled {
+		return nil
+	}
+	peerName := workerVPNPeerName(nodeName)
+	if _, err := cluster.VPNPeer(peerName); err != nil {
+		return nil
+	}
+	return cluster.DeleteVPNPeer(peerName)
+}
+
+// drainNode cordons nodeName and evicts every pod scheduled on it
+// that is not owned by a DaemonSet, through the eviction subresource
+// of the managed apiserver, so PodDisruptionBudgets are honored the
+// same way `kubectl drain` honors them
+func drainNode(kubernetesClient clientset.Interface, nodeName string) error {
+	patch := []byte(`{"spec":{"unschedulable":true}}`)
+	_, err := kubernetesClient.CoreV1().Nodes().Patch(context.TODO(), nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	pods, err := kubernetesClient.CoreV1().Pods(metav1.NamespaceAll).List(
+		context.TODO(),
+		metav1.ListOptions{
+			FieldSelector: "spec.nodeName=" + nodeName,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	for _, pod := range pods.Items {
+		if isDaemonSetPod(&pod) {
+			continue
+		}
+		err := kubernetesClient.PolicyV1beta1().Evictions(pod.Namespace).Evict(
+			context.TODO(),
+			&policyv1beta1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+				},
+			},
+		)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isDaemonSetPod returns whether pod is owned by a DaemonSet, so
+// drainNode can leave it in place the way `kubectl drain` does
+func isDaemonSetPod(pod *corev1.Pod) bool {
+	for _, ownerReference := range pod.OwnerReferences {
+		if ownerReference.Kind == "DaemonSet" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"github.com/coreos/go-semver/semver"
+
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/certificates"
+	"github.com/oneinfra/oneinfra/internal/pkg/crypto"
+)
+
+// ArtifactProvenance associates a named artifact (a certificate or
+// key pair) with its provenance
+type ArtifactProvenance struct {
+	Name       string
+	Provenance *commonv1alpha1.Provenance
+}
+
+// Artifacts returns the provenance of every certificate and key pair
+// known to this cluster
+func (cluster *Cluster) Artifacts() []ArtifactProvenance {
+	artifacts := []ArtifactProvenance{}
+	addCertificate := func(name string, certificate *certificates.Certificate) {
+		if certificate == nil {
+			return
+		}
+		artifacts = append(artifacts, ArtifactProvenance{Name: name, Provenance: certificate.Provenance})
+	}
+	addKeyPair := func(name string, keyPair *crypto.KeyPair) {
+		if keyPair == nil {
+			return
+		}
+		artifacts = append(artifacts, ArtifactProvenance{Name: name, Provenance: keyPair.Provenance})
+	}
+	if cluster.CertificateAuthorities != nil {
+		addCertificate("certificate-authorities/apiserver-client", cluster.CertificateAuthorities.APIServerClient)
+		addCertificate("certificate-authorities/certificate-signer", cluster.CertificateAuthorities.CertificateSigner)
+		addCertificate("certificate-authorities/kubelet", cluster.CertificateAuthorities.Kubelet)
+		addCertificate("certificate-authorities/kubelet-client", cluster.CertificateAuthorities.KubeletClient)
+		addCertificate("certificate-authorities/etcd-client", cluster.CertificateAuthorities.EtcdClient)
+		addCertificate("certificate-authorities/etcd-peer", cluster.CertificateAuthorities.EtcdPeer)
+	}
+	if cluster.EtcdServer != nil {
+		addCertificate("etcd-server/ca", cluster.EtcdServer.CA)
+	}
+	if cluster.APIServer != nil {
+		addCertificate("apiserver/ca", cluster.APIServer.CA)
+		addKeyPair("apiserver/service-account", cluster.APIServer.ServiceAccount)
+	}
+	addKeyPair("join-key", cluster.JoinKey)
+	if cluster.JoinBoxKey != nil {
+		artifacts = append(artifacts, ArtifactProvenance{Name: "join-box-key", Provenance: cluster.JoinBoxKey.Provenance})
+	}
+	return artifacts
+}
+
+// ArtifactsOlderThan returns the names of the artifacts generated by
+// a oneinfra version older than version, or with no recorded
+// provenance at all, so they can be targeted for regeneration
+func (cluster *Cluster) ArtifactsOlderThan(version string) ([]string, error) {
+	threshold, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	staleArtifacts := []string{}
+	for _, artifact := range cluster.Artifacts() {
+		if artifact.Provenance == nil || artifact.Provenance.GeneratorVersion == "" {
+			staleArtifacts = append(staleArtifacts, artifact.Name)
+			continue
+		}
+		generatorVersion, err := semver.NewVersion(artifact.Provenance.GeneratorVersion)
+		if err != nil {
+			staleArtifacts = append(staleArtifacts, artifact.Name)
+			continue
+		}
+		if generatorVersion.LessThan(*threshold) {
+			staleArtifacts = append(staleArtifacts, artifact.Name)
+		}
+	}
+	return staleArtifacts, nil
+}
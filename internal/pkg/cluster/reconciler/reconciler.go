@@ -20,10 +20,12 @@ import (
 	"net"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	clusterapi "github.com/oneinfra/oneinfra/internal/pkg/cluster"
 	componentapi "github.com/oneinfra/oneinfra/internal/pkg/component"
 	"github.com/oneinfra/oneinfra/internal/pkg/component/components"
@@ -34,6 +36,11 @@ import (
 	"github.com/oneinfra/oneinfra/pkg/constants"
 )
 
+// maxUpgradeFailedAttempts is the number of consecutive reconciles a
+// control plane is allowed to stay unready for under a new
+// KubernetesVersion before the upgrade is automatically rolled back
+const maxUpgradeFailedAttempts = 5
+
 // OptionalReconcile represents what optional reconciliations should
 // or should not take place
 type OptionalReconcile struct {
@@ -45,14 +52,16 @@ type ClusterReconciler struct {
 	hypervisorMap infra.HypervisorMap
 	clusterMap    clusterapi.Map
 	componentList componentapi.List
+	freezeWindows clusterapi.FreezeWindowList
 }
 
-// NewClusterReconciler creates a cluster reconciler with the provided hypervisors, clusters and components
-func NewClusterReconciler(hypervisorMap infra.HypervisorMap, clusterMap clusterapi.Map, componentList componentapi.List) *ClusterReconciler {
+// NewClusterReconciler creates a cluster reconciler with the provided hypervisors, clusters, components and freeze windows
+func NewClusterReconciler(hypervisorMap infra.HypervisorMap, clusterMap clusterapi.Map, componentList componentapi.List, freezeWindows clusterapi.FreezeWindowList) *ClusterReconciler {
 	return &ClusterReconciler{
 		hypervisorMap: hypervisorMap,
 		clusterMap:    clusterMap,
 		componentList: componentList,
+		freezeWindows: freezeWindows,
 	}
 }
 
@@ -106,11 +115,14 @@ func (clusterReconciler *ClusterReconciler) Reconcile(optionalReconcile Optional
 			clusterapi.ReconcileStarted,
 			conditions.ConditionTrue,
 		)
+		clusterReconciler.reconcileCertificateRotation(cluster, &reconcileErrors)
 		if cluster.VPN.Enabled {
 			clusterReconciler.reconcileVPNServerEndpoint(cluster, &reconcileErrors)
+			clusterReconciler.reconcileVPNPeerExpiry(cluster, &reconcileErrors)
 		}
 		clusterReconciler.reconcileCustomResourceDefinitions(cluster, &reconcileErrors)
 		clusterReconciler.reconcileNamespaces(cluster, &reconcileErrors)
+		clusterReconciler.reconcileBaseline(cluster, &reconcileErrors)
 		clusterReconciler.reconcilePermissions(cluster, &reconcileErrors)
 		clusterReconciler.reconcileJoinTokens(cluster, &reconcileErrors)
 		clusterReconciler.reconcileStorageEndpoints(cluster, &reconcileErrors)
@@ -120,16 +132,24 @@ func (clusterReconciler *ClusterReconciler) Reconcile(optionalReconcile Optional
 			clusterReconciler.reconcileNodeJoinRequests(cluster, &reconcileErrors)
 		}
 		clusterReconciler.reconcileJoinPublicKeyConfigMap(cluster, &reconcileErrors)
+		clusterReconciler.reconcileUpgradeRollback(cluster, &reconcileErrors)
+		wasSucceeded := cluster.Conditions.IsCondition(clusterapi.ReconcileSucceeded, conditions.ConditionTrue)
 		if reconcileErrors.IsClusterErrorFree(cluster.Namespace, cluster.Name) {
 			cluster.Conditions.SetCondition(
 				clusterapi.ReconcileSucceeded,
 				conditions.ConditionTrue,
 			)
+			if !wasSucceeded {
+				cluster.RecordHistory("reconcile", "succeeded", "cluster reconciled successfully")
+			}
 		} else {
 			cluster.Conditions.SetCondition(
 				clusterapi.ReconcileSucceeded,
 				conditions.ConditionFalse,
 			)
+			if wasSucceeded {
+				cluster.RecordHistory("reconcile", "failed", clusterErrorsMessage(reconcileErrors.ClusterErrors(cluster.Namespace, cluster.Name)))
+			}
 		}
 	}
 	if len(reconcileErrors) == 0 {
@@ -138,6 +158,32 @@ func (clusterReconciler *ClusterReconciler) Reconcile(optionalReconcile Optional
 	return reconcileErrors
 }
 
+func (clusterReconciler *ClusterReconciler) reconcileCertificateRotation(cluster *clusterapi.Cluster, reconcileErrors *reconciler.ReconcileErrors) {
+	if clusterReconciler.freezeWindows.Blocks(cluster.Namespace, cluster.Name) {
+		klog.V(1).Infof("cluster %q is within an active freeze window; skipping automated certificate rotation", cluster.Name)
+		return
+	}
+	rotated, err := cluster.RotateCertificates()
+	if err != nil {
+		reconcileErrors.AddClusterError(cluster.Namespace, cluster.Name, err)
+		return
+	}
+	// The allow-migration annotation is what lets the validating
+	// webhook accept this very update even though it changes
+	// otherwise immutable certificate authority material. It is only
+	// held for the reconcile cycle that performs the rotation, and
+	// removed again right away, so it cannot be mistaken for a
+	// standing exemption by a later, human-initiated edit.
+	if rotated {
+		if cluster.Annotations == nil {
+			cluster.Annotations = map[string]string{}
+		}
+		cluster.Annotations[constants.OneInfraAllowMigrationAnnotation] = ""
+	} else {
+		delete(cluster.Annotations, constants.OneInfraAllowMigrationAnnotation)
+	}
+}
+
 func (clusterReconciler *ClusterReconciler) reconcileAPIServerEndpoint(cluster *clusterapi.Cluster, reconcileErrors *reconciler.ReconcileErrors) {
 	controlPlaneIngressList := clusterReconciler.componentList.WithCluster(cluster.Namespace, cluster.Name).WithRole(componentapi.ControlPlaneIngressRole)
 	if len(controlPlaneIngressList) == 0 {
@@ -164,6 +210,10 @@ func (clusterReconciler *ClusterReconciler) reconcileAPIServerEndpoint(cluster *
 }
 
 func (clusterReconciler *ClusterReconciler) reconcileVPNServerEndpoint(cluster *clusterapi.Cluster, reconcileErrors *reconciler.ReconcileErrors) {
+	if cluster.VPN.ExternalEndpoint != "" {
+		cluster.VPNServerEndpoint = cluster.VPN.ExternalEndpoint
+		return
+	}
 	controlPlaneIngressList := clusterReconciler.componentList.WithCluster(cluster.Namespace, cluster.Name).WithRole(componentapi.ControlPlaneIngressRole)
 	if len(controlPlaneIngressList) == 0 {
 		reconcileErrors.AddClusterError(cluster.Namespace, cluster.Name, errors.New("could not find any control plane ingress component"))
@@ -187,6 +237,13 @@ func (clusterReconciler *ClusterReconciler) reconcileVPNServerEndpoint(cluster *
 	cluster.VPNServerEndpoint = net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(wireguardHostPort))
 }
 
+func (clusterReconciler *ClusterReconciler) reconcileVPNPeerExpiry(cluster *clusterapi.Cluster, reconcileErrors *reconciler.ReconcileErrors) {
+	if err := cluster.ReconcileExpiredVPNPeers(); err != nil {
+		klog.Errorf("failed to reconcile VPN peer expiry for cluster %q: %v", cluster.Name, err)
+		reconcileErrors.AddClusterError(cluster.Namespace, cluster.Name, errors.Wrap(err, "failed to reconcile VPN peer expiry"))
+	}
+}
+
 func (clusterReconciler *ClusterReconciler) reconcileCustomResourceDefinitions(cluster *clusterapi.Cluster, reconcileErrors *reconciler.ReconcileErrors) {
 	if err := cluster.ReconcileCustomResourceDefinitions(); err != nil {
 		klog.Errorf("failed to reconcile custom resource definitions for cluster %q: %v", cluster.Name, err)
@@ -201,6 +258,13 @@ func (clusterReconciler *ClusterReconciler) reconcileNamespaces(cluster *cluster
 	}
 }
 
+func (clusterReconciler *ClusterReconciler) reconcileBaseline(cluster *clusterapi.Cluster, reconcileErrors *reconciler.ReconcileErrors) {
+	if err := cluster.ReconcileBaseline(); err != nil {
+		klog.Errorf("failed to reconcile baseline objects for cluster %q: %v", cluster.Name, err)
+		reconcileErrors.AddClusterError(cluster.Namespace, cluster.Name, errors.Wrap(err, "failed to reconcile baseline objects"))
+	}
+}
+
 func (clusterReconciler *ClusterReconciler) reconcilePermissions(cluster *clusterapi.Cluster, reconcileErrors *reconciler.ReconcileErrors) {
 	if err := cluster.ReconcilePermissions(); err != nil {
 		klog.Errorf("failed to reconcile permissions for cluster %q: %v", cluster.Name, err)
@@ -279,6 +343,110 @@ func (clusterReconciler *ClusterReconciler) reconcileJoinPublicKeyConfigMap(clus
 	}
 }
 
+// reconcileUpgradeRollback watches over KubernetesVersion upgrades: it
+// snapshots etcd before the control plane is reconciled under a new
+// version, and automatically reverts KubernetesVersion and restores
+// that snapshot if the control plane does not become ready again
+// within maxUpgradeFailedAttempts reconciles
+func (clusterReconciler *ClusterReconciler) reconcileUpgradeRollback(cluster *clusterapi.Cluster, reconcileErrors *reconciler.ReconcileErrors) {
+	controlPlaneList := clusterReconciler.componentList.WithCluster(cluster.Namespace, cluster.Name).WithRole(componentapi.ControlPlaneRole)
+	if len(controlPlaneList) == 0 {
+		return
+	}
+	if cluster.Upgrade == nil || cluster.Upgrade.LastReadyKubernetesVersion == "" {
+		cluster.Upgrade = &clusterv1alpha1.ClusterUpgradeStatus{
+			LastReadyKubernetesVersion: cluster.KubernetesVersion,
+		}
+		return
+	}
+	if cluster.Upgrade.LastReadyKubernetesVersion == cluster.KubernetesVersion {
+		cluster.Upgrade.EtcdSnapshotComponent = ""
+		cluster.Upgrade.EtcdSnapshotPath = ""
+		cluster.Upgrade.FailedAttempts = 0
+		return
+	}
+	if cluster.UpgradeStrategyPaused {
+		// The control plane is being held at LastReadyKubernetesVersion
+		// while paused, so it stays ready under the old version rather
+		// than failing under the new one. Skip the snapshot/rollback
+		// bookkeeping entirely: no forward progress is being attempted,
+		// so there is nothing to snapshot against and the mismatch must
+		// not be mistaken for a completed upgrade
+		return
+	}
+	snapshotComponent := controlPlaneList.WithName(cluster.Upgrade.EtcdSnapshotComponent)
+	if cluster.Upgrade.EtcdSnapshotComponent == "" || snapshotComponent == nil {
+		if cluster.UpgradeStrategyType == clusterv1alpha1.UpgradeStrategySurge {
+			if err := clusterReconciler.validateSurgeCapacity(cluster); err != nil {
+				reconcileErrors.AddClusterError(cluster.Namespace, cluster.Name, err)
+				return
+			}
+		}
+		snapshotComponent = controlPlaneList[0]
+		snapshotInquirer := &reconciler.Inquirer{
+			ReconciledComponent: snapshotComponent,
+			Reconciler:          clusterReconciler,
+		}
+		if err := (&components.ControlPlane{}).SnapshotEtcd(snapshotInquirer); err != nil {
+			klog.Errorf("failed to take pre-upgrade etcd snapshot for cluster %q: %v", cluster.Name, err)
+			reconcileErrors.AddClusterError(cluster.Namespace, cluster.Name, errors.Wrap(err, "failed to take pre-upgrade etcd snapshot"))
+			return
+		}
+		cluster.Upgrade.EtcdSnapshotComponent = snapshotComponent.Name
+		cluster.Upgrade.FailedAttempts = 0
+		return
+	}
+	for _, controlPlane := range controlPlaneList {
+		if !controlPlane.Conditions.IsCondition(componentapi.ComponentReady, conditions.ConditionTrue) {
+			cluster.Upgrade.FailedAttempts++
+			if cluster.Upgrade.FailedAttempts < maxUpgradeFailedAttempts {
+				return
+			}
+			klog.Errorf("control plane for cluster %q did not become ready after upgrading to version %q, rolling back", cluster.Name, cluster.KubernetesVersion)
+			restoreInquirer := &reconciler.Inquirer{
+				ReconciledComponent: snapshotComponent,
+				Reconciler:          clusterReconciler,
+			}
+			if err := (&components.ControlPlane{}).RestoreEtcdSnapshot(restoreInquirer); err != nil {
+				klog.Errorf("failed to restore pre-upgrade etcd snapshot for cluster %q: %v", cluster.Name, err)
+				reconcileErrors.AddClusterError(cluster.Namespace, cluster.Name, errors.Wrap(err, "failed to restore pre-upgrade etcd snapshot"))
+				return
+			}
+			cluster.KubernetesVersion = cluster.Upgrade.LastReadyKubernetesVersion
+			cluster.Upgrade.EtcdSnapshotComponent = ""
+			cluster.Upgrade.EtcdSnapshotPath = ""
+			cluster.Upgrade.FailedAttempts = 0
+			cluster.Conditions.SetCondition(
+				clusterapi.UpgradeRolledBack,
+				conditions.ConditionTrue,
+			)
+			return
+		}
+	}
+	cluster.Upgrade.LastReadyKubernetesVersion = cluster.KubernetesVersion
+	cluster.Upgrade.EtcdSnapshotComponent = ""
+	cluster.Upgrade.EtcdSnapshotPath = ""
+	cluster.Upgrade.FailedAttempts = 0
+}
+
+// validateSurgeCapacity checks that enough private hypervisors are
+// schedulable to host cluster.UpgradeStrategyMaxSurge extra control
+// plane replicas on top of the ones already running the cluster's
+// current control plane, so a surge upgrade never lands its
+// new-version replicas on the same hypervisors it is about to retire
+func (clusterReconciler *ClusterReconciler) validateSurgeCapacity(cluster *clusterapi.Cluster) error {
+	controlPlaneList := clusterReconciler.componentList.WithCluster(cluster.Namespace, cluster.Name).WithRole(componentapi.ControlPlaneRole)
+	required := len(controlPlaneList) + cluster.UpgradeStrategyMaxSurge
+	available := len(clusterReconciler.hypervisorMap.WithPassingPreflight().PrivateList())
+	if available < required {
+		return errors.Errorf(
+			"surge upgrade requires %d schedulable private hypervisors (%d control plane replicas plus %d surge), only %d available",
+			required, len(controlPlaneList), cluster.UpgradeStrategyMaxSurge, available,
+		)
+	}
+	return nil
+}
+
 // ReconcileDeletion reconciles the deletion of the provided clusters
 func (clusterReconciler *ClusterReconciler) ReconcileDeletion(clustersToDelete ...*clusterapi.Cluster) reconciler.ReconcileErrors {
 	reconcileErrors := reconciler.ReconcileErrors{}
@@ -298,6 +466,16 @@ func (clusterReconciler *ClusterReconciler) ReconcileDeletion(clustersToDelete .
 	return reconcileErrors
 }
 
+// clusterErrorsMessage joins a cluster's reconcile errors into a
+// single human-readable message
+func clusterErrorsMessage(clusterErrors []error) string {
+	messages := make([]string, 0, len(clusterErrors))
+	for _, clusterError := range clusterErrors {
+		messages = append(messages, clusterError.Error())
+	}
+	return strings.Join(messages, ", ")
+}
+
 // Specs returns the versioned specs for all resources
 func (clusterReconciler *ClusterReconciler) Specs() (string, error) {
 	res := ""
@@ -0,0 +1,176 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	"github.com/pkg/errors"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/crypto"
+)
+
+// secretsEncryptionProvider, when set, envelope-encrypts every
+// private key and key pair of a cluster's versioned specs on Export,
+// and deciphers them back on NewClusterFromv1alpha1. It is a single
+// provider configured once by whatever process embeds this package
+// (the manager, or `oi` given a KMS key), mirroring the
+// EnableKeyPool/DisableKeyPools global toggle in the crypto package,
+// instead of being threaded through every caller that serializes or
+// parses cluster specs
+var secretsEncryptionProvider crypto.KeyPairProvider
+
+// SetSecretsEncryptionProvider configures provider as the envelope
+// encryption key used to cipher private key material when exporting
+// cluster specs, and to decipher it back when parsing them
+func SetSecretsEncryptionProvider(provider crypto.KeyPairProvider) {
+	secretsEncryptionProvider = provider
+}
+
+// ClearSecretsEncryptionProvider disables secrets encryption, so
+// Export emits private keys and key pairs in plain PEM again; this is
+// the `--insecure-plaintext` escape hatch of the `oi` commands that
+// round-trip cluster specs
+func ClearSecretsEncryptionProvider() {
+	secretsEncryptionProvider = nil
+}
+
+// encryptSecrets envelope-encrypts every private key and key pair
+// present in versionedCluster in place: a fresh random symmetric key
+// ciphers the (potentially large) key material with AES-GCM, and
+// that symmetric key is itself wrapped with secretsEncryptionProvider
+// and stored in Status.SecretsEncryptionKey. Enveloping is required
+// because secretsEncryptionProvider is commonly an RSA KeyPair, whose
+// raw RSA-OAEP Encrypt cannot fit a PEM-encoded private key in a
+// single operation. A no-op if secretsEncryptionProvider is not
+// configured
+func encryptSecrets(versionedCluster *clusterv1alpha1.Cluster) error {
+	if secretsEncryptionProvider == nil {
+		return nil
+	}
+	symmetricKey, err := crypto.NewSymmetricKey()
+	if err != nil {
+		return errors.Wrap(err, "could not generate a secrets encryption key")
+	}
+	if err := transformPrivateKeys(versionedCluster, symmetricKey.Encrypt); err != nil {
+		return err
+	}
+	wrappedSymmetricKey, err := secretsEncryptionProvider.Encrypt(string(symmetricKey))
+	if err != nil {
+		return errors.Wrap(err, "could not wrap the secrets encryption key")
+	}
+	versionedCluster.Status.SecretsEncryptionKey = wrappedSymmetricKey
+	versionedCluster.Status.SecretsEncrypted = true
+	return nil
+}
+
+// decryptSecrets deciphers every private key and key pair present in
+// versionedCluster in place, unwrapping Status.SecretsEncryptionKey
+// with secretsEncryptionProvider and using the resulting symmetric
+// key to decipher the rest, if versionedCluster declares its secrets
+// are encrypted
+func decryptSecrets(versionedCluster *clusterv1alpha1.Cluster) error {
+	if !versionedCluster.Status.SecretsEncrypted {
+		return nil
+	}
+	if secretsEncryptionProvider == nil {
+		return errors.Errorf("cluster %q has encrypted secrets, but no secrets encryption provider is configured to decipher them", versionedCluster.Name)
+	}
+	unwrappedSymmetricKey, err := secretsEncryptionProvider.Decrypt(versionedCluster.Status.SecretsEncryptionKey)
+	if err != nil {
+		return errors.Wrap(err, "could not unwrap the secrets encryption key")
+	}
+	symmetricKey := crypto.SymmetricKey(unwrappedSymmetricKey)
+	if err := transformPrivateKeys(versionedCluster, symmetricKey.Decrypt); err != nil {
+		return err
+	}
+	versionedCluster.Status.SecretsEncryptionKey = ""
+	versionedCluster.Status.SecretsEncrypted = false
+	return nil
+}
+
+// transformPrivateKeys runs transform over every private key and key
+// pair field versionedCluster carries, replacing each with the
+// result, in place
+func transformPrivateKeys(versionedCluster *clusterv1alpha1.Cluster, transform func(string) (string, error)) error {
+	certificates := []*commonv1alpha1.Certificate{}
+	keyPairs := []*commonv1alpha1.KeyPair{}
+	if certificateAuthorities := versionedCluster.Spec.CertificateAuthorities; certificateAuthorities != nil {
+		certificates = append(
+			certificates,
+			certificateAuthorities.APIServerClient,
+			certificateAuthorities.CertificateSigner,
+			certificateAuthorities.Kubelet,
+			certificateAuthorities.KubeletClient,
+			certificateAuthorities.EtcdClient,
+			certificateAuthorities.EtcdPeer,
+		)
+	}
+	if etcdServer := versionedCluster.Spec.EtcdServer; etcdServer != nil {
+		certificates = append(certificates, etcdServer.CA)
+		if etcdServer.External != nil {
+			certificates = append(certificates, etcdServer.External.ClientCertificate)
+		}
+	}
+	if apiServer := versionedCluster.Spec.APIServer; apiServer != nil {
+		certificates = append(certificates, apiServer.CA)
+		keyPairs = append(keyPairs, apiServer.ServiceAccount)
+	}
+	keyPairs = append(keyPairs, versionedCluster.Spec.JoinKey, versionedCluster.Spec.JoinBoxKey, versionedCluster.Spec.SigningKey)
+	for clientCertificateName := range versionedCluster.Status.ClientCertificates {
+		clientCertificate := versionedCluster.Status.ClientCertificates[clientCertificateName]
+		if err := transformCertificate(&clientCertificate, transform); err != nil {
+			return err
+		}
+		versionedCluster.Status.ClientCertificates[clientCertificateName] = clientCertificate
+	}
+	for _, certificate := range certificates {
+		if err := transformCertificate(certificate, transform); err != nil {
+			return err
+		}
+	}
+	for _, keyPair := range keyPairs {
+		if err := transformKeyPair(keyPair, transform); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func transformCertificate(certificate *commonv1alpha1.Certificate, transform func(string) (string, error)) error {
+	if certificate == nil || certificate.PrivateKey == "" {
+		return nil
+	}
+	privateKey, err := transform(certificate.PrivateKey)
+	if err != nil {
+		return errors.Wrap(err, "could not transform certificate private key")
+	}
+	certificate.PrivateKey = privateKey
+	return nil
+}
+
+func transformKeyPair(keyPair *commonv1alpha1.KeyPair, transform func(string) (string, error)) error {
+	if keyPair == nil || keyPair.PrivateKey == "" {
+		return nil
+	}
+	privateKey, err := transform(keyPair.PrivateKey)
+	if err != nil {
+		return errors.Wrap(err, "could not transform key pair private key")
+	}
+	keyPair.PrivateKey = privateKey
+	return nil
+}
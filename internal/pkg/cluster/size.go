@@ -0,0 +1,73 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package cluster
+
+import (
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+// SizePreset holds the resolved set of tuning values a cluster's
+// control plane will be configured with
+type SizePreset struct {
+	EtcdQuotaBackendBytes                int64
+	APIServerMaxRequestsInflight         int
+	APIServerMaxMutatingRequestsInflight int
+}
+
+// sizePresets are the vetted tuning values for each ClusterSize, so
+// self-service users don't need to understand a dozen tuning knobs
+var sizePresets = map[clusterv1alpha1.ClusterSize]SizePreset{
+	clusterv1alpha1.ClusterSizeSmall: {
+		EtcdQuotaBackendBytes:                2 * 1024 * 1024 * 1024,
+		APIServerMaxRequestsInflight:         400,
+		APIServerMaxMutatingRequestsInflight: 200,
+	},
+	clusterv1alpha1.ClusterSizeMedium: {
+		EtcdQuotaBackendBytes:                4 * 1024 * 1024 * 1024,
+		APIServerMaxRequestsInflight:         800,
+		APIServerMaxMutatingRequestsInflight: 400,
+	},
+	clusterv1alpha1.ClusterSizeLarge: {
+		EtcdQuotaBackendBytes:                8 * 1024 * 1024 * 1024,
+		APIServerMaxRequestsInflight:         1600,
+		APIServerMaxMutatingRequestsInflight: 800,
+	},
+}
+
+// SizePreset returns the tuning values this cluster should be
+// configured with, resolved from its selected Size preset (the
+// built-in defaults if unset), with any SizeOverrides applied on
+// top
+func (cluster *Cluster) SizePreset() SizePreset {
+	preset := SizePreset{}
+	if cluster.Size != nil {
+		preset = sizePresets[*cluster.Size]
+	}
+	if cluster.SizeOverrides == nil {
+		return preset
+	}
+	if cluster.SizeOverrides.EtcdQuotaBackendBytes != 0 {
+		preset.EtcdQuotaBackendBytes = cluster.SizeOverrides.EtcdQuotaBackendBytes
+	}
+	if cluster.SizeOverrides.APIServerMaxRequestsInflight != 0 {
+		preset.APIServerMaxRequestsInflight = cluster.SizeOverrides.APIServerMaxRequestsInflight
+	}
+	if cluster.SizeOverrides.APIServerMaxMutatingRequestsInflight != 0 {
+		preset.APIServerMaxMutatingRequestsInflight = cluster.SizeOverrides.APIServerMaxMutatingRequestsInflight
+	}
+	return preset
+}
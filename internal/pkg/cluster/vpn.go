@@ -17,17 +17,124 @@
 package cluster
 
 import (
+	"math/big"
 	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 )
 
+// wireguardDefaultKeepaliveSeconds is the WireGuard persistent
+// keepalive interval handed out to joining nodes when a cluster does
+// not override it
+const wireguardDefaultKeepaliveSeconds = 20
+
+const wireguardClientConfigTemplate = `[Interface]
+Address = {{ .Address }}
+PrivateKey = {{ .PrivateKey }}
+
+[Peer]
+PublicKey = {{ .ServerPublicKey }}
+Endpoint = {{ .ServerEndpoint }}
+AllowedIPs = {{ .AllowedIPs }}
+`
+
 // VPN represents the VPN configuration
 type VPN struct {
-	Enabled    bool
-	PrivateKey string
-	PublicKey  string
-	CIDR       *net.IPNet
+	Enabled          bool
+	Backend          clusterv1alpha1.VPNBackend
+	PrivateKey       string
+	PublicKey        string
+	CIDR             *net.IPNet
+	PeerQuotas       map[clusterv1alpha1.VPNPeerPurpose]int
+	ExternalEndpoint string
+	KeepaliveSeconds int
+}
+
+// VPNProvider implements the backend-specific behavior needed to run
+// a cluster VPN: minting peer key material and assigning peer
+// addresses. WireGuard is the only backend oneinfra ships today, but
+// alternative backends (e.g. Tailscale, plain IPsec) can be plugged
+// in with feature parity by implementing this interface and adding
+// an entry to vpnProviders
+type VPNProvider interface {
+	// NewKeyPair generates a new private/public key pair for a VPN peer
+	NewKeyPair() (privateKey, publicKey string, err error)
+
+	// AssignAddress returns the first address from cidr that is not
+	// already present in allocated, so a deleted peer's address can
+	// be reused instead of permanently retiring it
+	AssignAddress(cidr *net.IPNet, allocated map[string]bool) (string, error)
+}
+
+// vpnProviders maps each supported VPN backend to its provider
+var vpnProviders = map[clusterv1alpha1.VPNBackend]VPNProvider{
+	clusterv1alpha1.VPNBackendWireGuard: wireGuardVPNProvider{},
+	clusterv1alpha1.VPNBackendNone:      noneVPNProvider{},
+}
+
+// vpnProviderFor returns the VPN provider for backend, defaulting to
+// the WireGuard provider when backend is unset
+func vpnProviderFor(backend clusterv1alpha1.VPNBackend) VPNProvider {
+	if backend == "" {
+		backend = clusterv1alpha1.VPNBackendWireGuard
+	}
+	if provider, exists := vpnProviders[backend]; exists {
+		return provider
+	}
+	return wireGuardVPNProvider{}
+}
+
+// wireGuardVPNProvider implements VPNProvider on top of WireGuard
+// key generation and plain CIDR-sequential address assignment
+type wireGuardVPNProvider struct{}
+
+func (wireGuardVPNProvider) NewKeyPair() (string, string, error) {
+	privateKey, err := wgtypes.GeneratePrivateKey()
+	if err != nil {
+		return "", "", err
+	}
+	return privateKey.String(), privateKey.PublicKey().String(), nil
+}
+
+func (wireGuardVPNProvider) AssignAddress(cidr *net.IPNet, allocated map[string]bool) (string, error) {
+	for offset := int64(1); ; offset++ {
+		candidate := vpnAddressAtOffset(cidr, offset)
+		if !cidr.Contains(candidate) {
+			return "", errors.Errorf("not enough IP addresses to assign in the %q CIDR", cidr)
+		}
+		if !allocated[candidate.String()] {
+			return candidate.String(), nil
+		}
+	}
+}
+
+// vpnAddressAtOffset returns the address offset positions past the
+// start of cidr, without checking whether it still falls inside it
+func vpnAddressAtOffset(cidr *net.IPNet, offset int64) net.IP {
+	vpnNetwork := big.NewInt(0).SetBytes(cidr.IP.To16())
+	vpnAddress := vpnNetwork.Add(vpnNetwork, big.NewInt(offset))
+	vpnAddressSlice := vpnAddress.Bytes()[2:]
+	if len(vpnAddress.Bytes()) == net.IPv6len {
+		vpnAddressSlice = vpnAddress.Bytes()
+	}
+	return net.IP(vpnAddressSlice)
+}
+
+// noneVPNProvider implements VPNProvider for clusters that disabled
+// the VPN, refusing to mint any peer
+type noneVPNProvider struct{}
+
+func (noneVPNProvider) NewKeyPair() (string, string, error) {
+	return "", "", errors.New("VPN backend \"none\" does not support minting VPN peers")
+}
+
+func (noneVPNProvider) AssignAddress(cidr *net.IPNet, allocated map[string]bool) (string, error) {
+	return "", errors.New("VPN backend \"none\" does not support minting VPN peers")
 }
 
 // VPNPeer represents a VPN peer
@@ -36,6 +143,11 @@ type VPNPeer struct {
 	Address    string
 	PrivateKey string
 	PublicKey  string
+	Purpose    clusterv1alpha1.VPNPeerPurpose
+
+	// ExpiresAt, when set, is the time after which this VPN peer and
+	// its key material are automatically released
+	ExpiresAt *time.Time
 }
 
 // VPNPeerMap represents a map of VPN peers
@@ -47,11 +159,19 @@ func newVPNFromv1alpha1(vpn *clusterv1alpha1.VPN) *VPN {
 			Enabled: false,
 		}
 	}
+	externalEndpoint := ""
+	if vpn.ExternalEndpoint != nil {
+		externalEndpoint = *vpn.ExternalEndpoint
+	}
 	return &VPN{
-		Enabled:    vpn.Enabled,
-		PrivateKey: *vpn.PrivateKey,
-		PublicKey:  *vpn.PublicKey,
-		CIDR:       newVPNCIDRFromv1alpha1(*vpn.CIDR),
+		Enabled:          vpn.Enabled,
+		Backend:          vpn.Backend,
+		PrivateKey:       *vpn.PrivateKey,
+		PublicKey:        *vpn.PublicKey,
+		CIDR:             newVPNCIDRFromv1alpha1(*vpn.CIDR),
+		PeerQuotas:       vpn.PeerQuotas,
+		ExternalEndpoint: externalEndpoint,
+		KeepaliveSeconds: vpn.KeepaliveSeconds,
 	}
 }
 
@@ -63,23 +183,40 @@ func (vpn *VPN) Export() *clusterv1alpha1.VPN {
 		}
 	}
 	vpnCIDR := vpn.CIDR.String()
-	return &clusterv1alpha1.VPN{
-		Enabled:    vpn.Enabled,
-		PrivateKey: &vpn.PrivateKey,
-		PublicKey:  &vpn.PublicKey,
-		CIDR:       &vpnCIDR,
+	res := &clusterv1alpha1.VPN{
+		Enabled:          vpn.Enabled,
+		Backend:          vpn.Backend,
+		PrivateKey:       &vpn.PrivateKey,
+		PublicKey:        &vpn.PublicKey,
+		CIDR:             &vpnCIDR,
+		PeerQuotas:       vpn.PeerQuotas,
+		KeepaliveSeconds: vpn.KeepaliveSeconds,
+	}
+	if vpn.ExternalEndpoint != "" {
+		res.ExternalEndpoint = &vpn.ExternalEndpoint
 	}
+	return res
 }
 
 func newVPNPeersFromv1alpha1(peers []clusterv1alpha1.VPNPeer) VPNPeerMap {
 	res := VPNPeerMap{}
 	for _, peer := range peers {
-		res[peer.Name] = &VPNPeer{
+		purpose := peer.Purpose
+		if purpose == "" {
+			purpose = clusterv1alpha1.VPNPeerPurposeWorker
+		}
+		vpnPeer := &VPNPeer{
 			Name:       peer.Name,
 			Address:    peer.Address,
 			PrivateKey: peer.PrivateKey,
 			PublicKey:  peer.PublicKey,
+			Purpose:    purpose,
+		}
+		if peer.ExpiresAt != nil {
+			expiresAt := peer.ExpiresAt.Time
+			vpnPeer.ExpiresAt = &expiresAt
 		}
+		res[peer.Name] = vpnPeer
 	}
 	return res
 }
@@ -92,12 +229,13 @@ func newVPNCIDRFromv1alpha1(vpnCIDR string) *net.IPNet {
 	return ipNet
 }
 
-func newVPNPeer(name, address, privateKey, publicKey string) *VPNPeer {
+func newVPNPeer(name, address, privateKey, publicKey string, purpose clusterv1alpha1.VPNPeerPurpose) *VPNPeer {
 	return &VPNPeer{
 		Name:       name,
 		Address:    address,
 		PrivateKey: privateKey,
 		PublicKey:  publicKey,
+		Purpose:    purpose,
 	}
 }
 
@@ -105,12 +243,18 @@ func newVPNPeer(name, address, privateKey, publicKey string) *VPNPeer {
 func (vpnPeerMap VPNPeerMap) Export() []clusterv1alpha1.VPNPeer {
 	res := []clusterv1alpha1.VPNPeer{}
 	for _, peer := range vpnPeerMap {
-		res = append(res, clusterv1alpha1.VPNPeer{
+		versionedPeer := clusterv1alpha1.VPNPeer{
 			Name:       peer.Name,
 			Address:    peer.Address,
 			PrivateKey: peer.PrivateKey,
 			PublicKey:  peer.PublicKey,
-		})
+			Purpose:    peer.Purpose,
+		}
+		if peer.ExpiresAt != nil {
+			expiresAt := metav1.NewTime(*peer.ExpiresAt)
+			versionedPeer.ExpiresAt = &expiresAt
+		}
+		res = append(res, versionedPeer)
 	}
 	return res
 }
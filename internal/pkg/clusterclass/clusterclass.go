@@ -0,0 +1,95 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package clusterclass implements a higher level, single document
+// input format that bundles the cluster, VPN and placement choices
+// that would otherwise require composing several CRDs by hand. `oi
+// cluster apply` decomposes a ClusterClass document into the
+// underlying Cluster and Component resources.
+package clusterclass
+
+import (
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+)
+
+// ClusterClass represents a single document describing a cluster to
+// create, along with its VPN and placement choices
+type ClusterClass struct {
+	// Name is the cluster name
+	Name string `json:"name"`
+
+	// KubernetesVersion is the Kubernetes version this cluster will
+	// run. Defaults to the default Kubernetes version if empty.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+
+	// ControlPlaneReplicas is the number of control plane replicas
+	// this cluster will manage. One control plane replica if not
+	// provided.
+	ControlPlaneReplicas int `json:"controlPlaneReplicas,omitempty"`
+
+	// HypervisorPool, when set, is the name of the HypervisorPool
+	// this cluster will be bound to
+	HypervisorPool string `json:"hypervisorPool,omitempty"`
+
+	// APIServerExtraSANs are extra Subject Alternative Names for the
+	// API server certificate
+	APIServerExtraSANs []string `json:"apiServerExtraSANs,omitempty"`
+
+	// VPN configures the VPN for this cluster
+	VPN *VPN `json:"vpn,omitempty"`
+}
+
+// VPN represents the VPN choices for a ClusterClass document
+type VPN struct {
+	// Enabled tells whether the VPN should be enabled for this cluster
+	Enabled bool `json:"enabled,omitempty"`
+
+	// CIDR is the CIDR used for the internal VPN
+	CIDR string `json:"CIDR,omitempty"`
+}
+
+// NewClusterClassFromFile reads a ClusterClass document from path
+func NewClusterClassFromFile(path string) (*ClusterClass, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	clusterClass := ClusterClass{}
+	if err := yaml.Unmarshal(contents, &clusterClass); err != nil {
+		return nil, errors.Wrap(err, "could not parse cluster class document")
+	}
+	return &clusterClass, nil
+}
+
+// Validate performs server-side validation of this ClusterClass
+// document against the versions installed in this oneinfra release
+func (clusterClass *ClusterClass) Validate() error {
+	if clusterClass.Name == "" {
+		return errors.New("cluster class document is missing a name")
+	}
+	if clusterClass.KubernetesVersion == "" {
+		return nil
+	}
+	if _, err := constants.KubernetesVersionBundle(clusterClass.KubernetesVersion); err != nil {
+		return errors.Wrapf(err, "cluster class document requests an unsupported Kubernetes version %q", clusterClass.KubernetesVersion)
+	}
+	return nil
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -53,6 +54,30 @@ const (
 	// ReconcileSucceeded represents a condition type signaling that a
 	// reconcile has succeeded
 	ReconcileSucceeded conditions.ConditionType = "ReconcileSucceeded"
+	// ComponentReady represents a condition type signaling whether a
+	// component has passed its readiness checks
+	ComponentReady conditions.ConditionType = "ComponentReady"
+	// AutoRepairSuspended represents a condition type signaling that
+	// one of this component's pods has exceeded the max-restarts
+	// circuit breaker, and is no longer being automatically recreated
+	// by the auto-repair logic
+	AutoRepairSuspended conditions.ConditionType = "AutoRepairSuspended"
+	// UpgradePending represents a condition type signaling that this
+	// component is not yet running its cluster's desired
+	// KubernetesVersion, whether because its upgrade has not been
+	// reconciled yet or because the upgrade strategy is paused
+	UpgradePending conditions.ConditionType = "UpgradePending"
+	// EtcdMemberUnhealthy represents a condition type signaling that
+	// this component's own etcd member failed its last status check
+	EtcdMemberUnhealthy conditions.ConditionType = "EtcdMemberUnhealthy"
+	// EtcdDefragmented represents a condition type signaling that
+	// this component's own etcd member has been defragmented; its
+	// LastSetTime is used to throttle how often defragmentation runs
+	EtcdDefragmented conditions.ConditionType = "EtcdDefragmented"
+	// EtcdBackedUp represents a condition type signaling that this
+	// component's own etcd member has had a snapshot backup taken;
+	// its LastSetTime is used to throttle how often backups run
+	EtcdBackedUp conditions.ConditionType = "EtcdBackedUp"
 )
 
 // Component represents a Control Plane component
@@ -177,10 +202,11 @@ func (component *Component) FreePort(hypervisor *infra.Hypervisor, name string)
 	return nil
 }
 
-// ClientCertificate returns a client certificate with the given name
-func (component *Component) ClientCertificate(ca *certificates.Certificate, name, commonName string, organization []string, extraSANs []string) (*certificates.Certificate, error) {
-	// FIXME: not only check for existence, also that contents semantically match
-	if clientCertificate, exists := component.ClientCertificates[name]; exists {
+// ClientCertificate returns a client certificate with the given name,
+// re-issuing it when it is missing or expires within
+// rotationThreshold
+func (component *Component) ClientCertificate(ca *certificates.Certificate, name, commonName string, organization []string, extraSANs []string, rotationThreshold time.Duration) (*certificates.Certificate, error) {
+	if clientCertificate, exists := component.ClientCertificates[name]; exists && !clientCertificate.ExpiresWithin(rotationThreshold) {
 		return clientCertificate, nil
 	}
 	certificate, privateKey, err := ca.CreateCertificate(commonName, organization, extraSANs)
@@ -195,10 +221,12 @@ func (component *Component) ClientCertificate(ca *certificates.Certificate, name
 	return clientCertificate, nil
 }
 
-// ServerCertificate returns a server certificate with the given name
-func (component *Component) ServerCertificate(ca *certificates.Certificate, name, commonName string, organization []string, extraSANs []string) (*certificates.Certificate, error) {
-	// FIXME: not only check for existence, also that contents semantically match
-	if serverCertificate, exists := component.ServerCertificates[name]; exists {
+// ServerCertificate returns a server certificate with the given name,
+// rolling it when it already exists but does not cover all the
+// requested extraSANs (e.g. the control plane ingress moved to a new
+// hypervisor), or when it expires within rotationThreshold
+func (component *Component) ServerCertificate(ca *certificates.Certificate, name, commonName string, organization []string, extraSANs []string, rotationThreshold time.Duration) (*certificates.Certificate, error) {
+	if serverCertificate, exists := component.ServerCertificates[name]; exists && serverCertificate.HasSANs(extraSANs) && !serverCertificate.ExpiresWithin(rotationThreshold) {
 		return serverCertificate, nil
 	}
 	certificate, privateKey, err := ca.CreateCertificate(commonName, organization, extraSANs)
@@ -221,6 +249,7 @@ func (component *Component) KubeConfig(cluster *cluster.Cluster, apiServerEndpoi
 		"kubernetes-admin",
 		[]string{"system:masters"},
 		[]string{},
+		cluster.RotationThreshold(),
 	)
 	if err != nil {
 		return "", err
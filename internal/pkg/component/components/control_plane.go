@@ -20,24 +20,43 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/certificates"
+	clusterapi "github.com/oneinfra/oneinfra/internal/pkg/cluster"
 	componentapi "github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/component/healthcheck"
+	"github.com/oneinfra/oneinfra/internal/pkg/conditions"
 	"github.com/oneinfra/oneinfra/internal/pkg/constants"
 	"github.com/oneinfra/oneinfra/internal/pkg/infra"
 	"github.com/oneinfra/oneinfra/internal/pkg/infra/pod"
 	"github.com/oneinfra/oneinfra/internal/pkg/inquirer"
+	"github.com/oneinfra/oneinfra/internal/pkg/reconciler"
 	constantsapi "github.com/oneinfra/oneinfra/pkg/constants"
+	"github.com/oneinfra/oneinfra/pkg/versions"
 )
 
 const (
 	// APIServerHostPortName represents the apiserver host port
 	// allocation name
 	APIServerHostPortName = "apiserver"
+	// ControllerManagerHostPortName represents the controller-manager
+	// secure port host port allocation name
+	ControllerManagerHostPortName = "controller-manager"
+	// SchedulerHostPortName represents the scheduler secure port host
+	// port allocation name
+	SchedulerHostPortName = "scheduler"
+)
+
+const (
+	controllerManagerSecurePort = 10257
+	schedulerSecurePort         = 10259
 )
 
 const (
@@ -50,23 +69,103 @@ const (
 // including: etcd, API server, controller-manager and scheduler
 type ControlPlane struct{}
 
-// PreReconcile pre-reconciles the control plane component
+// componentImage formats imageFormat with version, and, when the
+// cluster overrides ImageRegistry, mirrors the resulting image under
+// that registry instead of its built-in default
+func componentImage(cluster *clusterapi.Cluster, imageFormat, version string) string {
+	return constantsapi.ImageWithRegistry(fmt.Sprintf(imageFormat, version), cluster.ImageRegistry)
+}
+
+// preReconcileStepMaxAttempts bounds the attempts, the first one
+// included, made at each port allocation step before PreReconcile
+// gives up on it
+const preReconcileStepMaxAttempts = 3
+
+// PreReconcile pre-reconciles the control plane component. Port
+// allocation is expressed as a dependency graph, instead of a flat
+// sequence of calls, so the actual constraints (etcd must have its
+// ports reserved before apiserver is told to use them, apiserver
+// must have its port reserved before controller-manager and
+// scheduler, which talk to it, get theirs) are declared rather than
+// implied by the order statements happen to appear in
 func (controlPlane *ControlPlane) PreReconcile(inquirer inquirer.ReconcilerInquirer) error {
 	component := inquirer.Component()
 	if component.HypervisorName == "" {
 		return errors.Errorf("could not pre-reconcile component %q; no hypervisor assigned yet", component.Name)
 	}
 	hypervisor := inquirer.Hypervisor()
-	if _, err := component.RequestPort(hypervisor, APIServerHostPortName); err != nil {
-		return err
-	}
-	if _, err := component.RequestPort(hypervisor, EtcdPeerHostPortName); err != nil {
-		return err
+	cluster := inquirer.Cluster()
+	steps := reconciler.StepGraph{
+		{
+			Name:        "etcd",
+			MaxAttempts: preReconcileStepMaxAttempts,
+			Run: func() error {
+				if cluster.EtcdServer.External != nil {
+					return nil
+				}
+				if cluster.EtcdServer.KineSQL == nil {
+					if _, err := component.RequestPort(hypervisor, EtcdPeerHostPortName); err != nil {
+						return err
+					}
+				}
+				_, err := component.RequestPort(hypervisor, EtcdClientHostPortName)
+				return err
+			},
+		},
+		{
+			Name:        "etcd-events",
+			DependsOn:   []string{"etcd"},
+			MaxAttempts: preReconcileStepMaxAttempts,
+			Run: func() error {
+				if !cluster.EtcdServer.EventsEnabled {
+					return nil
+				}
+				if _, err := component.RequestPort(hypervisor, EtcdEventsPeerHostPortName); err != nil {
+					return err
+				}
+				_, err := component.RequestPort(hypervisor, EtcdEventsClientHostPortName)
+				return err
+			},
+		},
+		{
+			Name:        "apiserver",
+			DependsOn:   []string{"etcd"},
+			MaxAttempts: preReconcileStepMaxAttempts,
+			Run: func() error {
+				_, err := component.RequestPort(hypervisor, APIServerHostPortName)
+				return err
+			},
+		},
+		{
+			Name:        "controller-manager",
+			DependsOn:   []string{"apiserver"},
+			MaxAttempts: preReconcileStepMaxAttempts,
+			Run: func() error {
+				if !cluster.ControllerManagerEnabled() {
+					return nil
+				}
+				_, err := component.RequestPort(hypervisor, ControllerManagerHostPortName)
+				return err
+			},
+		},
+		{
+			Name:        "scheduler",
+			DependsOn:   []string{"apiserver"},
+			MaxAttempts: preReconcileStepMaxAttempts,
+			Run: func() error {
+				if !cluster.SchedulerEnabled() {
+					return nil
+				}
+				_, err := component.RequestPort(hypervisor, SchedulerHostPortName)
+				return err
+			},
+		},
 	}
-	if _, err := component.RequestPort(hypervisor, EtcdClientHostPortName); err != nil {
-		return err
+	statuses, err := reconciler.Execute(steps)
+	for _, status := range statuses {
+		klog.V(2).Infof("component %q pre-reconcile step %q finished after %d attempt(s): %v", component.Name, status.Name, status.Attempts, status.Err)
 	}
-	return nil
+	return err
 }
 
 func (controlPlane *ControlPlane) reconcileInputAndOutputEndpoints(inquirer inquirer.ReconcilerInquirer) error {
@@ -84,12 +183,55 @@ func (controlPlane *ControlPlane) reconcileInputAndOutputEndpoints(inquirer inqu
 	return nil
 }
 
+// pinnedImageDigests returns the subset of this control plane's
+// component images that have a pinned content digest configured,
+// keyed by the fully qualified image reference that was ensured to
+// be present on the hypervisor
+func (controlPlane *ControlPlane) pinnedImageDigests(cluster *clusterapi.Cluster, kubernetesVersion string, versionBundle *versions.KubernetesVersion) map[string]string {
+	digests := map[string]string{}
+	if cluster.ImageDigests == nil {
+		return digests
+	}
+	if cluster.ImageDigests.Etcd != "" {
+		digests[componentImage(cluster, etcdImage, versionBundle.EtcdVersion)] = cluster.ImageDigests.Etcd
+	}
+	if cluster.ImageDigests.APIServer != "" {
+		digests[componentImage(cluster, kubeAPIServerImage, kubernetesVersion)] = cluster.ImageDigests.APIServer
+	}
+	if cluster.ControllerManagerEnabled() && cluster.ImageDigests.ControllerManager != "" {
+		digests[componentImage(cluster, kubeControllerManagerImage, kubernetesVersion)] = cluster.ImageDigests.ControllerManager
+	}
+	if cluster.SchedulerEnabled() && cluster.ImageDigests.Scheduler != "" {
+		digests[componentImage(cluster, kubeSchedulerImage, kubernetesVersion)] = cluster.ImageDigests.Scheduler
+	}
+	return digests
+}
+
+// RequiredImages returns the full set of control plane images
+// (etcd, apiserver, controller-manager and scheduler) pinned for
+// kubernetesVersion, regardless of whether the optional
+// controller-manager or scheduler components are enabled, for
+// callers that need the full image set upfront and not tied to one
+// particular cluster (e.g. the offline bundle builder)
+func RequiredImages(kubernetesVersion string) ([]string, error) {
+	versionBundle, err := constants.KubernetesVersionBundle(kubernetesVersion)
+	if err != nil {
+		return nil, errors.Errorf("could not retrieve version bundle for version %q", kubernetesVersion)
+	}
+	return []string{
+		fmt.Sprintf(etcdImage, versionBundle.EtcdVersion),
+		fmt.Sprintf(kubeAPIServerImage, kubernetesVersion),
+		fmt.Sprintf(kubeControllerManagerImage, kubernetesVersion),
+		fmt.Sprintf(kubeSchedulerImage, kubernetesVersion),
+	}, nil
+}
+
 // Reconcile reconciles the control plane component
 func (controlPlane *ControlPlane) Reconcile(inquirer inquirer.ReconcilerInquirer) error {
 	component := inquirer.Component()
 	hypervisor := inquirer.Hypervisor()
 	cluster := inquirer.Cluster()
-	kubernetesVersion := inquirer.Cluster().KubernetesVersion
+	kubernetesVersion := cluster.EffectiveKubernetesVersion()
 	versionBundle, err := constants.KubernetesVersionBundle(kubernetesVersion)
 	if err != nil {
 		return errors.Errorf("could not retrieve version bundle for version %q", kubernetesVersion)
@@ -98,15 +240,33 @@ func (controlPlane *ControlPlane) Reconcile(inquirer inquirer.ReconcilerInquirer
 	if err := controlPlane.reconcileInputAndOutputEndpoints(inquirer); err != nil {
 		return err
 	}
-	err = hypervisor.EnsureImages(
-		fmt.Sprintf(etcdImage, versionBundle.EtcdVersion),
-		fmt.Sprintf(kubeAPIServerImage, kubernetesVersion),
-		fmt.Sprintf(kubeControllerManagerImage, kubernetesVersion),
-		fmt.Sprintf(kubeSchedulerImage, kubernetesVersion),
-	)
-	if err != nil {
+	images := []string{
+		componentImage(cluster, etcdImage, versionBundle.EtcdVersion),
+		componentImage(cluster, kubeAPIServerImage, kubernetesVersion),
+	}
+	if cluster.ControllerManagerEnabled() {
+		images = append(images, componentImage(cluster, kubeControllerManagerImage, kubernetesVersion))
+	}
+	if cluster.SchedulerEnabled() {
+		images = append(images, componentImage(cluster, kubeSchedulerImage, kubernetesVersion))
+	}
+	if cluster.EtcdServer.KineSQL != nil {
+		images = append(images, componentImage(cluster, kineImage, kineVersion))
+	}
+	if err := hypervisor.EnsureImages(images...); err != nil {
 		return err
 	}
+	for image, digest := range controlPlane.pinnedImageDigests(cluster, kubernetesVersion, versionBundle) {
+		if err := hypervisor.VerifyImageDigest(image, digest); err != nil {
+			return err
+		}
+	}
+	signaturePolicy := hypervisor.EffectiveImageSignaturePolicy(cluster.ImageSignaturePolicy)
+	for _, image := range images {
+		if err := hypervisor.VerifyImageSignature(cluster.Namespace, cluster.Name, component.Name, image, signaturePolicy); err != nil {
+			return errors.Wrapf(err, "image %q failed signature verification", image)
+		}
+	}
 	advertiseAddressHost, advertiseAddressPort, err := controlPlane.kubeAPIServerAdvertiseAddressAndPort(inquirer)
 	if err != nil {
 		return err
@@ -118,93 +278,261 @@ func (controlPlane *ControlPlane) Reconcile(inquirer inquirer.ReconcilerInquirer
 	if err != nil {
 		return err
 	}
-	if err := controlPlane.runEtcd(inquirer); err != nil {
+	if cluster.EtcdServer.External == nil {
+		if cluster.EtcdServer.KineSQL != nil {
+			if err := controlPlane.runKine(inquirer); err != nil {
+				return err
+			}
+		} else if err := controlPlane.runEtcd(inquirer); err != nil {
+			return err
+		}
+	}
+	if cluster.EtcdServer.EventsEnabled {
+		if err := controlPlane.runEventsEtcd(inquirer); err != nil {
+			return err
+		}
+	}
+	// kubeControllerManagerArguments intentionally does not set
+	// leader-elect: kube-controller-manager (and kube-scheduler,
+	// below) already default it to true, which is what lets one
+	// instance per control plane replica run safely without
+	// stepping on each other once ControlPlaneReplicas is greater
+	// than one, coordinating through Lease objects in the tenant
+	// cluster's own apiserver
+	kubeControllerManagerArguments := map[string]string{}
+	if cluster.ControllerManagerEnabled() {
+		kubeControllerManagerArguments = map[string]string{
+			"kubeconfig":                       componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "controller-manager.kubeconfig"),
+			"controllers":                      "*,tokencleaner",
+			"service-account-private-key-file": componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "service-account.key"),
+			"cluster-signing-cert-file":        componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "cluster-signing-ca.crt"),
+			"cluster-signing-key-file":         componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "cluster-signing-ca.key"),
+			"cluster-cidr":                     cluster.ClusterCIDR,
+			"service-cluster-ip-range":         cluster.ServiceCIDR,
+			"allocate-node-cidrs":              "true",
+		}
+		if cluster.NodeCIDRMaskSize > 0 {
+			kubeControllerManagerArguments["node-cidr-mask-size"] = strconv.Itoa(cluster.NodeCIDRMaskSize)
+		}
+		if cluster.NodeCIDRMaskSizeIPv4 > 0 {
+			kubeControllerManagerArguments["node-cidr-mask-size-ipv4"] = strconv.Itoa(cluster.NodeCIDRMaskSizeIPv4)
+		}
+		if cluster.NodeCIDRMaskSizeIPv6 > 0 {
+			kubeControllerManagerArguments["node-cidr-mask-size-ipv6"] = strconv.Itoa(cluster.NodeCIDRMaskSizeIPv6)
+		}
+	}
+	kubeAPIServerArguments := map[string]string{
+		"advertise-address":               advertiseAddressHost,
+		"secure-port":                     strconv.Itoa(advertiseAddressPort),
+		"etcd-servers":                    strings.Join(controlPlane.etcdClientEndpoints(inquirer), ","),
+		"etcd-cafile":                     componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-ca.crt"),
+		"etcd-certfile":                   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.crt"),
+		"etcd-keyfile":                    componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.key"),
+		"anonymous-auth":                  strconv.FormatBool(cluster.APIServer.AnonymousAuth),
+		"profiling":                       strconv.FormatBool(cluster.APIServer.EnableProfiling),
+		"authorization-mode":              "Node,RBAC",
+		"enable-bootstrap-token-auth":     "true",
+		"allow-privileged":                "true",
+		"tls-cert-file":                   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver.crt"),
+		"tls-private-key-file":            componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver.key"),
+		"client-ca-file":                  componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-client-ca.crt"),
+		"service-account-key-file":        componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "service-account-pub.key"),
+		"kubelet-certificate-authority":   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "kubelet-ca.crt"),
+		"kubelet-client-certificate":      componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-kubelet-client.crt"),
+		"kubelet-client-key":              componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-kubelet-client.key"),
+		"kubelet-preferred-address-types": "ExternalIP,ExternalDNS,InternalIP,InternalDNS,Hostname",
+		"service-cluster-ip-range":        cluster.ServiceCIDR,
+	}
+	if cluster.APIServer.EventTTL != "" {
+		kubeAPIServerArguments["event-ttl"] = cluster.APIServer.EventTTL
+	}
+	if cluster.EtcdServer.EventsEnabled {
+		eventsEtcdClientEndpoint, err := controlPlane.eventsEtcdClientEndpoint(inquirer)
+		if err != nil {
+			return err
+		}
+		kubeAPIServerArguments["etcd-servers-overrides"] = fmt.Sprintf("/events#%s", eventsEtcdClientEndpoint)
+	}
+	supportsInsecurePortFlag, err := cluster.SupportsInsecurePortFlag()
+	if err != nil {
 		return err
 	}
-	kubeControllerManagerArguments := map[string]string{
-		"kubeconfig":                       componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "controller-manager.kubeconfig"),
-		"controllers":                      "*,tokencleaner",
-		"service-account-private-key-file": componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "service-account.key"),
-		"cluster-signing-cert-file":        componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "cluster-signing-ca.crt"),
-		"cluster-signing-key-file":         componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "cluster-signing-ca.key"),
-		"cluster-cidr":                     cluster.ClusterCIDR,
-		"service-cluster-ip-range":         cluster.ServiceCIDR,
-		"allocate-node-cidrs":              "true",
+	if supportsInsecurePortFlag {
+		if cluster.APIServer.EnableInsecurePort {
+			kubeAPIServerArguments["insecure-port"] = "8080"
+		} else {
+			kubeAPIServerArguments["insecure-port"] = "0"
+		}
+	}
+	if cluster.Authentication != nil && len(cluster.Authentication.JWT) > 0 {
+		usesStructuredAuthenticationConfiguration, err := cluster.UsesStructuredAuthenticationConfiguration()
+		if err != nil {
+			return err
+		}
+		if usesStructuredAuthenticationConfiguration {
+			kubeAPIServerArguments["authentication-config"] = componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "authentication-config.yaml")
+		} else {
+			for flag, value := range cluster.LegacyOIDCFlags() {
+				kubeAPIServerArguments[flag] = value
+			}
+		}
 	}
-	if cluster.NodeCIDRMaskSize > 0 {
-		kubeControllerManagerArguments["node-cidr-mask-size"] = strconv.Itoa(cluster.NodeCIDRMaskSize)
+	if cluster.APIServer.AuditLog != nil {
+		kubeAPIServerArguments["audit-policy-file"] = componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "audit-policy.yaml")
+		if cluster.APIServer.AuditLog.Path != "" {
+			kubeAPIServerArguments["audit-log-path"] = cluster.APIServer.AuditLog.Path
+		}
+		if cluster.APIServer.AuditLog.Webhook != nil {
+			kubeAPIServerArguments["audit-webhook-config-file"] = componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "audit-webhook-config.yaml")
+		}
+	}
+	sizePreset := cluster.SizePreset()
+	if sizePreset.APIServerMaxRequestsInflight > 0 {
+		kubeAPIServerArguments["max-requests-inflight"] = strconv.Itoa(sizePreset.APIServerMaxRequestsInflight)
 	}
-	if cluster.NodeCIDRMaskSizeIPv4 > 0 {
-		kubeControllerManagerArguments["node-cidr-mask-size-ipv4"] = strconv.Itoa(cluster.NodeCIDRMaskSizeIPv4)
+	if sizePreset.APIServerMaxMutatingRequestsInflight > 0 {
+		kubeAPIServerArguments["max-mutating-requests-inflight"] = strconv.Itoa(sizePreset.APIServerMaxMutatingRequestsInflight)
 	}
-	if cluster.NodeCIDRMaskSizeIPv6 > 0 {
-		kubeControllerManagerArguments["node-cidr-mask-size-ipv6"] = strconv.Itoa(cluster.NodeCIDRMaskSizeIPv6)
+	kubeAPIServerMounts := map[string]string{
+		componentSecretsPath(cluster.Namespace, cluster.Name, component.Name): componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
+	}
+	if cluster.APIServer.AuditLog != nil && cluster.APIServer.AuditLog.Path != "" {
+		auditLogDir := filepath.Dir(cluster.APIServer.AuditLog.Path)
+		kubeAPIServerMounts[auditLogDir] = auditLogDir
+	}
+	controlPlaneContainers := []pod.Container{
+		{
+			Name:    "kube-apiserver",
+			Image:   componentImage(cluster, kubeAPIServerImage, kubernetesVersion),
+			Command: []string{"kube-apiserver"},
+			Args:    component.ArgsFromMap(kubeAPIServerArguments),
+			Mounts:  kubeAPIServerMounts,
+		},
+	}
+	controlPlanePorts := map[int]int{
+		apiserverHostPort: advertiseAddressPort,
+	}
+	if cluster.ControllerManagerEnabled() {
+		controllerManagerHostPort, err := component.RequestPort(hypervisor, ControllerManagerHostPortName)
+		if err != nil {
+			return err
+		}
+		controlPlanePorts[controllerManagerHostPort] = controllerManagerSecurePort
+		controlPlaneContainers = append(controlPlaneContainers, pod.Container{
+			Name:    "kube-controller-manager",
+			Image:   componentImage(cluster, kubeControllerManagerImage, kubernetesVersion),
+			Command: []string{"kube-controller-manager"},
+			Args:    component.ArgsFromMap(kubeControllerManagerArguments),
+			Mounts: map[string]string{
+				componentSecretsPath(cluster.Namespace, cluster.Name, component.Name): componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
+			},
+		})
+	}
+	if cluster.SchedulerEnabled() {
+		schedulerHostPort, err := component.RequestPort(hypervisor, SchedulerHostPortName)
+		if err != nil {
+			return err
+		}
+		controlPlanePorts[schedulerHostPort] = schedulerSecurePort
+		controlPlaneContainers = append(controlPlaneContainers, pod.Container{
+			Name:    "kube-scheduler",
+			Image:   componentImage(cluster, kubeSchedulerImage, kubernetesVersion),
+			Command: []string{"kube-scheduler"},
+			Args: component.ArgsFromMap(map[string]string{
+				"kubeconfig": componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "scheduler.kubeconfig"),
+			}),
+			Mounts: map[string]string{
+				componentSecretsPath(cluster.Namespace, cluster.Name, component.Name): componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
+			},
+		})
 	}
+	controlPlanePod := pod.NewPod(
+		controlPlane.controlPlanePodName(inquirer),
+		controlPlaneContainers,
+		controlPlanePorts,
+		pod.PrivilegesUnprivileged,
+	)
+	if err := validateExtraHostPathMounts(hypervisor, cluster.ExtraHostPathMounts); err != nil {
+		return err
+	}
+	controlPlanePod.ApplyEnv(hypervisor.ProxyEnv(cluster.Proxy))
+	controlPlanePod.ApplyEnv(cluster.ExtraEnv)
+	controlPlanePod.ApplyMounts(extraHostPathMounts(cluster.ExtraHostPathMounts))
+	controlPlanePod.ApplyLabels(cluster.Labels)
+	controlPlanePod.ApplyAnnotations(cluster.Annotations)
 	_, err = hypervisor.EnsurePod(
 		cluster.Namespace,
 		cluster.Name,
 		component.Name,
-		pod.NewPod(
-			controlPlane.controlPlanePodName(inquirer),
-			[]pod.Container{
-				{
-					Name:    "kube-apiserver",
-					Image:   fmt.Sprintf(kubeAPIServerImage, kubernetesVersion),
-					Command: []string{"kube-apiserver"},
-					Args: component.ArgsFromMap(map[string]string{
-						"insecure-port":                   "0",
-						"advertise-address":               advertiseAddressHost,
-						"secure-port":                     strconv.Itoa(advertiseAddressPort),
-						"etcd-servers":                    strings.Join(controlPlane.etcdClientEndpoints(inquirer), ","),
-						"etcd-cafile":                     componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-ca.crt"),
-						"etcd-certfile":                   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.crt"),
-						"etcd-keyfile":                    componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.key"),
-						"anonymous-auth":                  "true",
-						"authorization-mode":              "Node,RBAC",
-						"enable-bootstrap-token-auth":     "true",
-						"allow-privileged":                "true",
-						"tls-cert-file":                   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver.crt"),
-						"tls-private-key-file":            componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver.key"),
-						"client-ca-file":                  componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-client-ca.crt"),
-						"service-account-key-file":        componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "service-account-pub.key"),
-						"kubelet-certificate-authority":   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "kubelet-ca.crt"),
-						"kubelet-client-certificate":      componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-kubelet-client.crt"),
-						"kubelet-client-key":              componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-kubelet-client.key"),
-						"kubelet-preferred-address-types": "ExternalIP,ExternalDNS,InternalIP,InternalDNS,Hostname",
-						"service-cluster-ip-range":        cluster.ServiceCIDR,
-					}),
-					Mounts: map[string]string{
-						componentSecretsPath(cluster.Namespace, cluster.Name, component.Name): componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
-					},
-				},
-				{
-					Name:    "kube-controller-manager",
-					Image:   fmt.Sprintf(kubeControllerManagerImage, kubernetesVersion),
-					Command: []string{"kube-controller-manager"},
-					Args:    component.ArgsFromMap(kubeControllerManagerArguments),
-					Mounts: map[string]string{
-						componentSecretsPath(cluster.Namespace, cluster.Name, component.Name): componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
-					},
-				},
-				{
-					Name:    "kube-scheduler",
-					Image:   fmt.Sprintf(kubeSchedulerImage, kubernetesVersion),
-					Command: []string{"kube-scheduler"},
-					Args: component.ArgsFromMap(map[string]string{
-						"kubeconfig": componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "scheduler.kubeconfig"),
-					}),
-					Mounts: map[string]string{
-						componentSecretsPath(cluster.Namespace, cluster.Name, component.Name): componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
-					},
-				},
-			},
-			map[int]int{
-				apiserverHostPort: advertiseAddressPort,
-			},
-			pod.PrivilegesUnprivileged,
-		),
+		controlPlanePod,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if kubernetesVersion == cluster.KubernetesVersion {
+		component.Conditions.SetCondition(componentapi.UpgradePending, conditions.ConditionFalse)
+	} else {
+		component.Conditions.SetCondition(componentapi.UpgradePending, conditions.ConditionTrue)
+	}
+	return nil
+}
+
+// IsReady reports whether the kube-apiserver, every etcd member, and
+// any enabled controller-manager and scheduler of this control plane
+// instance are responding to requests
+func (controlPlane *ControlPlane) IsReady(inquirer inquirer.ReconcilerInquirer) (bool, error) {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	cluster := inquirer.Cluster()
+	outputEndpoint, hasOutputEndpoint := component.OutputEndpoints[component.Name]
+	if !hasOutputEndpoint {
+		return false, nil
+	}
+	checks := []healthcheck.Check{
+		healthcheck.HTTPHealthzCheck{
+			TargetName: fmt.Sprintf("%s/kube-apiserver", component.Name),
+			URL:        outputEndpoint + "/healthz",
+		},
+	}
+	for _, etcdClientEndpoint := range controlPlane.etcdClientEndpoints(inquirer) {
+		checks = append(checks, healthcheck.EtcdEndpointCheck{
+			TargetName: fmt.Sprintf("%s/etcd", component.Name),
+			Endpoint:   etcdClientEndpoint,
+		})
+	}
+	if cluster.EtcdServer.EventsEnabled {
+		if eventsEtcdClientEndpoint, err := controlPlane.eventsEtcdClientEndpoint(inquirer); err == nil {
+			checks = append(checks, healthcheck.EtcdEndpointCheck{
+				TargetName: fmt.Sprintf("%s/etcd-events", component.Name),
+				Endpoint:   eventsEtcdClientEndpoint,
+			})
+		}
+	}
+	if cluster.ControllerManagerEnabled() {
+		if controllerManagerHostPort, exists := component.AllocatedHostPorts[ControllerManagerHostPortName]; exists {
+			checks = append(checks, healthcheck.TCPConnectCheck{
+				TargetName: fmt.Sprintf("%s/kube-controller-manager", component.Name),
+				Address:    net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(controllerManagerHostPort)),
+			})
+		}
+	}
+	if cluster.SchedulerEnabled() {
+		if schedulerHostPort, exists := component.AllocatedHostPorts[SchedulerHostPortName]; exists {
+			checks = append(checks, healthcheck.TCPConnectCheck{
+				TargetName: fmt.Sprintf("%s/kube-scheduler", component.Name),
+				Address:    net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(schedulerHostPort)),
+			})
+		}
+	}
+	config := healthcheck.Config{
+		TimeoutSeconds:  cluster.HealthCheckTimeoutSeconds,
+		IntervalSeconds: cluster.HealthCheckIntervalSeconds,
+	}
+	if err := healthcheck.Ready(checks, config); err != nil {
+		klog.V(2).Infof("component %q not ready yet: %v", component.Name, err)
+		return false, nil
+	}
+	return true, nil
 }
 
 func (controlPlane *ControlPlane) kubeAPIServerSANs(inquirer inquirer.ReconcilerInquirer) ([]string, error) {
@@ -241,15 +569,25 @@ func (controlPlane *ControlPlane) uploadFiles(inquirer inquirer.ReconcilerInquir
 	cluster := inquirer.Cluster()
 	component := inquirer.Component()
 	hypervisor := inquirer.Hypervisor()
-	etcdAPIServerClientCertificate, err := component.ClientCertificate(
-		cluster.CertificateAuthorities.EtcdClient,
-		"apiserver-etcd-client",
-		fmt.Sprintf("apiserver-etcd-client-%s", component.Name),
-		[]string{cluster.Name},
-		[]string{},
-	)
-	if err != nil {
-		return err
+	var etcdCACertificate string
+	var etcdAPIServerClientCertificate *certificates.Certificate
+	if cluster.EtcdServer.External != nil {
+		etcdCACertificate = cluster.EtcdServer.External.CA
+		etcdAPIServerClientCertificate = cluster.EtcdServer.External.ClientCertificate
+	} else {
+		etcdCACertificate = cluster.EtcdServer.CA.Certificate
+		var err error
+		etcdAPIServerClientCertificate, err = component.ClientCertificate(
+			cluster.CertificateAuthorities.EtcdClient,
+			"apiserver-etcd-client",
+			fmt.Sprintf("apiserver-etcd-client-%s", component.Name),
+			[]string{cluster.Name},
+			[]string{},
+			cluster.RotationThreshold(),
+		)
+		if err != nil {
+			return err
+		}
 	}
 	kubeAPIServerExtraSANs, err := controlPlane.kubeAPIServerSANs(inquirer)
 	if err != nil {
@@ -261,6 +599,7 @@ func (controlPlane *ControlPlane) uploadFiles(inquirer inquirer.ReconcilerInquir
 		"kube-apiserver",
 		[]string{"kube-apiserver"},
 		kubeAPIServerExtraSANs,
+		cluster.RotationThreshold(),
 	)
 	if err != nil {
 		return err
@@ -271,6 +610,7 @@ func (controlPlane *ControlPlane) uploadFiles(inquirer inquirer.ReconcilerInquir
 		"kube-apiserver-kubelet-client",
 		[]string{constantsapi.OneInfraKubeletProxierExtraGroups},
 		[]string{},
+		cluster.RotationThreshold(),
 	)
 	if err != nil {
 		return err
@@ -280,40 +620,72 @@ func (controlPlane *ControlPlane) uploadFiles(inquirer inquirer.ReconcilerInquir
 		return err
 	}
 	apiserverURL := url.URL{Scheme: "https", Host: net.JoinHostPort("127.0.0.1", strconv.Itoa(advertiseAddressPort))}
-	controllerManagerKubeConfig, err := component.KubeConfig(cluster, apiserverURL.String(), "controller-manager")
-	if err != nil {
-		return err
+	files := map[string]string{
+		// etcd secrets
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-ca.crt"):               etcdCACertificate,
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.crt"): etcdAPIServerClientCertificate.Certificate,
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.key"): etcdAPIServerClientCertificate.PrivateKey,
+		// API server secrets
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-client-ca.crt"):      cluster.CertificateAuthorities.APIServerClient.Certificate,
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver.crt"):                apiServerCertificate.Certificate,
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver.key"):                apiServerCertificate.PrivateKey,
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "service-account-pub.key"):      cluster.APIServer.ServiceAccount.PublicKey,
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-kubelet-client.crt"): kubeletClientCertificate.Certificate,
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-kubelet-client.key"): kubeletClientCertificate.PrivateKey,
+		// kubelet secrets
+		componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "kubelet-ca.crt"): cluster.CertificateAuthorities.Kubelet.Certificate,
+	}
+	if cluster.ControllerManagerEnabled() {
+		controllerManagerKubeConfig, err := component.KubeConfig(cluster, apiserverURL.String(), "controller-manager")
+		if err != nil {
+			return err
+		}
+		// controller-manager secrets
+		files[componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "controller-manager.kubeconfig")] = controllerManagerKubeConfig
+		files[componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "service-account.key")] = cluster.APIServer.ServiceAccount.PrivateKey
+		files[componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "cluster-signing-ca.crt")] = cluster.CertificateAuthorities.CertificateSigner.Certificate
+		files[componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "cluster-signing-ca.key")] = cluster.CertificateAuthorities.CertificateSigner.PrivateKey
+	}
+	if cluster.SchedulerEnabled() {
+		schedulerKubeConfig, err := component.KubeConfig(cluster, apiserverURL.String(), "scheduler")
+		if err != nil {
+			return err
+		}
+		// scheduler secrets
+		files[componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "scheduler.kubeconfig")] = schedulerKubeConfig
 	}
-	schedulerKubeConfig, err := component.KubeConfig(cluster, apiserverURL.String(), "scheduler")
-	if err != nil {
-		return err
+	if cluster.Authentication != nil && len(cluster.Authentication.JWT) > 0 {
+		usesStructuredAuthenticationConfiguration, err := cluster.UsesStructuredAuthenticationConfiguration()
+		if err != nil {
+			return err
+		}
+		if usesStructuredAuthenticationConfiguration {
+			authenticationConfigYAML, err := cluster.AuthenticationConfigurationYAML()
+			if err != nil {
+				return err
+			}
+			files[componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "authentication-config.yaml")] = authenticationConfigYAML
+		}
+	}
+	if cluster.APIServer.AuditLog != nil {
+		auditPolicyYAML, err := cluster.AuditPolicyYAML()
+		if err != nil {
+			return err
+		}
+		files[componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "audit-policy.yaml")] = auditPolicyYAML
+		if cluster.APIServer.AuditLog.Webhook != nil {
+			auditWebhookConfigYAML, err := cluster.AuditWebhookConfigYAML()
+			if err != nil {
+				return err
+			}
+			files[componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "audit-webhook-config.yaml")] = auditWebhookConfigYAML
+		}
 	}
 	return hypervisor.UploadFiles(
 		cluster.Namespace,
 		cluster.Name,
 		component.Name,
-		map[string]string{
-			// etcd secrets
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-ca.crt"):               cluster.EtcdServer.CA.Certificate,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.crt"): etcdAPIServerClientCertificate.Certificate,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.key"): etcdAPIServerClientCertificate.PrivateKey,
-			// API server secrets
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-client-ca.crt"):      cluster.CertificateAuthorities.APIServerClient.Certificate,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver.crt"):                apiServerCertificate.Certificate,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver.key"):                apiServerCertificate.PrivateKey,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "service-account-pub.key"):      cluster.APIServer.ServiceAccount.PublicKey,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-kubelet-client.crt"): kubeletClientCertificate.Certificate,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-kubelet-client.key"): kubeletClientCertificate.PrivateKey,
-			// controller-manager secrets
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "controller-manager.kubeconfig"): controllerManagerKubeConfig,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "service-account.key"):           cluster.APIServer.ServiceAccount.PrivateKey,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "cluster-signing-ca.crt"):        cluster.CertificateAuthorities.CertificateSigner.Certificate,
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "cluster-signing-ca.key"):        cluster.CertificateAuthorities.CertificateSigner.PrivateKey,
-			// scheduler secrets
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "scheduler.kubeconfig"): schedulerKubeConfig,
-			// kubelet secrets
-			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "kubelet-ca.crt"): cluster.CertificateAuthorities.Kubelet.Certificate,
-		},
+		files,
 	)
 }
 
@@ -356,6 +728,16 @@ func (controlPlane *ControlPlane) stopControlPlane(inquirer inquirer.ReconcilerI
 		if err := component.FreePort(hypervisor, APIServerHostPortName); err != nil {
 			return errors.Wrapf(err, "could not free port %q for hypervisor %q", APIServerHostPortName, hypervisor.Name)
 		}
+		if _, exists := component.AllocatedHostPorts[ControllerManagerHostPortName]; exists {
+			if err := component.FreePort(hypervisor, ControllerManagerHostPortName); err != nil {
+				return errors.Wrapf(err, "could not free port %q for hypervisor %q", ControllerManagerHostPortName, hypervisor.Name)
+			}
+		}
+		if _, exists := component.AllocatedHostPorts[SchedulerHostPortName]; exists {
+			if err := component.FreePort(hypervisor, SchedulerHostPortName); err != nil {
+				return errors.Wrapf(err, "could not free port %q for hypervisor %q", SchedulerHostPortName, hypervisor.Name)
+			}
+		}
 	}
 	return err
 }
@@ -369,14 +751,24 @@ func (controlPlane *ControlPlane) ReconcileDeletion(inquirer inquirer.Reconciler
 	if err := controlPlane.stopControlPlane(inquirer); err != nil {
 		return err
 	}
-	if inquirer.Cluster().DeletionTimestamp == nil {
+	kineSQL := inquirer.Cluster().EtcdServer.KineSQL != nil
+	if inquirer.Cluster().DeletionTimestamp == nil && !kineSQL {
 		if err := controlPlane.removeEtcdMember(inquirer); err != nil {
 			return err
 		}
 	}
-	if err := controlPlane.stopEtcd(inquirer); err != nil {
+	if kineSQL {
+		if err := controlPlane.stopKine(inquirer); err != nil {
+			return err
+		}
+	} else if err := controlPlane.stopEtcd(inquirer); err != nil {
 		return err
 	}
+	if inquirer.Cluster().EtcdServer.EventsEnabled {
+		if err := controlPlane.stopEventsEtcd(inquirer); err != nil {
+			return err
+		}
+	}
 	return controlPlane.hostCleanup(inquirer)
 }
 
@@ -384,6 +776,26 @@ func (controlPlane *ControlPlane) hostCleanup(inquirer inquirer.ReconcilerInquir
 	component := inquirer.Component()
 	hypervisor := inquirer.Hypervisor()
 	cluster := inquirer.Cluster()
+	etcdDataPath := etcdDataHostPath(hypervisor, cluster.Namespace, cluster.Name, component.Name)
+	if cluster.EtcdServer.KineSQL != nil {
+		etcdDataPath = kineDataHostPath(hypervisor, cluster.Namespace, cluster.Name, component.Name)
+	}
+	removePaths := etcdDataPath
+	if cluster.EtcdServer.EventsEnabled {
+		removePaths = fmt.Sprintf("%s %s", etcdDataPath, eventsEtcdDataHostPath(hypervisor, cluster.Namespace, cluster.Name, component.Name))
+	}
+	etcdCleanupArgs := fmt.Sprintf(
+		"rm -rf %s && ((rmdir %s && rmdir %s && rmdir %s) || true)",
+		removePaths,
+		componentStoragePath(cluster.Namespace, cluster.Name, component.Name),
+		clusterStoragePath(cluster.Namespace, cluster.Name),
+		namespacedClusterStoragePath(cluster.Namespace),
+	)
+	etcdCleanupMount := globalStoragePath()
+	if hypervisor.EtcdDataDir != "" {
+		etcdCleanupArgs = fmt.Sprintf("rm -rf %s", removePaths)
+		etcdCleanupMount = hypervisor.EtcdDataDir
+	}
 	res := hypervisor.RunAndWaitForPod(
 		cluster.Namespace,
 		cluster.Name,
@@ -397,16 +809,10 @@ func (controlPlane *ControlPlane) hostCleanup(inquirer inquirer.ReconcilerInquir
 					Command: []string{"/bin/sh"},
 					Args: []string{
 						"-c",
-						fmt.Sprintf(
-							"rm -rf %s && ((rmdir %s && rmdir %s && rmdir %s) || true)",
-							subcomponentStoragePath(cluster.Namespace, cluster.Name, component.Name, "etcd"),
-							componentStoragePath(cluster.Namespace, cluster.Name, component.Name),
-							clusterStoragePath(cluster.Namespace, cluster.Name),
-							namespacedClusterStoragePath(cluster.Namespace),
-						),
+						etcdCleanupArgs,
 					},
 					Mounts: map[string]string{
-						globalStoragePath(): globalStoragePath(),
+						etcdCleanupMount: etcdCleanupMount,
 					},
 				},
 				{
@@ -436,3 +842,30 @@ func (controlPlane *ControlPlane) hostCleanup(inquirer inquirer.ReconcilerInquir
 	}
 	return res
 }
+
+// extraHostPathMounts converts a cluster's ExtraHostPathMounts into
+// the host path to container path mapping pod.Pod.ApplyMounts expects
+func extraHostPathMounts(hostPathMounts []clusterv1alpha1.HostPathMount) map[string]string {
+	mounts := map[string]string{}
+	for _, hostPathMount := range hostPathMounts {
+		containerPath := hostPathMount.ContainerPath
+		if containerPath == "" {
+			containerPath = hostPathMount.HostPath
+		}
+		mounts[hostPathMount.HostPath] = containerPath
+	}
+	return mounts
+}
+
+// validateExtraHostPathMounts rejects scheduling a component on
+// hypervisor if any of hostPathMounts falls outside of the host
+// paths hypervisor allows through its
+// AllowedExtraHostPathMountPrefixes
+func validateExtraHostPathMounts(hypervisor *infra.Hypervisor, hostPathMounts []clusterv1alpha1.HostPathMount) error {
+	for _, hostPathMount := range hostPathMounts {
+		if !hypervisor.AllowsExtraHostPathMount(hostPathMount.HostPath) {
+			return errors.Errorf("hypervisor %q does not allow extra host path mount %q", hypervisor.Name, hostPathMount.HostPath)
+		}
+	}
+	return nil
+}
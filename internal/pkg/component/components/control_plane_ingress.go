@@ -20,15 +20,21 @@ import (
 	"bytes"
 	"crypto/sha1"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	componentapi "github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/component/healthcheck"
 	"github.com/oneinfra/oneinfra/internal/pkg/infra"
 	"github.com/oneinfra/oneinfra/internal/pkg/infra/pod"
 	"github.com/oneinfra/oneinfra/internal/pkg/inquirer"
@@ -39,6 +45,24 @@ const (
 )
 
 const (
+	// IngressStatsHostPortName represents the haproxy stats host port
+	IngressStatsHostPortName = "ingress-stats"
+
+	haProxyStatsPort = 9404
+
+	haProxyStatsTimeout = 5 * time.Second
+)
+
+const (
+	// defaultIngressClientTimeoutSeconds is the default maximum time
+	// to wait for data from the client before closing the connection
+	defaultIngressClientTimeoutSeconds = 60
+
+	// defaultIngressServerTimeoutSeconds is the default maximum time
+	// to wait for data from a kube-apiserver backend before closing
+	// the connection
+	defaultIngressServerTimeoutSeconds = 60
+
 	haProxyTemplate = `global
   log /dev/log local0
   log /dev/log local1 notice
@@ -48,10 +72,22 @@ defaults
   mode tcp
   option dontlognull
   timeout connect 10s
-  timeout client  60s
-  timeout server  60s
+  timeout client  {{ .ClientTimeoutSeconds }}s
+  timeout server  {{ .ServerTimeoutSeconds }}s
+frontend stats
+  mode http
+  bind *:{{ .StatsPort }}
+  stats enable
+  stats uri /
+  stats refresh 10s
 frontend control-plane
   bind *:6443
+  {{- if .MaxConnections }}
+  maxconn {{ .MaxConnections }}
+  {{- end }}
+  {{- if .ClientKeepaliveEnabled }}
+  option clitcpka
+  {{- end }}
   default_backend apiservers
 backend apiservers
   option httpchk GET /healthz
@@ -75,6 +111,9 @@ func (ingress *ControlPlaneIngress) PreReconcile(inquirer inquirer.ReconcilerInq
 	if _, err := component.RequestPort(hypervisor, APIServerHostPortName); err != nil {
 		return err
 	}
+	if _, err := component.RequestPort(hypervisor, IngressStatsHostPortName); err != nil {
+		return err
+	}
 	cluster := inquirer.Cluster()
 	if cluster.VPN.Enabled {
 		if _, err := component.RequestPort(hypervisor, WireguardHostPortName); err != nil {
@@ -138,6 +177,10 @@ func (ingress *ControlPlaneIngress) Reconcile(inquirer inquirer.ReconcilerInquir
 	if err != nil {
 		return err
 	}
+	statsHostPort, err := component.RequestPort(hypervisor, IngressStatsHostPortName)
+	if err != nil {
+		return err
+	}
 	haProxyConfig, err := ingress.haProxyConfiguration(inquirer, clusterComponents)
 	if err != nil {
 		return err
@@ -152,29 +195,34 @@ func (ingress *ControlPlaneIngress) Reconcile(inquirer inquirer.ReconcilerInquir
 	if err != nil {
 		return err
 	}
+	ingressPod := pod.NewPod(
+		ingress.ingressPodName(inquirer),
+		[]pod.Container{
+			{
+				Name:  "haproxy",
+				Image: haProxyImage,
+				Mounts: map[string]string{
+					componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "haproxy.cfg"): "/etc/haproxy/haproxy.cfg",
+				},
+				Annotations: map[string]string{
+					"oneinfra/haproxy-config-sha1sum": fmt.Sprintf("%x", sha1.Sum([]byte(haProxyConfig))),
+				},
+			},
+		},
+		map[int]int{
+			apiserverHostPort: 6443,
+			statsHostPort:     haProxyStatsPort,
+		},
+		pod.PrivilegesUnprivileged,
+	)
+	ingressPod.ApplyEnv(hypervisor.ProxyEnv(cluster.Proxy))
+	ingressPod.ApplyLabels(cluster.Labels)
+	ingressPod.ApplyAnnotations(cluster.Annotations)
 	_, err = hypervisor.EnsurePod(
 		cluster.Namespace,
 		cluster.Name,
 		component.Name,
-		pod.NewPod(
-			ingress.ingressPodName(inquirer),
-			[]pod.Container{
-				{
-					Name:  "haproxy",
-					Image: haProxyImage,
-					Mounts: map[string]string{
-						componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "haproxy.cfg"): "/etc/haproxy/haproxy.cfg",
-					},
-					Annotations: map[string]string{
-						"oneinfra/haproxy-config-sha1sum": fmt.Sprintf("%x", sha1.Sum([]byte(haProxyConfig))),
-					},
-				},
-			},
-			map[int]int{
-				apiserverHostPort: 6443,
-			},
-			pod.PrivilegesUnprivileged,
-		),
+		ingressPod,
 	)
 	if err != nil {
 		return err
@@ -187,15 +235,67 @@ func (ingress *ControlPlaneIngress) Reconcile(inquirer inquirer.ReconcilerInquir
 	return nil
 }
 
+// IsReady reports whether the haproxy frontend of this control plane
+// ingress instance is accepting connections
+func (ingress *ControlPlaneIngress) IsReady(inquirer inquirer.ReconcilerInquirer) (bool, error) {
+	component := inquirer.Component()
+	cluster := inquirer.Cluster()
+	hypervisor := inquirer.Hypervisor()
+	apiserverHostPort, err := component.RequestPort(hypervisor, APIServerHostPortName)
+	if err != nil {
+		return false, nil
+	}
+	checks := []healthcheck.Check{
+		healthcheck.TCPConnectCheck{
+			TargetName: fmt.Sprintf("%s/haproxy", component.Name),
+			Address:    net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(apiserverHostPort)),
+		},
+	}
+	config := healthcheck.Config{
+		TimeoutSeconds:  cluster.HealthCheckTimeoutSeconds,
+		IntervalSeconds: cluster.HealthCheckIntervalSeconds,
+	}
+	if err := healthcheck.Ready(checks, config); err != nil {
+		klog.V(2).Infof("component %q not ready yet: %v", component.Name, err)
+		return false, nil
+	}
+	return true, nil
+}
+
 func (ingress *ControlPlaneIngress) haProxyConfiguration(inquirer inquirer.ReconcilerInquirer, clusterComponents componentapi.List) (string, error) {
 	template, err := template.New("").Parse(haProxyTemplate)
 	if err != nil {
 		return "", err
 	}
+	cluster := inquirer.Cluster()
+	clientTimeoutSeconds := defaultIngressClientTimeoutSeconds
+	serverTimeoutSeconds := defaultIngressServerTimeoutSeconds
+	maxConnections := 0
+	clientKeepaliveEnabled := false
+	if ingressTuning := cluster.IngressTuning; ingressTuning != nil {
+		if ingressTuning.ClientTimeoutSeconds > 0 {
+			clientTimeoutSeconds = ingressTuning.ClientTimeoutSeconds
+		}
+		if ingressTuning.ServerTimeoutSeconds > 0 {
+			serverTimeoutSeconds = ingressTuning.ServerTimeoutSeconds
+		}
+		maxConnections = ingressTuning.MaxConnections
+		clientKeepaliveEnabled = ingressTuning.ClientKeepaliveEnabled
+	}
 	haProxyConfigData := struct {
-		APIServers map[string]string
+		APIServers             map[string]string
+		ClientTimeoutSeconds   int
+		ServerTimeoutSeconds   int
+		MaxConnections         int
+		ClientKeepaliveEnabled bool
+		StatsPort              int
 	}{
-		APIServers: map[string]string{},
+		APIServers:             map[string]string{},
+		ClientTimeoutSeconds:   clientTimeoutSeconds,
+		ServerTimeoutSeconds:   serverTimeoutSeconds,
+		MaxConnections:         maxConnections,
+		ClientKeepaliveEnabled: clientKeepaliveEnabled,
+		StatsPort:              haProxyStatsPort,
 	}
 	for _, component := range clusterComponents {
 		apiserverHostPort, exists := component.AllocatedHostPorts[APIServerHostPortName]
@@ -230,6 +330,9 @@ func (ingress *ControlPlaneIngress) stopIngress(inquirer inquirer.ReconcilerInqu
 		if err := component.FreePort(hypervisor, APIServerHostPortName); err != nil {
 			return errors.Wrapf(err, "could not free port %q for hypervisor %q", APIServerHostPortName, hypervisor.Name)
 		}
+		if err := component.FreePort(hypervisor, IngressStatsHostPortName); err != nil {
+			return errors.Wrapf(err, "could not free port %q for hypervisor %q", IngressStatsHostPortName, hypervisor.Name)
+		}
 		if cluster.VPN.Enabled {
 			if err := component.FreePort(hypervisor, WireguardHostPortName); err != nil {
 				return errors.Wrapf(err, "could not free port %q for hypervisor %q", WireguardHostPortName, hypervisor.Name)
@@ -289,3 +392,78 @@ func (ingress *ControlPlaneIngress) hostCleanup(inquirer inquirer.ReconcilerInqu
 	}
 	return res
 }
+
+// CollectStats queries this control plane ingress instance's haproxy
+// stats endpoint and returns its current view of every apiserver
+// backend server, for the ingress stats scanner controller to record
+// on the cluster's Ingress status
+func (ingress *ControlPlaneIngress) CollectStats(inquirer inquirer.ReconcilerInquirer) ([]clusterv1alpha1.IngressBackendStatus, error) {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	statsHostPort, exists := component.AllocatedHostPorts[IngressStatsHostPortName]
+	if !exists {
+		return nil, errors.Errorf("ingress stats host port not found for component %q", component.Name)
+	}
+	statsEndpoint := url.URL{
+		Scheme:   "http",
+		Host:     net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(statsHostPort)),
+		Path:     "/",
+		RawQuery: "csv",
+	}
+	httpClient := http.Client{Timeout: haProxyStatsTimeout}
+	res, err := httpClient.Get(statsEndpoint.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not reach haproxy stats endpoint for component %q", component.Name)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return parseHAProxyBackendStats(string(body))
+}
+
+// parseHAProxyBackendStats parses the haproxy CSV stats page
+// (https://docs.haproxy.org/dev/management.html#9.3), returning the
+// status of every server behind the "apiservers" backend, keyed by
+// its server name, which is the control plane component it represents
+func parseHAProxyBackendStats(csv string) ([]clusterv1alpha1.IngressBackendStatus, error) {
+	lines := strings.Split(strings.TrimSpace(csv), "\n")
+	if len(lines) == 0 {
+		return nil, errors.New("empty haproxy stats response")
+	}
+	columns := strings.Split(strings.TrimPrefix(lines[0], "# "), ",")
+	columnIndex := map[string]int{}
+	for i, column := range columns {
+		columnIndex[column] = i
+	}
+	for _, requiredColumn := range []string{"pxname", "svname", "scur", "econ", "eresp", "status"} {
+		if _, exists := columnIndex[requiredColumn]; !exists {
+			return nil, errors.Errorf("haproxy stats response is missing the %q column", requiredColumn)
+		}
+	}
+	backends := []clusterv1alpha1.IngressBackendStatus{}
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if fields[columnIndex["pxname"]] != "apiservers" {
+			continue
+		}
+		serverName := fields[columnIndex["svname"]]
+		if serverName == "BACKEND" || serverName == "FRONTEND" {
+			continue
+		}
+		currentSessions, _ := strconv.Atoi(fields[columnIndex["scur"]])
+		connectionErrors, _ := strconv.ParseInt(fields[columnIndex["econ"]], 10, 64)
+		responseErrors, _ := strconv.ParseInt(fields[columnIndex["eresp"]], 10, 64)
+		backends = append(backends, clusterv1alpha1.IngressBackendStatus{
+			Component:       serverName,
+			Up:              strings.HasPrefix(fields[columnIndex["status"]], "UP"),
+			CurrentSessions: currentSessions,
+			ErrorResponses:  connectionErrors + responseErrors,
+		})
+	}
+	return backends, nil
+}
@@ -23,9 +23,11 @@ import (
 	"encoding/pem"
 	goerrors "errors"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -36,6 +38,8 @@ import (
 	"k8s.io/klog/v2"
 
 	"github.com/oneinfra/oneinfra/internal/pkg/component"
+	"github.com/oneinfra/oneinfra/internal/pkg/component/healthcheck"
+	"github.com/oneinfra/oneinfra/internal/pkg/conditions"
 	"github.com/oneinfra/oneinfra/internal/pkg/constants"
 	"github.com/oneinfra/oneinfra/internal/pkg/infra/pod"
 	"github.com/oneinfra/oneinfra/internal/pkg/inquirer"
@@ -49,12 +53,35 @@ const (
 	// EtcdClientHostPortName represents the etcd client host port
 	// allocation name
 	EtcdClientHostPortName = "etcd-client"
+
+	// EtcdEventsPeerHostPortName represents the dedicated events
+	// etcd peer host port allocation name
+	EtcdEventsPeerHostPortName = "etcd-events-peer"
+
+	// EtcdEventsClientHostPortName represents the dedicated events
+	// etcd client host port allocation name
+	EtcdEventsClientHostPortName = "etcd-events-client"
 )
 
 const (
-	etcdDialTimeout = 5 * time.Second
-	etcdImage       = "oneinfra/etcd:%s"
-	etcdDataDir     = "/var/lib/etcd"
+	etcdDialTimeout     = 5 * time.Second
+	etcdSnapshotTimeout = time.Minute
+	etcdImage           = "oneinfra/etcd:%s"
+	etcdDataDir         = "/var/lib/etcd"
+	etcdEventsDataDir   = "/var/lib/etcd-events"
+
+	// etcdDefragmentationInterval is the minimum time between two
+	// defragmentations of the same etcd member. Each member is
+	// defragmented by its own component's reconcile, on its own
+	// schedule; the schedules of different members are not
+	// coordinated, so several members can be defragmented at once
+	etcdDefragmentationInterval = 24 * time.Hour
+
+	// etcdBackupInterval is the minimum time between two snapshot
+	// backups of the same etcd member
+	etcdBackupInterval = 24 * time.Hour
+
+	etcdBackupTimestampLayout = "20060102T150405"
 )
 
 var (
@@ -88,6 +115,7 @@ func (controlPlane *ControlPlane) etcdClientWithEndpoints(inquirer inquirer.Reco
 		"oneinfra-client",
 		[]string{cluster.Name},
 		[]string{},
+		cluster.RotationThreshold(),
 	)
 	if err != nil {
 		return nil, err
@@ -130,8 +158,39 @@ func (controlPlane *ControlPlane) etcdPeerEndpoints(inquirer inquirer.Reconciler
 	return endpoints
 }
 
+// etcdClientEndpoint weights a single etcd client endpoint so
+// etcdClientEndpoints can order the final --etcd-servers list
+// instead of handing it to kube-apiserver in map iteration order
+type etcdClientEndpoint struct {
+	endpoint string
+	local    bool
+	healthy  bool
+}
+
+// etcdClientEndpoints returns the etcd client endpoints
+// kube-apiserver should talk to: the externally configured ones
+// when this cluster uses an external etcd cluster, this replica's
+// own kine endpoint when the cluster uses a kine SQL backend (kine
+// replicas do not form a member list to choose from), or the client
+// endpoint of every scheduled control plane replica's own etcd
+// member otherwise, ordered so that the member collocated on the
+// same hypervisor as this kube-apiserver is preferred, then members
+// that are currently passing their health check, so failover away
+// from a down or remote member is predictable rather than
+// map-iteration-order luck
 func (controlPlane *ControlPlane) etcdClientEndpoints(inquirer inquirer.ReconcilerInquirer) []string {
-	endpoints := []string{}
+	if externalEtcd := inquirer.Cluster().EtcdServer.External; externalEtcd != nil {
+		return externalEtcd.Endpoints
+	}
+	if inquirer.Cluster().EtcdServer.KineSQL != nil {
+		kineClientEndpoint, err := controlPlane.kineClientEndpoint(inquirer)
+		if err != nil {
+			return []string{}
+		}
+		return []string{kineClientEndpoint}
+	}
+	localHypervisor := inquirer.Hypervisor()
+	weightedEndpoints := []etcdClientEndpoint{}
 	controlPlaneComponents := inquirer.ClusterComponents(component.ControlPlaneRole)
 	for _, controlPlaneComponent := range controlPlaneComponents {
 		if controlPlaneComponent.DeletionTimestamp != nil {
@@ -146,7 +205,23 @@ func (controlPlane *ControlPlane) etcdClientEndpoints(inquirer inquirer.Reconcil
 			continue
 		}
 		url := url.URL{Scheme: "https", Host: net.JoinHostPort(componentHypervisor.IPAddress, strconv.Itoa(etcdClientHostPort))}
-		endpoints = append(endpoints, url.String())
+		endpoint := url.String()
+		healthy := healthcheck.EtcdEndpointCheck{TargetName: controlPlaneComponent.Name, Endpoint: endpoint}.Run(healthcheck.Config{}) == nil
+		weightedEndpoints = append(weightedEndpoints, etcdClientEndpoint{
+			endpoint: endpoint,
+			local:    localHypervisor != nil && componentHypervisor.Name == localHypervisor.Name,
+			healthy:  healthy,
+		})
+	}
+	sort.SliceStable(weightedEndpoints, func(i, j int) bool {
+		if weightedEndpoints[i].local != weightedEndpoints[j].local {
+			return weightedEndpoints[i].local
+		}
+		return weightedEndpoints[i].healthy && !weightedEndpoints[j].healthy
+	})
+	endpoints := make([]string, 0, len(weightedEndpoints))
+	for _, weightedEndpoint := range weightedEndpoints {
+		endpoints = append(endpoints, weightedEndpoint.endpoint)
 	}
 	return endpoints
 }
@@ -351,6 +426,7 @@ func (controlPlane *ControlPlane) reconcileEtcdCertificatesAndKeys(inquirer inqu
 		[]string{cluster.Name},
 		// Peer authentication via SANs
 		[]string{hypervisor.IPAddress},
+		cluster.RotationThreshold(),
 	)
 	if err != nil {
 		return err
@@ -361,6 +437,7 @@ func (controlPlane *ControlPlane) reconcileEtcdCertificatesAndKeys(inquirer inqu
 		"etcd",
 		[]string{"etcd"},
 		[]string{hypervisor.IPAddress},
+		cluster.RotationThreshold(),
 	)
 	if err != nil {
 		return err
@@ -400,6 +477,99 @@ func (controlPlane *ControlPlane) runEtcd(inquirer inquirer.ReconcilerInquirer)
 	if hasEtcdLearner {
 		return controlPlane.promoteEtcdLearner(inquirer)
 	}
+	if err := controlPlane.reconcileEtcdMaintenance(inquirer); err != nil {
+		klog.Warningf("etcd maintenance failed: %v", err)
+	}
+	if err := controlPlane.reconcileEtcdBackup(inquirer); err != nil {
+		klog.Warningf("etcd backup failed: %v", err)
+	}
+	return nil
+}
+
+// reconcileEtcdMaintenance reports this component's own etcd member
+// health on the EtcdMemberUnhealthy condition, and defragments its
+// data file on etcdDefragmentationInterval, gated by the
+// EtcdDefragmented condition's last-set time, to reclaim disk space
+// freed by etcd's own background compaction
+func (controlPlane *ControlPlane) reconcileEtcdMaintenance(inquirer inquirer.ReconcilerInquirer) error {
+	thisComponent := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	etcdClientHostPort, err := controlPlane.etcdClientHostPort(inquirer)
+	if err != nil {
+		return err
+	}
+	endpoint := (&url.URL{Scheme: "https", Host: net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(etcdClientHostPort))}).String()
+	etcdClient, err := controlPlane.etcdClientWithEndpoints(inquirer, []string{endpoint})
+	if err != nil {
+		return err
+	}
+	defer etcdClient.Close()
+	statusCtx, statusCancel := context.WithTimeout(context.TODO(), etcdDialTimeout)
+	defer statusCancel()
+	if _, err := etcdClient.Status(statusCtx, endpoint); err != nil {
+		thisComponent.Conditions.SetCondition(component.EtcdMemberUnhealthy, conditions.ConditionTrue)
+		return errors.Wrapf(err, "etcd member %q is unhealthy", thisComponent.Name)
+	}
+	thisComponent.Conditions.SetCondition(component.EtcdMemberUnhealthy, conditions.ConditionFalse)
+	if time.Since(thisComponent.Conditions.LastSetTime(component.EtcdDefragmented).Time) < etcdDefragmentationInterval {
+		return nil
+	}
+	defragCtx, defragCancel := context.WithTimeout(context.TODO(), etcdDialTimeout)
+	defer defragCancel()
+	if _, err := etcdClient.Defragment(defragCtx, endpoint); err != nil {
+		return errors.Wrapf(err, "failed to defragment etcd member %q", thisComponent.Name)
+	}
+	thisComponent.Conditions.SetCondition(component.EtcdDefragmented, conditions.ConditionTrue)
+	return nil
+}
+
+// reconcileEtcdBackup takes a live snapshot of this component's
+// etcd instance and uploads it to a timestamped file under the
+// cluster's EtcdServer.BackupPath, on etcdBackupInterval, gated by
+// the EtcdBackedUp condition's last-set time. A no-op if BackupPath
+// is not set. Unlike SnapshotEtcd, backups are kept around
+// indefinitely under their own timestamped name, rather than being
+// overwritten on every run, so an earlier point in time can still
+// be restored from. Only a local hypervisor path is supported
+// today; remote backends (S3, GCS) are not implemented yet
+func (controlPlane *ControlPlane) reconcileEtcdBackup(inquirer inquirer.ReconcilerInquirer) error {
+	cluster := inquirer.Cluster()
+	backupPath := cluster.EtcdServer.BackupPath
+	if backupPath == "" {
+		return nil
+	}
+	thisComponent := inquirer.Component()
+	if time.Since(thisComponent.Conditions.LastSetTime(component.EtcdBackedUp).Time) < etcdBackupInterval {
+		return nil
+	}
+	hypervisor := inquirer.Hypervisor()
+	etcdClient, err := controlPlane.etcdClient(inquirer)
+	if err != nil {
+		return errors.Wrapf(err, "could not create an etcd client to back up %q", thisComponent.Name)
+	}
+	defer etcdClient.Close()
+	ctx, cancel := context.WithTimeout(context.TODO(), etcdSnapshotTimeout)
+	defer cancel()
+	snapshot, err := etcdClient.Snapshot(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not take an etcd backup snapshot for %q", thisComponent.Name)
+	}
+	defer snapshot.Close()
+	snapshotContents, err := ioutil.ReadAll(snapshot)
+	if err != nil {
+		return errors.Wrapf(err, "could not read etcd backup snapshot for %q", thisComponent.Name)
+	}
+	timestamp := time.Now().Format(etcdBackupTimestampLayout)
+	if err := hypervisor.UploadFile(
+		cluster.Namespace,
+		cluster.Name,
+		thisComponent.Name,
+		etcdBackupHostPath(backupPath, thisComponent.Name, timestamp),
+		string(snapshotContents),
+	); err != nil {
+		return errors.Wrapf(err, "could not upload etcd backup snapshot for %q", thisComponent.Name)
+	}
+	thisComponent.Conditions.SetCondition(component.EtcdBackedUp, conditions.ConditionTrue)
 	return nil
 }
 
@@ -407,10 +577,16 @@ func (controlPlane *ControlPlane) ensureEtcdPod(inquirer inquirer.ReconcilerInqu
 	component := inquirer.Component()
 	cluster := inquirer.Cluster()
 	hypervisor := inquirer.Hypervisor()
+	if err := hypervisor.ValidateEtcdDataDir(); err != nil {
+		return errors.Wrapf(err, "could not validate etcd data directory for component %q", component.Name)
+	}
 	etcdPod, err := controlPlane.etcdPod(inquirer)
 	if err != nil {
 		return err
 	}
+	etcdPod.ApplyEnv(hypervisor.ProxyEnv(cluster.Proxy))
+	etcdPod.ApplyLabels(cluster.Labels)
+	etcdPod.ApplyAnnotations(cluster.Annotations)
 	if _, err = hypervisor.EnsurePod(cluster.Namespace, cluster.Name, component.Name, etcdPod); err != nil {
 		return err
 	}
@@ -421,7 +597,7 @@ func (controlPlane *ControlPlane) etcdContainer(inquirer inquirer.ReconcilerInqu
 	component := inquirer.Component()
 	hypervisor := inquirer.Hypervisor()
 	cluster := inquirer.Cluster()
-	kubernetesVersion := inquirer.Cluster().KubernetesVersion
+	kubernetesVersion := cluster.EffectiveKubernetesVersion()
 	versionBundle, err := constants.KubernetesVersionBundle(kubernetesVersion)
 	if err != nil {
 		return pod.Container{}, errors.Errorf("could not retrieve version bundle for version %q", kubernetesVersion)
@@ -430,28 +606,32 @@ func (controlPlane *ControlPlane) etcdContainer(inquirer inquirer.ReconcilerInqu
 	advertiseClientURLs := url.URL{Scheme: "https", Host: net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(etcdClientHostPort))}
 	listenPeerURLs := url.URL{Scheme: "https", Host: "0.0.0.0:2380"}
 	initialAdvertisePeerURLs := url.URL{Scheme: "https", Host: net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(etcdPeerHostPort))}
+	etcdArguments := map[string]string{
+		"name":                 component.Name,
+		"client-cert-auth":     "true",
+		"peer-cert-allowed-cn": fmt.Sprintf("%s.etcd.cluster", cluster.Name),
+		"experimental-peer-skip-client-san-verification": "true",
+		"cert-file":                   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd.crt"),
+		"key-file":                    componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd.key"),
+		"trusted-ca-file":             componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-client-ca.crt"),
+		"peer-trusted-ca-file":        componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-peer-ca.crt"),
+		"peer-cert-file":              componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-peer.crt"),
+		"peer-key-file":               componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-peer.key"),
+		"data-dir":                    etcdDataDir,
+		"listen-client-urls":          listenClientURLs.String(),
+		"advertise-client-urls":       advertiseClientURLs.String(),
+		"listen-peer-urls":            listenPeerURLs.String(),
+		"initial-advertise-peer-urls": initialAdvertisePeerURLs.String(),
+		"enable-grpc-gateway":         "false",
+	}
+	if quotaBackendBytes := cluster.SizePreset().EtcdQuotaBackendBytes; quotaBackendBytes > 0 {
+		etcdArguments["quota-backend-bytes"] = strconv.FormatInt(quotaBackendBytes, 10)
+	}
 	etcdContainer := pod.Container{
 		Name:    "etcd",
-		Image:   fmt.Sprintf(etcdImage, versionBundle.EtcdVersion),
+		Image:   componentImage(cluster, etcdImage, versionBundle.EtcdVersion),
 		Command: []string{"etcd"},
-		Args: component.ArgsFromMap(map[string]string{
-			"name":                 component.Name,
-			"client-cert-auth":     "true",
-			"peer-cert-allowed-cn": fmt.Sprintf("%s.etcd.cluster", cluster.Name),
-			"experimental-peer-skip-client-san-verification": "true",
-			"cert-file":                   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd.crt"),
-			"key-file":                    componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd.key"),
-			"trusted-ca-file":             componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-client-ca.crt"),
-			"peer-trusted-ca-file":        componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-peer-ca.crt"),
-			"peer-cert-file":              componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-peer.crt"),
-			"peer-key-file":               componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-peer.key"),
-			"data-dir":                    etcdDataDir,
-			"listen-client-urls":          listenClientURLs.String(),
-			"advertise-client-urls":       advertiseClientURLs.String(),
-			"listen-peer-urls":            listenPeerURLs.String(),
-			"initial-advertise-peer-urls": initialAdvertisePeerURLs.String(),
-			"enable-grpc-gateway":         "false",
-		}),
+		Args:    component.ArgsFromMap(etcdArguments),
 		Env: map[string]string{
 			"ETCDCTL_CACERT":    componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-ca.crt"),
 			"ETCDCTL_CERT":      componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "apiserver-etcd-client.crt"),
@@ -459,8 +639,8 @@ func (controlPlane *ControlPlane) etcdContainer(inquirer inquirer.ReconcilerInqu
 			"ETCDCTL_ENDPOINTS": strings.Join(controlPlane.etcdClientEndpoints(inquirer), ","),
 		},
 		Mounts: map[string]string{
-			componentSecretsPath(cluster.Namespace, cluster.Name, component.Name):            componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
-			subcomponentStoragePath(cluster.Namespace, cluster.Name, component.Name, "etcd"): etcdDataDir,
+			componentSecretsPath(cluster.Namespace, cluster.Name, component.Name):         componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
+			etcdDataHostPath(hypervisor, cluster.Namespace, cluster.Name, component.Name): etcdDataDir,
 		},
 	}
 	etcdMembers, err := controlPlane.etcdMembers(inquirer)
@@ -571,3 +751,94 @@ func (controlPlane *ControlPlane) stopEtcd(inquirer inquirer.ReconcilerInquirer)
 	}
 	return err
 }
+
+// SnapshotEtcd takes a live snapshot of this component's etcd
+// instance and uploads it to the hypervisor at
+// etcdPreUpgradeSnapshotHostPath, so it can be restored later with
+// RestoreEtcdSnapshot if a Kubernetes version upgrade needs to be
+// rolled back
+func (controlPlane *ControlPlane) SnapshotEtcd(inquirer inquirer.ReconcilerInquirer) error {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	cluster := inquirer.Cluster()
+	etcdClient, err := controlPlane.etcdClient(inquirer)
+	if err != nil {
+		return errors.Wrapf(err, "could not create an etcd client to snapshot %q", component.Name)
+	}
+	defer etcdClient.Close()
+	ctx, cancel := context.WithTimeout(context.TODO(), etcdSnapshotTimeout)
+	defer cancel()
+	snapshot, err := etcdClient.Snapshot(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not take an etcd snapshot for %q", component.Name)
+	}
+	defer snapshot.Close()
+	snapshotContents, err := ioutil.ReadAll(snapshot)
+	if err != nil {
+		return errors.Wrapf(err, "could not read etcd snapshot for %q", component.Name)
+	}
+	return hypervisor.UploadFile(
+		cluster.Namespace,
+		cluster.Name,
+		component.Name,
+		etcdPreUpgradeSnapshotHostPath(cluster.Namespace, cluster.Name, component.Name),
+		string(snapshotContents),
+	)
+}
+
+// RestoreEtcdSnapshot stops etcd and restores the pre-upgrade
+// snapshot taken by SnapshotEtcd over the live etcd data directory,
+// discarding whatever data is currently there. The next reconcile
+// recreates the etcd pod from the restored data
+func (controlPlane *ControlPlane) RestoreEtcdSnapshot(inquirer inquirer.ReconcilerInquirer) error {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	cluster := inquirer.Cluster()
+	if err := controlPlane.stopEtcd(inquirer); err != nil {
+		return err
+	}
+	kubernetesVersion := cluster.KubernetesVersion
+	versionBundle, err := constants.KubernetesVersionBundle(kubernetesVersion)
+	if err != nil {
+		return errors.Errorf("could not retrieve version bundle for version %q", kubernetesVersion)
+	}
+	etcdDataPath := etcdDataHostPath(hypervisor, cluster.Namespace, cluster.Name, component.Name)
+	restoreDataPath := etcdRestoreDataHostPath(hypervisor, cluster.Namespace, cluster.Name, component.Name)
+	snapshotPath := etcdPreUpgradeSnapshotHostPath(cluster.Namespace, cluster.Name, component.Name)
+	restoreMounts := map[string]string{
+		globalStoragePath(): globalStoragePath(),
+	}
+	if hypervisor.EtcdDataDir != "" {
+		restoreMounts[hypervisor.EtcdDataDir] = hypervisor.EtcdDataDir
+	}
+	return hypervisor.RunAndWaitForPod(
+		cluster.Namespace,
+		cluster.Name,
+		component.Name,
+		pod.NewPod(
+			fmt.Sprintf("%s-%s-%s-etcd-restore", cluster.Namespace, cluster.Name, component.Name),
+			[]pod.Container{
+				{
+					Name:    "etcd-restore",
+					Image:   componentImage(cluster, etcdImage, versionBundle.EtcdVersion),
+					Command: []string{"/bin/sh"},
+					Args: []string{
+						"-c",
+						fmt.Sprintf(
+							"etcdctl snapshot restore %s --name %s --data-dir %s && rm -rf %s && mv %s %s",
+							snapshotPath,
+							component.Name,
+							restoreDataPath,
+							etcdDataPath,
+							restoreDataPath,
+							etcdDataPath,
+						),
+					},
+					Mounts: restoreMounts,
+				},
+			},
+			map[int]int{},
+			pod.PrivilegesUnprivileged,
+		),
+	)
+}
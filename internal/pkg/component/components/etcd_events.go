@@ -0,0 +1,218 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package components
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+	"github.com/oneinfra/oneinfra/internal/pkg/infra/pod"
+	"github.com/oneinfra/oneinfra/internal/pkg/inquirer"
+)
+
+// runEventsEtcd reconciles the single-member etcd instance dedicated
+// to storing Kubernetes Event objects, colocated with this control
+// plane replica's primary etcd instance. Unlike the primary store,
+// it is not clustered across control plane replicas: each replica's
+// kube-apiserver is pointed only at its own local events instance.
+// This keeps event storage (inherently short-lived, TTL'd data) from
+// competing with the primary store for etcd's resources, at the cost
+// of events not surviving the loss of the replica that received them
+func (controlPlane *ControlPlane) runEventsEtcd(inquirer inquirer.ReconcilerInquirer) error {
+	if err := controlPlane.reconcileEventsEtcdCertificatesAndKeys(inquirer); err != nil {
+		return err
+	}
+	return controlPlane.ensureEventsEtcdPod(inquirer)
+}
+
+func (controlPlane *ControlPlane) reconcileEventsEtcdCertificatesAndKeys(inquirer inquirer.ReconcilerInquirer) error {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	cluster := inquirer.Cluster()
+	eventsEtcdPeerCertificate, err := component.ClientCertificate(
+		cluster.CertificateAuthorities.EtcdPeer,
+		"etcd-events-peer",
+		fmt.Sprintf("%s.etcd-events.cluster", cluster.Name),
+		[]string{cluster.Name},
+		[]string{hypervisor.IPAddress},
+		cluster.RotationThreshold(),
+	)
+	if err != nil {
+		return err
+	}
+	eventsEtcdServerCertificate, err := component.ServerCertificate(
+		cluster.EtcdServer.CA,
+		"etcd-events",
+		"etcd-events",
+		[]string{"etcd-events"},
+		[]string{hypervisor.IPAddress},
+		cluster.RotationThreshold(),
+	)
+	if err != nil {
+		return err
+	}
+	return hypervisor.UploadFiles(
+		cluster.Namespace,
+		cluster.Name,
+		component.Name,
+		map[string]string{
+			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-events.crt"):      eventsEtcdServerCertificate.Certificate,
+			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-events.key"):      eventsEtcdServerCertificate.PrivateKey,
+			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-events-peer.crt"): eventsEtcdPeerCertificate.Certificate,
+			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-events-peer.key"): eventsEtcdPeerCertificate.PrivateKey,
+		},
+	)
+}
+
+func (controlPlane *ControlPlane) ensureEventsEtcdPod(inquirer inquirer.ReconcilerInquirer) error {
+	component := inquirer.Component()
+	cluster := inquirer.Cluster()
+	hypervisor := inquirer.Hypervisor()
+	if err := hypervisor.ValidateEtcdDataDir(); err != nil {
+		return errors.Wrapf(err, "could not validate etcd data directory for component %q", component.Name)
+	}
+	eventsEtcdPod, err := controlPlane.eventsEtcdPod(inquirer)
+	if err != nil {
+		return err
+	}
+	eventsEtcdPod.ApplyEnv(hypervisor.ProxyEnv(cluster.Proxy))
+	eventsEtcdPod.ApplyLabels(cluster.Labels)
+	eventsEtcdPod.ApplyAnnotations(cluster.Annotations)
+	_, err = hypervisor.EnsurePod(cluster.Namespace, cluster.Name, component.Name, eventsEtcdPod)
+	return err
+}
+
+func (controlPlane *ControlPlane) eventsEtcdPod(inquirer inquirer.ReconcilerInquirer) (pod.Pod, error) {
+	component := inquirer.Component()
+	eventsEtcdPeerHostPort, err := controlPlane.eventsEtcdPeerHostPort(inquirer)
+	if err != nil {
+		return pod.Pod{}, errors.Wrapf(err, "could not allocate events etcd peer host port for component %q", component.Name)
+	}
+	eventsEtcdClientHostPort, err := controlPlane.eventsEtcdClientHostPort(inquirer)
+	if err != nil {
+		return pod.Pod{}, errors.Wrapf(err, "could not allocate events etcd client host port for component %q", component.Name)
+	}
+	eventsEtcdContainer, err := controlPlane.eventsEtcdContainer(inquirer, eventsEtcdClientHostPort, eventsEtcdPeerHostPort)
+	if err != nil {
+		return pod.Pod{}, err
+	}
+	return pod.NewPod(
+		controlPlane.eventsEtcdPodName(inquirer),
+		[]pod.Container{
+			eventsEtcdContainer,
+		},
+		map[int]int{
+			eventsEtcdClientHostPort: 2379,
+			eventsEtcdPeerHostPort:   2380,
+		},
+		pod.PrivilegesUnprivileged,
+	), nil
+}
+
+func (controlPlane *ControlPlane) eventsEtcdContainer(inquirer inquirer.ReconcilerInquirer, eventsEtcdClientHostPort, eventsEtcdPeerHostPort int) (pod.Container, error) {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	cluster := inquirer.Cluster()
+	kubernetesVersion := cluster.EffectiveKubernetesVersion()
+	versionBundle, err := constants.KubernetesVersionBundle(kubernetesVersion)
+	if err != nil {
+		return pod.Container{}, errors.Errorf("could not retrieve version bundle for version %q", kubernetesVersion)
+	}
+	listenClientURLs := url.URL{Scheme: "https", Host: "0.0.0.0:2379"}
+	advertiseClientURLs := url.URL{Scheme: "https", Host: net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(eventsEtcdClientHostPort))}
+	listenPeerURLs := url.URL{Scheme: "https", Host: "0.0.0.0:2380"}
+	initialAdvertisePeerURLs := url.URL{Scheme: "https", Host: net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(eventsEtcdPeerHostPort))}
+	eventsEtcdArguments := map[string]string{
+		"name":                 component.Name,
+		"client-cert-auth":     "true",
+		"peer-cert-allowed-cn": fmt.Sprintf("%s.etcd-events.cluster", cluster.Name),
+		"experimental-peer-skip-client-san-verification": "true",
+		"cert-file":                   componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-events.crt"),
+		"key-file":                    componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-events.key"),
+		"trusted-ca-file":             componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-client-ca.crt"),
+		"peer-trusted-ca-file":        componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-peer-ca.crt"),
+		"peer-cert-file":              componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-events-peer.crt"),
+		"peer-key-file":               componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-events-peer.key"),
+		"data-dir":                    etcdEventsDataDir,
+		"listen-client-urls":          listenClientURLs.String(),
+		"advertise-client-urls":       advertiseClientURLs.String(),
+		"listen-peer-urls":            listenPeerURLs.String(),
+		"initial-advertise-peer-urls": initialAdvertisePeerURLs.String(),
+		"enable-grpc-gateway":         "false",
+		"initial-cluster-state":       "new",
+		"initial-cluster":             fmt.Sprintf("%s=%s", component.Name, initialAdvertisePeerURLs.String()),
+	}
+	return pod.Container{
+		Name:    "etcd-events",
+		Image:   componentImage(cluster, etcdImage, versionBundle.EtcdVersion),
+		Command: []string{"etcd"},
+		Args:    component.ArgsFromMap(eventsEtcdArguments),
+		Mounts: map[string]string{
+			componentSecretsPath(cluster.Namespace, cluster.Name, component.Name):               componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
+			eventsEtcdDataHostPath(hypervisor, cluster.Namespace, cluster.Name, component.Name): etcdEventsDataDir,
+		},
+	}, nil
+}
+
+// eventsEtcdClientEndpoint returns the local client endpoint of this
+// control plane replica's own events etcd instance, the only
+// endpoint its colocated kube-apiserver is ever pointed at
+func (controlPlane *ControlPlane) eventsEtcdClientEndpoint(inquirer inquirer.ReconcilerInquirer) (string, error) {
+	eventsEtcdClientHostPort, err := controlPlane.eventsEtcdClientHostPort(inquirer)
+	if err != nil {
+		return "", err
+	}
+	endpoint := url.URL{Scheme: "https", Host: net.JoinHostPort("127.0.0.1", strconv.Itoa(eventsEtcdClientHostPort))}
+	return endpoint.String(), nil
+}
+
+func (controlPlane *ControlPlane) eventsEtcdPeerHostPort(inquirer inquirer.ReconcilerInquirer) (int, error) {
+	return inquirer.Component().RequestPort(inquirer.Hypervisor(), EtcdEventsPeerHostPortName)
+}
+
+func (controlPlane *ControlPlane) eventsEtcdClientHostPort(inquirer inquirer.ReconcilerInquirer) (int, error) {
+	return inquirer.Component().RequestPort(inquirer.Hypervisor(), EtcdEventsClientHostPortName)
+}
+
+func (controlPlane *ControlPlane) eventsEtcdPodName(inquirer inquirer.ReconcilerInquirer) string {
+	return fmt.Sprintf("etcd-events-%s", inquirer.Cluster().Name)
+}
+
+func (controlPlane *ControlPlane) stopEventsEtcd(inquirer inquirer.ReconcilerInquirer) error {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	err := hypervisor.DeletePod(
+		inquirer.Cluster().Namespace,
+		inquirer.Cluster().Name,
+		component.Name,
+		controlPlane.eventsEtcdPodName(inquirer),
+	)
+	if err == nil {
+		if err := component.FreePort(hypervisor, EtcdEventsPeerHostPortName); err != nil {
+			return errors.Wrapf(err, "could not free port %q for hypervisor %q", EtcdEventsPeerHostPortName, hypervisor.Name)
+		}
+		if err := component.FreePort(hypervisor, EtcdEventsClientHostPortName); err != nil {
+			return errors.Wrapf(err, "could not free port %q for hypervisor %q", EtcdEventsClientHostPortName, hypervisor.Name)
+		}
+	}
+	return err
+}
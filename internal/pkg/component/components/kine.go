@@ -0,0 +1,167 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package components
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/infra/pod"
+	"github.com/oneinfra/oneinfra/internal/pkg/inquirer"
+)
+
+const (
+	kineImage   = "rancher/kine:%s"
+	kineVersion = "0.9.2"
+	kineDataDir = "/var/lib/kine"
+)
+
+// kineClientEndpoint returns this component's own kine client
+// endpoint. Unlike etcd, kine replicas do not form a raft cluster:
+// each control plane replica runs an independent kine frontend
+// against the same DataSourceName, so kube-apiserver is only ever
+// pointed at its own local replica instead of a member list
+func (controlPlane *ControlPlane) kineClientEndpoint(inquirer inquirer.ReconcilerInquirer) (string, error) {
+	hypervisor := inquirer.Hypervisor()
+	kineClientHostPort, err := controlPlane.etcdClientHostPort(inquirer)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "https", Host: net.JoinHostPort(hypervisor.IPAddress, strconv.Itoa(kineClientHostPort))}).String(), nil
+}
+
+func (controlPlane *ControlPlane) reconcileKineCertificatesAndKeys(inquirer inquirer.ReconcilerInquirer) error {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	cluster := inquirer.Cluster()
+	kineServerCertificate, err := component.ServerCertificate(
+		cluster.EtcdServer.CA,
+		"kine",
+		"kine",
+		[]string{"kine"},
+		[]string{hypervisor.IPAddress},
+		cluster.RotationThreshold(),
+	)
+	if err != nil {
+		return err
+	}
+	return hypervisor.UploadFiles(
+		cluster.Namespace,
+		cluster.Name,
+		component.Name,
+		map[string]string{
+			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "kine.crt"):           kineServerCertificate.Certificate,
+			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "kine.key"):           kineServerCertificate.PrivateKey,
+			componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-client-ca.crt"): cluster.CertificateAuthorities.EtcdClient.Certificate,
+		},
+	)
+}
+
+func (controlPlane *ControlPlane) runKine(inquirer inquirer.ReconcilerInquirer) error {
+	if err := controlPlane.reconcileKineCertificatesAndKeys(inquirer); err != nil {
+		return err
+	}
+	return controlPlane.ensureKinePod(inquirer)
+}
+
+func (controlPlane *ControlPlane) ensureKinePod(inquirer inquirer.ReconcilerInquirer) error {
+	component := inquirer.Component()
+	cluster := inquirer.Cluster()
+	hypervisor := inquirer.Hypervisor()
+	kinePod, err := controlPlane.kinePod(inquirer)
+	if err != nil {
+		return err
+	}
+	kinePod.ApplyEnv(hypervisor.ProxyEnv(cluster.Proxy))
+	kinePod.ApplyLabels(cluster.Labels)
+	kinePod.ApplyAnnotations(cluster.Annotations)
+	if _, err = hypervisor.EnsurePod(cluster.Namespace, cluster.Name, component.Name, kinePod); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (controlPlane *ControlPlane) kinePod(inquirer inquirer.ReconcilerInquirer) (pod.Pod, error) {
+	component := inquirer.Component()
+	kineClientHostPort, err := controlPlane.etcdClientHostPort(inquirer)
+	if err != nil {
+		return pod.Pod{}, errors.Wrapf(err, "could not allocate kine client host port for component %q", component.Name)
+	}
+	kineContainer, err := controlPlane.kineContainer(inquirer, kineClientHostPort)
+	if err != nil {
+		return pod.Pod{}, err
+	}
+	return pod.NewPod(
+		controlPlane.kinePodName(inquirer),
+		[]pod.Container{
+			kineContainer,
+		},
+		map[int]int{
+			kineClientHostPort: 2379,
+		},
+		pod.PrivilegesUnprivileged,
+	), nil
+}
+
+func (controlPlane *ControlPlane) kineContainer(inquirer inquirer.ReconcilerInquirer, kineClientHostPort int) (pod.Container, error) {
+	component := inquirer.Component()
+	hypervisor := inquirer.Hypervisor()
+	cluster := inquirer.Cluster()
+	listenAddress := url.URL{Scheme: "https", Host: "0.0.0.0:2379"}
+	kineArguments := map[string]string{
+		"endpoint":         cluster.EtcdServer.KineSQL.DataSourceName,
+		"listen-address":   listenAddress.String(),
+		"server-cert-file": componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "kine.crt"),
+		"server-key-file":  componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "kine.key"),
+		"ca-file":          componentSecretsPathFile(cluster.Namespace, cluster.Name, component.Name, "etcd-client-ca.crt"),
+	}
+	return pod.Container{
+		Name:    "kine",
+		Image:   componentImage(cluster, kineImage, kineVersion),
+		Command: []string{"kine"},
+		Args:    component.ArgsFromMap(kineArguments),
+		Mounts: map[string]string{
+			componentSecretsPath(cluster.Namespace, cluster.Name, component.Name):         componentSecretsPath(cluster.Namespace, cluster.Name, component.Name),
+			kineDataHostPath(hypervisor, cluster.Namespace, cluster.Name, component.Name): kineDataDir,
+		},
+	}, nil
+}
+
+func (controlPlane *ControlPlane) kinePodName(inquirer inquirer.ReconcilerInquirer) string {
+	return fmt.Sprintf("kine-%s", inquirer.Cluster().Name)
+}
+
+func (controlPlane *ControlPlane) stopKine(inquirer inquirer.ReconcilerInquirer) error {
+	err := inquirer.Hypervisor().DeletePod(
+		inquirer.Cluster().Namespace,
+		inquirer.Cluster().Name,
+		inquirer.Component().Name,
+		controlPlane.kinePodName(inquirer),
+	)
+	if err == nil {
+		component := inquirer.Component()
+		hypervisor := inquirer.Hypervisor()
+		if err := component.FreePort(hypervisor, EtcdClientHostPortName); err != nil {
+			return errors.Wrapf(err, "could not free port %q for hypervisor %q", EtcdClientHostPortName, hypervisor.Name)
+		}
+	}
+	return err
+}
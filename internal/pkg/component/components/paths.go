@@ -17,9 +17,18 @@
 package components
 
 import (
+	"fmt"
 	"path/filepath"
+
+	"github.com/oneinfra/oneinfra/internal/pkg/infra"
 )
 
+// globalSecretsPath returns the root path key material is written
+// under on a hypervisor. oneinfra only controls the bind mounts it
+// passes to component containers, not how the underlying host
+// directory is backed, so operators on shared hosts who want this
+// path to never hit durable storage should mount it as tmpfs
+// themselves before pointing a Hypervisor at it
 func globalSecretsPath() string {
 	return "/etc/oneinfra/clusters"
 }
@@ -59,3 +68,61 @@ func componentStoragePath(clusterNamespace, clusterName, componentName string) s
 func subcomponentStoragePath(clusterNamespace, clusterName, componentName, subcomponentName string) string {
 	return filepath.Join(componentStoragePath(clusterNamespace, clusterName, componentName), subcomponentName)
 }
+
+// etcdDataHostPath returns the host path where etcd data for the
+// given cluster and component should be stored, rooted at the
+// hypervisor's EtcdDataDir override when set, or at the default
+// storage path otherwise
+func etcdDataHostPath(hypervisor *infra.Hypervisor, clusterNamespace, clusterName, componentName string) string {
+	if hypervisor.EtcdDataDir != "" {
+		return filepath.Join(hypervisor.EtcdDataDir, clusterNamespace, clusterName, componentName)
+	}
+	return subcomponentStoragePath(clusterNamespace, clusterName, componentName, "etcd")
+}
+
+// eventsEtcdDataHostPath returns the host path where the dedicated
+// events etcd data for the given cluster and component should be
+// stored, alongside the primary etcd data directory
+func eventsEtcdDataHostPath(hypervisor *infra.Hypervisor, clusterNamespace, clusterName, componentName string) string {
+	if hypervisor.EtcdDataDir != "" {
+		return filepath.Join(hypervisor.EtcdDataDir, clusterNamespace, clusterName, componentName+"-events")
+	}
+	return subcomponentStoragePath(clusterNamespace, clusterName, componentName, "etcd-events")
+}
+
+// kineDataHostPath returns the host path where kine's own local
+// state (e.g. a sqlite DataSourceName) for the given cluster and
+// component should be stored, alongside the primary etcd data
+// directory. A kine process talking to a remote SQL server does not
+// use this path for anything
+func kineDataHostPath(hypervisor *infra.Hypervisor, clusterNamespace, clusterName, componentName string) string {
+	if hypervisor.EtcdDataDir != "" {
+		return filepath.Join(hypervisor.EtcdDataDir, clusterNamespace, clusterName, componentName+"-kine")
+	}
+	return subcomponentStoragePath(clusterNamespace, clusterName, componentName, "kine")
+}
+
+// etcdPreUpgradeSnapshotHostPath returns the host path where the
+// etcd snapshot taken automatically before a Kubernetes version
+// upgrade is stored for the given component, so it can be restored
+// if the upgrade is rolled back
+func etcdPreUpgradeSnapshotHostPath(clusterNamespace, clusterName, componentName string) string {
+	return filepath.Join(
+		subcomponentStoragePath(clusterNamespace, clusterName, componentName, "etcd-snapshots"),
+		"pre-upgrade.db",
+	)
+}
+
+// etcdRestoreDataHostPath returns the host path a pre-upgrade etcd
+// snapshot is restored into, before it replaces the live etcd data
+// directory
+func etcdRestoreDataHostPath(hypervisor *infra.Hypervisor, clusterNamespace, clusterName, componentName string) string {
+	return etcdDataHostPath(hypervisor, clusterNamespace, clusterName, componentName) + ".restore"
+}
+
+// etcdBackupHostPath returns the host path a periodic etcd snapshot
+// backup taken at the given timestamp is stored at, rooted at
+// backupPath
+func etcdBackupHostPath(backupPath, componentName, timestamp string) string {
+	return filepath.Join(backupPath, fmt.Sprintf("%s-%s.db", componentName, timestamp))
+}
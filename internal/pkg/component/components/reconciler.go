@@ -29,4 +29,7 @@ type Component interface {
 	PreReconcile(inquirer.ReconcilerInquirer) error
 	Reconcile(inquirer.ReconcilerInquirer) error
 	ReconcileDeletion(inquirer.ReconcilerInquirer) error
+	// IsReady runs this component's readiness checks, returning
+	// whether it has converged yet
+	IsReady(inquirer.ReconcilerInquirer) (bool, error)
 }
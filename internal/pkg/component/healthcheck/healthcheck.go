@@ -0,0 +1,162 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package healthcheck provides a small set of pluggable readiness
+// checks (HTTP healthz, etcd endpoint health, plain TCP connect)
+// that components can register instead of relying on one hardcoded
+// polling strategy
+package healthcheck
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultTimeoutSeconds  = 5
+	defaultIntervalSeconds = 10
+)
+
+// Config holds the timeout and interval a Check is run with. Zero
+// values fall back to conservative defaults, so cluster authors only
+// have to tune what slow hardware actually requires. The checks in
+// this package and Ready only read the timeout; the interval is not
+// used by this package.
+type Config struct {
+	TimeoutSeconds  int
+	IntervalSeconds int
+}
+
+// Timeout returns the configured timeout, or a default of 5 seconds
+func (config Config) Timeout() time.Duration {
+	if config.TimeoutSeconds <= 0 {
+		return defaultTimeoutSeconds * time.Second
+	}
+	return time.Duration(config.TimeoutSeconds) * time.Second
+}
+
+// Interval returns the configured polling interval, or a default of
+// 10 seconds
+func (config Config) Interval() time.Duration {
+	if config.IntervalSeconds <= 0 {
+		return defaultIntervalSeconds * time.Second
+	}
+	return time.Duration(config.IntervalSeconds) * time.Second
+}
+
+// Check represents a single pluggable readiness check
+type Check interface {
+	// Name identifies the check, for logging and diagnostics
+	Name() string
+	// Run executes the check once, returning an error when the
+	// checked target is not ready yet
+	Run(config Config) error
+}
+
+// HTTPHealthzCheck reports readiness once an HTTP(S) healthz-style
+// endpoint responds with a 200 status code
+type HTTPHealthzCheck struct {
+	TargetName string
+	URL        string
+	CACertPEM  string
+}
+
+// Name returns this check's target name
+func (check HTTPHealthzCheck) Name() string {
+	return check.TargetName
+}
+
+// Run requests the configured URL and expects a 200 status code
+func (check HTTPHealthzCheck) Run(config Config) error {
+	client := &http.Client{Timeout: config.Timeout()}
+	if check.CACertPEM != "" {
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM([]byte(check.CACertPEM)) {
+			return errors.Errorf("could not parse CA certificate for check %q", check.TargetName)
+		}
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: certPool},
+		}
+	}
+	res, err := client.Get(check.URL)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.Errorf("check %q received status code %d", check.TargetName, res.StatusCode)
+	}
+	return nil
+}
+
+// EtcdEndpointCheck reports readiness of an etcd member through its
+// HTTP /health endpoint
+type EtcdEndpointCheck struct {
+	TargetName string
+	Endpoint   string
+	CACertPEM  string
+}
+
+// Name returns this check's target name
+func (check EtcdEndpointCheck) Name() string {
+	return check.TargetName
+}
+
+// Run requests the etcd member's /health endpoint
+func (check EtcdEndpointCheck) Run(config Config) error {
+	return HTTPHealthzCheck{
+		TargetName: check.TargetName,
+		URL:        check.Endpoint + "/health",
+		CACertPEM:  check.CACertPEM,
+	}.Run(config)
+}
+
+// TCPConnectCheck reports readiness once a plain TCP connection to
+// an address succeeds
+type TCPConnectCheck struct {
+	TargetName string
+	Address    string
+}
+
+// Name returns this check's target name
+func (check TCPConnectCheck) Name() string {
+	return check.TargetName
+}
+
+// Run dials the configured address over TCP
+func (check TCPConnectCheck) Run(config Config) error {
+	conn, err := net.DialTimeout("tcp", check.Address, config.Timeout())
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// Ready runs every check in checks, returning the first error
+// encountered, or nil if all checks succeeded
+func Ready(checks []Check, config Config) error {
+	for _, check := range checks {
+		if err := check.Run(config); err != nil {
+			return errors.Wrapf(err, "check %q not ready", check.Name())
+		}
+	}
+	return nil
+}
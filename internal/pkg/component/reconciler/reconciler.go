@@ -18,6 +18,7 @@ package reconciler
 
 import (
 	"errors"
+	"sync"
 
 	"k8s.io/klog/v2"
 
@@ -86,56 +87,106 @@ func (componentReconciler *ComponentReconciler) PreReconcile(componentsToPreReco
 	return reconcileErrors
 }
 
-// Reconcile reconciles the provided components
+// Reconcile reconciles the provided components. Components on
+// different hypervisors are reconciled concurrently, since their
+// image pulls, file uploads and sandbox creation are independent of
+// each other; components sharing the same hypervisor are reconciled
+// sequentially, since a hypervisor's transaction and port bookkeeping
+// isn't safe for concurrent use
 func (componentReconciler *ComponentReconciler) Reconcile(componentsToReconcile ...*componentapi.Component) reconciler.ReconcileErrors {
 	if len(componentsToReconcile) == 0 {
 		componentsToReconcile = componentReconciler.componentList
 	}
-	reconcileErrors := reconciler.ReconcileErrors{}
+	componentsByHypervisor := map[string][]*componentapi.Component{}
 	for _, component := range componentsToReconcile {
-		klog.V(1).Infof("reconciling component %q with role %q", component.Name, component.Role)
-		componentToReconcile := retrieveComponent(component)
-		if componentToReconcile == nil {
-			reconcileErrors.AddComponentError(
-				component.Namespace,
-				component.ClusterName,
-				component.Name,
-				errors.New("could not retrieve a specific component instance"),
-			)
-			continue
-		}
+		componentsByHypervisor[component.HypervisorName] = append(
+			componentsByHypervisor[component.HypervisorName],
+			component,
+		)
+	}
+	reconcileErrors := reconciler.ReconcileErrors{}
+	var reconcileErrorsMutex sync.Mutex
+	var wg sync.WaitGroup
+	for _, hypervisorComponents := range componentsByHypervisor {
+		wg.Add(1)
+		go func(hypervisorComponents []*componentapi.Component) {
+			defer wg.Done()
+			for _, component := range hypervisorComponents {
+				if err := componentReconciler.reconcileComponent(component); err != nil {
+					reconcileErrorsMutex.Lock()
+					reconcileErrors.AddComponentError(
+						component.Namespace,
+						component.ClusterName,
+						component.Name,
+						err,
+					)
+					reconcileErrorsMutex.Unlock()
+				}
+			}
+		}(hypervisorComponents)
+	}
+	wg.Wait()
+	if len(reconcileErrors) == 0 {
+		return nil
+	}
+	return reconcileErrors
+}
+
+// reconcileComponent reconciles a single component against its
+// hypervisor
+func (componentReconciler *ComponentReconciler) reconcileComponent(component *componentapi.Component) error {
+	klog.V(1).Infof("reconciling component %q with role %q", component.Name, component.Role)
+	componentToReconcile := retrieveComponent(component)
+	if componentToReconcile == nil {
+		return errors.New("could not retrieve a specific component instance")
+	}
+	component.Conditions.SetCondition(
+		componentapi.ReconcileStarted,
+		conditions.ConditionTrue,
+	)
+	hypervisor := componentReconciler.hypervisorMap[component.HypervisorName]
+	if hypervisor != nil {
+		componentReconciler.rollbackStaleComponentTransaction(hypervisor, component)
+		hypervisor.BeginComponentTransaction(component.Namespace, component.ClusterName, component.Name)
+	}
+	err := componentToReconcile.Reconcile(
+		&reconciler.Inquirer{
+			ReconciledComponent: component,
+			Reconciler:          componentReconciler,
+		},
+	)
+	if hypervisor != nil && hypervisor.IsComponentRepairCircuitBroken(component.Namespace, component.ClusterName, component.Name) {
 		component.Conditions.SetCondition(
-			componentapi.ReconcileStarted,
+			componentapi.AutoRepairSuspended,
 			conditions.ConditionTrue,
 		)
-		err := componentToReconcile.Reconcile(
-			&reconciler.Inquirer{
-				ReconciledComponent: component,
-				Reconciler:          componentReconciler,
-			},
+	} else {
+		component.Conditions.SetCondition(
+			componentapi.AutoRepairSuspended,
+			conditions.ConditionFalse,
 		)
-		if err == nil {
-			component.Conditions.SetCondition(
-				componentapi.ReconcileSucceeded,
-				conditions.ConditionTrue,
-			)
-		} else {
-			component.Conditions.SetCondition(
-				componentapi.ReconcileSucceeded,
-				conditions.ConditionFalse,
-			)
-			reconcileErrors.AddComponentError(
-				component.Namespace,
-				component.ClusterName,
-				component.Name,
-				err,
-			)
-		}
 	}
-	if len(reconcileErrors) == 0 {
+	if err == nil {
+		if hypervisor != nil {
+			hypervisor.CommitComponentTransaction(component.Namespace, component.ClusterName, component.Name)
+		}
+		component.Conditions.SetCondition(
+			componentapi.ReconcileSucceeded,
+			conditions.ConditionTrue,
+		)
+		componentReconciler.updateReadyCondition(component, componentToReconcile)
 		return nil
 	}
-	return reconcileErrors
+	if hypervisor != nil {
+		if rollbackErr := hypervisor.RollbackComponentTransaction(component.Namespace, component.ClusterName, component.Name); rollbackErr != nil {
+			klog.V(1).Infof("could not roll back partially created component %q: %v", component.Name, rollbackErr)
+		}
+	}
+	component.Conditions.SetCondition(
+		componentapi.ReconcileSucceeded,
+		conditions.ConditionFalse,
+	)
+	return err
 }
 
 // ReconcileDeletion reconciles the deletion of the provided components
@@ -179,6 +230,44 @@ func (componentReconciler *ComponentReconciler) ReconcileDeletion(componentsToDe
 	return reconcileErrors
 }
 
+func (componentReconciler *ComponentReconciler) updateReadyCondition(component *componentapi.Component, componentToReconcile components.Component) {
+	ready, err := componentToReconcile.IsReady(
+		&reconciler.Inquirer{
+			ReconciledComponent: component,
+			Reconciler:          componentReconciler,
+		},
+	)
+	if err != nil {
+		klog.V(1).Infof("could not run readiness checks for component %q: %v", component.Name, err)
+		return
+	}
+	if ready {
+		component.Conditions.SetCondition(
+			componentapi.ComponentReady,
+			conditions.ConditionTrue,
+		)
+	} else {
+		component.Conditions.SetCondition(
+			componentapi.ComponentReady,
+			conditions.ConditionFalse,
+		)
+	}
+}
+
+// rollbackStaleComponentTransaction rolls back a transaction left
+// pending by a previous reconcile that started creating this
+// component but never got to commit it, e.g. because the manager
+// crashed or was restarted mid-reconcile
+func (componentReconciler *ComponentReconciler) rollbackStaleComponentTransaction(hypervisor *infra.Hypervisor, component *componentapi.Component) {
+	if !hypervisor.HasPendingComponentTransaction(component.Namespace, component.ClusterName, component.Name) {
+		return
+	}
+	klog.V(1).Infof("rolling back stale pending transaction for component %q on hypervisor %q", component.Name, hypervisor.Name)
+	if err := hypervisor.RollbackComponentTransaction(component.Namespace, component.ClusterName, component.Name); err != nil {
+		klog.V(1).Infof("could not roll back stale pending transaction for component %q: %v", component.Name, err)
+	}
+}
+
 func retrieveComponent(component *componentapi.Component) components.Component {
 	switch component.Role {
 	case componentapi.ControlPlaneRole:
@@ -120,6 +120,18 @@ func (conditionList *ConditionList) SetCondition(conditionType ConditionType, co
 	*conditionList = newConditionList
 }
 
+// LastSetTime returns the last time conditionType was set on this
+// condition list, regardless of its status. The zero time is
+// returned if conditionType has never been set
+func (conditionList ConditionList) LastSetTime(conditionType ConditionType) metav1.Time {
+	for _, condition := range conditionList {
+		if condition.Type == conditionType {
+			return condition.LastSetTime
+		}
+	}
+	return metav1.Time{}
+}
+
 // Export exports the internal condition list to a versioned condition
 // list
 func (conditionList ConditionList) Export() commonv1alpha1.ConditionList {
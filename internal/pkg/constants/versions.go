@@ -57,6 +57,8 @@ func KubernetesComponentVersion(version string, component releasecomponents.Kube
 		return kubernetesVersionBundle.EtcdVersion, nil
 	case releasecomponents.CoreDNS:
 		return kubernetesVersionBundle.CoreDNSVersion, nil
+	case releasecomponents.KubeProxy:
+		return kubernetesVersionBundle.KubeProxyVersion, nil
 	}
 	return "", errors.Errorf("could not find component %q in version %q", component, version)
 }
@@ -26,19 +26,25 @@ kubernetesVersions:
 - version: "1.15.12"
   etcdVersion: "3.4.3"
   coreDNSVersion: "1.3.1"
+  kubeProxyVersion: "1.15.12"
 - version: "1.16.13"
   etcdVersion: "3.4.3"
   coreDNSVersion: "1.6.2"
+  kubeProxyVersion: "1.16.13"
 - version: "1.17.9"
   etcdVersion: "3.4.3"
   coreDNSVersion: "1.6.7"
+  kubeProxyVersion: "1.17.9"
 - version: "1.18.6"
   etcdVersion: "3.4.3"
   coreDNSVersion: "1.6.7"
+  kubeProxyVersion: "1.18.6"
 - version: "1.19.0"
   etcdVersion: "3.4.3"
   coreDNSVersion: "1.6.7"
+  kubeProxyVersion: "1.19.0"
 - version: "1.20.0-alpha.0"
   etcdVersion: "3.4.3"
-  coreDNSVersion: "1.6.7"`
+  coreDNSVersion: "1.6.7"
+  kubeProxyVersion: "1.20.0-alpha.0"`
 )
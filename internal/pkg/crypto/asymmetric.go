@@ -28,12 +28,14 @@ import (
 	"io/ioutil"
 
 	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
 )
 
 // KeyPair represents a public/private key pair
 type KeyPair struct {
 	PublicKey  string
 	PrivateKey string
+	Provenance *commonv1alpha1.Provenance
 	key        *rsa.PrivateKey
 }
 
@@ -43,8 +45,16 @@ type PublicKey struct {
 	key       *rsa.PublicKey
 }
 
-// NewPrivateKey generates a new key pair
+// NewPrivateKey generates a new key pair, served from the key pool
+// registered for keyBitSize through EnableKeyPool if one exists
 func NewPrivateKey(keyBitSize int) (*KeyPair, error) {
+	if pool := keyPoolFor(keyBitSize); pool != nil {
+		return pool.Get()
+	}
+	return newPrivateKey(keyBitSize)
+}
+
+func newPrivateKey(keyBitSize int) (*KeyPair, error) {
 	key, err := rsa.GenerateKey(rand.Reader, keyBitSize)
 	if err != nil {
 		return nil, err
@@ -72,6 +82,7 @@ func NewPrivateKey(keyBitSize int) (*KeyPair, error) {
 	return &KeyPair{
 		PublicKey:  publicKeyPEM.String(),
 		PrivateKey: privateKeyPEM.String(),
+		Provenance: commonv1alpha1.NewProvenance(constants.BuildVersion),
 		key:        key,
 	}, nil
 }
@@ -152,6 +163,7 @@ func NewKeyPairFromv1alpha1(keyPair *commonv1alpha1.KeyPair) (*KeyPair, error) {
 	if err != nil {
 		return nil, err
 	}
+	res.Provenance = keyPair.Provenance
 	return res, nil
 }
 
@@ -160,9 +172,14 @@ func (keyPair *KeyPair) Export() *commonv1alpha1.KeyPair {
 	if keyPair == nil {
 		return nil
 	}
+	provenance := keyPair.Provenance
+	if provenance == nil {
+		provenance = commonv1alpha1.NewProvenance(constants.BuildVersion)
+	}
 	return &commonv1alpha1.KeyPair{
 		PublicKey:  keyPair.PublicKey,
 		PrivateKey: keyPair.PrivateKey,
+		Provenance: provenance,
 	}
 }
 
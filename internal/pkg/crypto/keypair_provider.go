@@ -0,0 +1,37 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package crypto
+
+// Encrypter encrypts to a public key, implemented by every public
+// key type regardless of the underlying cipher suite (PublicKey,
+// NaClBoxPublicKey, and the KeyPairProvider implementations
+// themselves), so callers that only ever encrypt do not need to know
+// which cipher suite they were handed
+type Encrypter interface {
+	Encrypt(content string) (string, error)
+}
+
+// KeyPairProvider encrypts to, and decrypts from, a key pair of any
+// supported cipher suite. KeyPair (RSA) and NaClBoxKeyPair
+// (Curve25519) implement it by holding the private key locally; a
+// KMS/HSM backed provider such as KMSKeyPair can implement it too,
+// decrypting by calling out to the external key store instead, so
+// the private key never needs to leave it
+type KeyPairProvider interface {
+	Encrypt(content string) (string, error)
+	Decrypt(content string) (string, error)
+}
@@ -0,0 +1,123 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package crypto
+
+import (
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+// KeyPool keeps a background supply of ready to use RSA key pairs of
+// a given bit size, so that callers that would otherwise pay the RSA
+// generation cost inline can consume an already generated key instead
+type KeyPool struct {
+	keyBitSize int
+	keys       chan *KeyPair
+	stopCh     chan struct{}
+}
+
+// NewKeyPool returns a key pool that keeps up to poolSize keys of
+// keyBitSize ready. The pool does not start generating keys until
+// Start is called.
+func NewKeyPool(keyBitSize, poolSize int) *KeyPool {
+	return &KeyPool{
+		keyBitSize: keyBitSize,
+		keys:       make(chan *KeyPair, poolSize),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start begins filling the pool in the background. It returns
+// immediately.
+func (keyPool *KeyPool) Start() {
+	go keyPool.run()
+}
+
+// Stop stops the background key generation. Keys already in the pool
+// remain available through Get.
+func (keyPool *KeyPool) Stop() {
+	close(keyPool.stopCh)
+}
+
+// Get returns a key from the pool if one is ready, or generates one
+// inline otherwise
+func (keyPool *KeyPool) Get() (*KeyPair, error) {
+	select {
+	case key := <-keyPool.keys:
+		return key, nil
+	default:
+		return newPrivateKey(keyPool.keyBitSize)
+	}
+}
+
+func (keyPool *KeyPool) run() {
+	for {
+		key, err := newPrivateKey(keyPool.keyBitSize)
+		if err != nil {
+			klog.Warningf("could not pre-generate a %d bit key for the key pool: %v", keyPool.keyBitSize, err)
+			continue
+		}
+		select {
+		case keyPool.keys <- key:
+		case <-keyPool.stopCh:
+			return
+		}
+		select {
+		case <-keyPool.stopCh:
+			return
+		default:
+		}
+	}
+}
+
+var (
+	keyPoolsMutex sync.RWMutex
+	keyPools      = map[int]*KeyPool{}
+)
+
+// EnableKeyPool starts pre-generating up to poolSize RSA keys of
+// keyBitSize in the background. Once enabled, NewPrivateKey calls
+// requesting that exact bit size are served from the pool. Calling it
+// again with the same bit size replaces the existing pool.
+func EnableKeyPool(keyBitSize, poolSize int) {
+	pool := NewKeyPool(keyBitSize, poolSize)
+	pool.Start()
+	keyPoolsMutex.Lock()
+	defer keyPoolsMutex.Unlock()
+	if existing, found := keyPools[keyBitSize]; found {
+		existing.Stop()
+	}
+	keyPools[keyBitSize] = pool
+}
+
+// DisableKeyPools stops all background key generation started with
+// EnableKeyPool
+func DisableKeyPools() {
+	keyPoolsMutex.Lock()
+	defer keyPoolsMutex.Unlock()
+	for keyBitSize, pool := range keyPools {
+		pool.Stop()
+		delete(keyPools, keyBitSize)
+	}
+}
+
+func keyPoolFor(keyBitSize int) *KeyPool {
+	keyPoolsMutex.RLock()
+	defer keyPoolsMutex.RUnlock()
+	return keyPools[keyBitSize]
+}
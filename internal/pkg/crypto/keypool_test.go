@@ -0,0 +1,75 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package crypto
+
+import "testing"
+
+const (
+	benchmarkKeyBitSize = 2048
+	testKeyBitSize      = 2048
+)
+
+func TestKeyPoolGet(t *testing.T) {
+	pool := NewKeyPool(testKeyBitSize, 3)
+	pool.Start()
+	defer pool.Stop()
+
+	seen := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		key, err := pool.Get()
+		if err != nil {
+			t.Fatalf("could not get a key from the pool: %v", err)
+		}
+		if key == nil {
+			t.Fatal("expected a key, got nil")
+		}
+		if seen[key.PrivateKey] {
+			t.Fatalf("key %d was already returned by a previous Get call", i)
+		}
+		seen[key.PrivateKey] = true
+	}
+}
+
+func TestKeyPoolGetAfterStop(t *testing.T) {
+	pool := NewKeyPool(testKeyBitSize, 3)
+	pool.Start()
+	pool.Stop()
+
+	if _, err := pool.Get(); err != nil {
+		t.Fatalf("expected Get to fall back to inline generation after Stop, got: %v", err)
+	}
+}
+
+func BenchmarkNewPrivateKey(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := newPrivateKey(benchmarkKeyBitSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewPrivateKeyFromPool(b *testing.B) {
+	pool := NewKeyPool(benchmarkKeyBitSize, b.N)
+	pool.Start()
+	defer pool.Stop()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := pool.Get(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+)
+
+// KMSClient performs RSA-OAEP decryption on behalf of a KMSKeyPair,
+// keeping the private key inside a KMS or HSM instead of on the
+// management cluster. KeyID identifies which key the KMS/HSM should
+// use to decrypt ciphertext
+type KMSClient interface {
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// KMSKeyPair is a KeyPairProvider whose private key lives in a
+// KMS/HSM reachable through a KMSClient. Only the public key is kept
+// locally, which is enough to encrypt and to hand out to joining
+// nodes; every Decrypt call is delegated to the KMS/HSM
+type KMSKeyPair struct {
+	PublicKey string
+	KeyID     string
+
+	client    KMSClient
+	publicKey *rsa.PublicKey
+}
+
+// NewKMSKeyPair returns a KMSKeyPair backed by the given KMS/HSM
+// client, identifying the remote key with keyID and its PEM encoded
+// public key with publicKeyPEM
+func NewKMSKeyPair(publicKeyPEM, keyID string, client KMSClient) (*KMSKeyPair, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, errors.New("could not parse public key")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("could not identify public key as an RSA public key")
+	}
+	return &KMSKeyPair{
+		PublicKey: publicKeyPEM,
+		KeyID:     keyID,
+		client:    client,
+		publicKey: rsaPublicKey,
+	}, nil
+}
+
+// Encrypt encrypts the given content using the locally held public
+// key, producing a base64 result
+func (keyPair *KMSKeyPair) Encrypt(content string) (string, error) {
+	encryptedContents, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, keyPair.publicKey, []byte(content), []byte(""))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(encryptedContents), nil
+}
+
+// Decrypt decrypts the given base-64 contents by delegating to the
+// KMS/HSM client
+func (keyPair *KMSKeyPair) Decrypt(content string) (string, error) {
+	rawContent, err := base64.RawStdEncoding.DecodeString(content)
+	if err != nil {
+		return "", err
+	}
+	decryptedContents, err := keyPair.client.Decrypt(keyPair.KeyID, rawContent)
+	if err != nil {
+		return "", err
+	}
+	return string(decryptedContents), nil
+}
@@ -0,0 +1,163 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+
+	"golang.org/x/crypto/nacl/box"
+
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+)
+
+// NaClBoxKeyPair is a KeyPairProvider backed by a Curve25519 key
+// pair, encrypting with NaCl's anonymous sealed box construction
+// (XSalsa20-Poly1305) instead of RSA-OAEP. It is selected as an
+// alternative join key cipher suite for clusters that prefer a
+// smaller, modern key over an RSA key pair
+type NaClBoxKeyPair struct {
+	// PublicKey is the base64 encoded Curve25519 public key
+	PublicKey string
+	// PrivateKey is the base64 encoded Curve25519 private key
+	PrivateKey string
+	Provenance *commonv1alpha1.Provenance
+
+	publicKey  *[32]byte
+	privateKey *[32]byte
+}
+
+// NewNaClBoxKeyPair generates a new Curve25519 key pair
+func NewNaClBoxKeyPair() (*NaClBoxKeyPair, error) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &NaClBoxKeyPair{
+		PublicKey:  base64.RawStdEncoding.EncodeToString(publicKey[:]),
+		PrivateKey: base64.RawStdEncoding.EncodeToString(privateKey[:]),
+		Provenance: commonv1alpha1.NewProvenance(constants.BuildVersion),
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}, nil
+}
+
+// NewNaClBoxKeyPairFromv1alpha1 returns a NaCl box key pair from a
+// versioned key pair
+func NewNaClBoxKeyPairFromv1alpha1(keyPair *commonv1alpha1.KeyPair) (*NaClBoxKeyPair, error) {
+	if keyPair == nil {
+		return nil, nil
+	}
+	publicKey, err := decodeNaClBoxKey(keyPair.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := decodeNaClBoxKey(keyPair.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &NaClBoxKeyPair{
+		PublicKey:  keyPair.PublicKey,
+		PrivateKey: keyPair.PrivateKey,
+		Provenance: keyPair.Provenance,
+		publicKey:  publicKey,
+		privateKey: privateKey,
+	}, nil
+}
+
+func decodeNaClBoxKey(encoded string) (*[32]byte, error) {
+	raw, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var key [32]byte
+	copy(key[:], raw)
+	return &key, nil
+}
+
+// Export exports the key pair to a versioned key pair
+func (keyPair *NaClBoxKeyPair) Export() *commonv1alpha1.KeyPair {
+	if keyPair == nil {
+		return nil
+	}
+	provenance := keyPair.Provenance
+	if provenance == nil {
+		provenance = commonv1alpha1.NewProvenance(constants.BuildVersion)
+	}
+	return &commonv1alpha1.KeyPair{
+		PublicKey:  keyPair.PublicKey,
+		PrivateKey: keyPair.PrivateKey,
+		Provenance: provenance,
+	}
+}
+
+// NaClBoxPublicKey represents a Curve25519 public key, used by
+// joining nodes to encrypt their symmetric key without needing the
+// cluster's private key
+type NaClBoxPublicKey struct {
+	PublicKey string
+	publicKey *[32]byte
+}
+
+// NewNaClBoxPublicKeyFromString returns a NaCl box public key from
+// its base64 encoding
+func NewNaClBoxPublicKeyFromString(publicKeyBase64 string) (*NaClBoxPublicKey, error) {
+	publicKey, err := decodeNaClBoxKey(publicKeyBase64)
+	if err != nil {
+		return nil, err
+	}
+	return &NaClBoxPublicKey{
+		PublicKey: publicKeyBase64,
+		publicKey: publicKey,
+	}, nil
+}
+
+// Encrypt encrypts the given content using this public key,
+// producing a base64 result
+func (publicKey *NaClBoxPublicKey) Encrypt(content string) (string, error) {
+	sealed, err := box.SealAnonymous(nil, []byte(content), publicKey.publicKey, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Encrypt encrypts the given content using this key pair's public
+// key, producing a base64 result
+func (keyPair *NaClBoxKeyPair) Encrypt(content string) (string, error) {
+	sealed, err := box.SealAnonymous(nil, []byte(content), keyPair.publicKey, rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the given base-64 contents using this key pair's
+// private key
+func (keyPair *NaClBoxKeyPair) Decrypt(content string) (string, error) {
+	sealed, err := base64.RawStdEncoding.DecodeString(content)
+	if err != nil {
+		return "", err
+	}
+	opened, ok := box.OpenAnonymous(nil, sealed, keyPair.publicKey, keyPair.privateKey)
+	if !ok {
+		return "", errors.New("could not decrypt contents")
+	}
+	return string(opened), nil
+}
@@ -0,0 +1,208 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package crypto
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+	"github.com/oneinfra/oneinfra/internal/pkg/constants"
+)
+
+// SigningKeyPair represents an Ed25519 key pair used to produce and
+// verify detached signatures over exported manifests and join
+// payloads
+type SigningKeyPair struct {
+	PublicKey  string
+	PrivateKey string
+	Provenance *commonv1alpha1.Provenance
+	key        ed25519.PrivateKey
+}
+
+// SigningPublicKey represents the public half of an Ed25519 signing
+// key pair, used to verify detached signatures
+type SigningPublicKey struct {
+	PublicKey string
+	key       ed25519.PublicKey
+}
+
+// NewSigningKeyPair generates a new Ed25519 signing key pair
+func NewSigningKeyPair() (*SigningKeyPair, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	publicKeyPEM, err := marshalSigningPublicKeyPEM(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	privateKeyPEM, err := marshalSigningPrivateKeyPEM(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKeyPair{
+		PublicKey:  publicKeyPEM,
+		PrivateKey: privateKeyPEM,
+		Provenance: commonv1alpha1.NewProvenance(constants.BuildVersion),
+		key:        privateKey,
+	}, nil
+}
+
+// NewSigningKeyPairFromv1alpha1 returns a signing key pair from a
+// versioned key pair
+func NewSigningKeyPairFromv1alpha1(keyPair *commonv1alpha1.KeyPair) (*SigningKeyPair, error) {
+	if keyPair == nil {
+		return nil, nil
+	}
+	res, err := NewSigningKeyPairFromString(keyPair.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	res.Provenance = keyPair.Provenance
+	return res, nil
+}
+
+// NewSigningKeyPairFromString returns a signing key pair from a PEM
+// encoded Ed25519 private key
+func NewSigningKeyPairFromString(privateKeyPEM string) (*SigningKeyPair, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("could not parse private key")
+	}
+	parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := parsedKey.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errors.New("could not identify private key as an Ed25519 private key")
+	}
+	publicKeyPEM, err := marshalSigningPublicKeyPEM(privateKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKeyPair{
+		PublicKey:  publicKeyPEM,
+		PrivateKey: string(privateKeyPEM),
+		key:        privateKey,
+	}, nil
+}
+
+// Export exports the signing key pair to a versioned key pair
+func (signingKeyPair *SigningKeyPair) Export() *commonv1alpha1.KeyPair {
+	if signingKeyPair == nil {
+		return nil
+	}
+	provenance := signingKeyPair.Provenance
+	if provenance == nil {
+		provenance = commonv1alpha1.NewProvenance(constants.BuildVersion)
+	}
+	return &commonv1alpha1.KeyPair{
+		PublicKey:  signingKeyPair.PublicKey,
+		PrivateKey: signingKeyPair.PrivateKey,
+		Provenance: provenance,
+	}
+}
+
+// Sign produces a base64 encoded detached Ed25519 signature of content
+func (signingKeyPair *SigningKeyPair) Sign(content string) string {
+	signature := ed25519.Sign(signingKeyPair.key, []byte(content))
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+// SigningPublicKey returns the public half of this signing key pair
+func (signingKeyPair *SigningKeyPair) SigningPublicKey() *SigningPublicKey {
+	return &SigningPublicKey{
+		PublicKey: signingKeyPair.PublicKey,
+		key:       signingKeyPair.key.Public().(ed25519.PublicKey),
+	}
+}
+
+// NewSigningPublicKeyFromFile returns a signing public key from a PEM
+// encoded Ed25519 public key file in the given path
+func NewSigningPublicKeyFromFile(publicKeyPEMPath string) (*SigningPublicKey, error) {
+	publicKeyPEM, err := ioutil.ReadFile(publicKeyPEMPath)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningPublicKeyFromString(string(publicKeyPEM))
+}
+
+// NewSigningPublicKeyFromString returns a signing public key from a
+// PEM encoded Ed25519 public key
+func NewSigningPublicKeyFromString(publicKeyPEM string) (*SigningPublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, errors.New("could not parse public key")
+	}
+	parsedKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, ok := parsedKey.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("could not identify public key as an Ed25519 public key")
+	}
+	return &SigningPublicKey{
+		PublicKey: publicKeyPEM,
+		key:       publicKey,
+	}, nil
+}
+
+// Verify verifies a base64 encoded detached Ed25519 signature of
+// content, returning an error when the signature does not match
+func (signingPublicKey *SigningPublicKey) Verify(content, signature string) error {
+	rawSignature, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errors.New("could not decode signature")
+	}
+	if !ed25519.Verify(signingPublicKey.key, []byte(content), rawSignature) {
+		return errors.New("signature verification failed")
+	}
+	return nil
+}
+
+func marshalSigningPublicKeyPEM(publicKey ed25519.PublicKey) (string, error) {
+	encoded, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &pem.Block{Type: "PUBLIC KEY", Bytes: encoded}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func marshalSigningPrivateKeyPEM(privateKey ed25519.PrivateKey) (string, error) {
+	encoded, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &pem.Block{Type: "PRIVATE KEY", Bytes: encoded}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -21,12 +21,25 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 	"io"
 )
 
 // SymmetricKey represents a symmetric key
 type SymmetricKey string
 
+// NewSymmetricKey generates a new random symmetric key, suitable for
+// AES-256 (Encrypt and Decrypt use the key string bytes directly as
+// the cipher key, so its length, once hex encoded, already matches
+// what aes.NewCipher expects)
+func NewSymmetricKey() (SymmetricKey, error) {
+	rawKey := make([]byte, 16)
+	if _, err := rand.Read(rawKey); err != nil {
+		return "", err
+	}
+	return SymmetricKey(fmt.Sprintf("%x", rawKey)), nil
+}
+
 // Encrypt encrypts the given content using this symmetric key,
 // producing a base64 result
 func (symmetricKey SymmetricKey) Encrypt(content string) (string, error) {
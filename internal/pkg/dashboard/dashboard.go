@@ -0,0 +1,108 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package dashboard aggregates per-cluster health, latency and
+// capacity observations in memory, and serves them as a single
+// Grafana-ready JSON document, so platform teams get a baseline
+// dashboard without standing up a separate metrics pipeline
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// historyLength is the number of samples retained per cluster. At
+// one sample per reconcile, this keeps a few hours of history for a
+// cluster reconciling every couple of minutes
+const historyLength = 180
+
+// Sample is a single point-in-time observation of a cluster's
+// reconcile health, latency and capacity
+type Sample struct {
+	Timestamp              time.Time `json:"timestamp"`
+	Ready                  bool      `json:"ready"`
+	ReconcileLatencyMillis int64     `json:"reconcileLatencyMillis"`
+	ComponentCount         int       `json:"componentCount"`
+	ReadyComponentCount    int       `json:"readyComponentCount"`
+}
+
+// ClusterSummary is a cluster's identity plus its retained history
+// of samples
+type ClusterSummary struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	History   []Sample `json:"history"`
+}
+
+// Aggregator collects per-cluster samples in memory and serves them
+// over HTTP. It is safe for concurrent use
+type Aggregator struct {
+	mu       sync.Mutex
+	clusters map[string]*ClusterSummary
+}
+
+// NewAggregator returns an empty aggregator
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		clusters: map[string]*ClusterSummary{},
+	}
+}
+
+// Record appends a sample for the namespace/name cluster, evicting
+// the oldest sample once historyLength is exceeded
+func (aggregator *Aggregator) Record(namespace, name string, sample Sample) {
+	aggregator.mu.Lock()
+	defer aggregator.mu.Unlock()
+	key := namespace + "/" + name
+	summary, exists := aggregator.clusters[key]
+	if !exists {
+		summary = &ClusterSummary{Namespace: namespace, Name: name}
+		aggregator.clusters[key] = summary
+	}
+	summary.History = append(summary.History, sample)
+	if len(summary.History) > historyLength {
+		summary.History = summary.History[len(summary.History)-historyLength:]
+	}
+}
+
+// Summaries returns a snapshot of every tracked cluster's retained
+// history
+func (aggregator *Aggregator) Summaries() []ClusterSummary {
+	aggregator.mu.Lock()
+	defer aggregator.mu.Unlock()
+	res := make([]ClusterSummary, 0, len(aggregator.clusters))
+	for _, summary := range aggregator.clusters {
+		history := make([]Sample, len(summary.History))
+		copy(history, summary.History)
+		res = append(res, ClusterSummary{
+			Namespace: summary.Namespace,
+			Name:      summary.Name,
+			History:   history,
+		})
+	}
+	return res
+}
+
+// ServeHTTP serves the current aggregated dashboard data as JSON
+func (aggregator *Aggregator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(aggregator.Summaries()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
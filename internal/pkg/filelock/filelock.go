@@ -0,0 +1,54 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package filelock
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Lock represents an exclusive advisory lock held on a file
+type Lock struct {
+	file *os.File
+}
+
+// Acquire takes an exclusive advisory lock on path, creating it if
+// it does not exist yet, blocking until the lock is available. It is
+// meant to serialize state-mutating invocations of CLI commands such
+// as "oi reconcile" that read and write the same resources, so two
+// concurrent invocations don't race against each other
+func Acquire(path string) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(file.Fd()), unix.LOCK_EX); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Lock{file: file}, nil
+}
+
+// Release releases the lock
+func (lock *Lock) Release() error {
+	if err := unix.Flock(int(lock.file.Fd()), unix.LOCK_UN); err != nil {
+		lock.file.Close()
+		return err
+	}
+	return lock.file.Close()
+}
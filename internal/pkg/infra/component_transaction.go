@@ -0,0 +1,162 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	"k8s.io/klog/v2"
+
+	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
+)
+
+// ComponentTransaction identifies an in-progress component
+// provisioning attempt on an hypervisor
+type ComponentTransaction struct {
+	ClusterNamespace string
+	Cluster          string
+	Component        string
+}
+
+// ComponentTransactionList represents a list of component transactions
+type ComponentTransactionList []ComponentTransaction
+
+// NewComponentTransactionListFromv1alpha1 creates a component transaction list
+func NewComponentTransactionListFromv1alpha1(componentTransactionList []infrav1alpha1.ComponentTransaction) ComponentTransactionList {
+	res := ComponentTransactionList{}
+	for _, componentTransaction := range componentTransactionList {
+		res = append(res, ComponentTransaction{
+			ClusterNamespace: componentTransaction.ClusterNamespace,
+			Cluster:          componentTransaction.Cluster,
+			Component:        componentTransaction.Component,
+		})
+	}
+	return res
+}
+
+// Export exports the component transaction list to a versioned object
+func (componentTransactionList ComponentTransactionList) Export() []infrav1alpha1.ComponentTransaction {
+	res := []infrav1alpha1.ComponentTransaction{}
+	for _, componentTransaction := range componentTransactionList {
+		res = append(res, infrav1alpha1.ComponentTransaction{
+			ClusterNamespace: componentTransaction.ClusterNamespace,
+			Cluster:          componentTransaction.Cluster,
+			Component:        componentTransaction.Component,
+		})
+	}
+	return res
+}
+
+// BeginComponentTransaction records that componentName's artifacts
+// (pod, containers and ports) are about to be created on this
+// hypervisor, so a reconcile that crashes before committing can be
+// detected and rolled back on the next run instead of leaking
+// partial state
+func (hypervisor *Hypervisor) BeginComponentTransaction(clusterNamespace, clusterName, componentName string) {
+	if hypervisor.HasPendingComponentTransaction(clusterNamespace, clusterName, componentName) {
+		return
+	}
+	hypervisor.pendingTransactions = append(hypervisor.pendingTransactions, ComponentTransaction{
+		ClusterNamespace: clusterNamespace,
+		Cluster:          clusterName,
+		Component:        componentName,
+	})
+}
+
+// CommitComponentTransaction marks componentName's artifacts as
+// fully created on this hypervisor, clearing its transaction log
+// entry
+func (hypervisor *Hypervisor) CommitComponentTransaction(clusterNamespace, clusterName, componentName string) {
+	newPendingTransactions := ComponentTransactionList{}
+	for _, transaction := range hypervisor.pendingTransactions {
+		if transaction.ClusterNamespace == clusterNamespace && transaction.Cluster == clusterName && transaction.Component == componentName {
+			continue
+		}
+		newPendingTransactions = append(newPendingTransactions, transaction)
+	}
+	hypervisor.pendingTransactions = newPendingTransactions
+}
+
+// HasPendingComponentTransaction returns whether componentName has
+// an in-progress, uncommitted transaction on this hypervisor
+func (hypervisor *Hypervisor) HasPendingComponentTransaction(clusterNamespace, clusterName, componentName string) bool {
+	for _, transaction := range hypervisor.pendingTransactions {
+		if transaction.ClusterNamespace == clusterNamespace && transaction.Cluster == clusterName && transaction.Component == componentName {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingComponentTransactions returns the component transactions
+// still pending on this hypervisor, left behind by a reconcile that
+// started creating a component but never committed it, e.g. because
+// it crashed or was interrupted
+func (hypervisor *Hypervisor) PendingComponentTransactions() ComponentTransactionList {
+	return hypervisor.pendingTransactions
+}
+
+// RollbackComponentTransaction removes the partial pod, containers,
+// port allocations and uploaded files created for componentName on
+// this hypervisor, and clears its transaction log entry, so a
+// failed or interrupted provisioning attempt doesn't leak resources
+func (hypervisor *Hypervisor) RollbackComponentTransaction(clusterNamespace, clusterName, componentName string) error {
+	defer hypervisor.CommitComponentTransaction(clusterNamespace, clusterName, componentName)
+	if err := hypervisor.DeletePods(clusterNamespace, clusterName, componentName); err != nil {
+		return err
+	}
+	hypervisor.freeComponentPorts(clusterNamespace, clusterName, componentName)
+	hypervisor.removeComponentFiles(clusterNamespace, clusterName, componentName)
+	return nil
+}
+
+// freeComponentPorts frees every port allocated to componentName or
+// to one of its subcomponents (named "componentName-subcomponent")
+// on this hypervisor
+func (hypervisor *Hypervisor) freeComponentPorts(clusterNamespace, clusterName, componentName string) {
+	for _, portAllocation := range hypervisor.allocatedPorts {
+		if portAllocation.ClusterNamespace != clusterNamespace || portAllocation.Cluster != clusterName {
+			continue
+		}
+		if portAllocation.Component != componentName && !isComponentSubcomponent(componentName, portAllocation.Component) {
+			continue
+		}
+		if err := hypervisor.FreePort(clusterNamespace, clusterName, portAllocation.Component); err != nil {
+			klog.Warningf("failed to free port for component %q on hypervisor %q: %v", portAllocation.Component, hypervisor.Name, err)
+		}
+	}
+}
+
+// removeComponentFiles removes every file uploaded for componentName
+// on this hypervisor
+func (hypervisor *Hypervisor) removeComponentFiles(clusterNamespace, clusterName, componentName string) {
+	clusterFileMap, exists := hypervisor.Files[clusterNamespace]
+	if !exists {
+		return
+	}
+	componentFileMap, exists := clusterFileMap[clusterName]
+	if !exists {
+		return
+	}
+	delete(componentFileMap, componentName)
+}
+
+// isComponentSubcomponent returns whether subcomponentName is named
+// after componentName, following the "componentName-subcomponent"
+// convention used for per-subcomponent port allocations
+func isComponentSubcomponent(componentName, subcomponentName string) bool {
+	prefix := componentName + "-"
+	return len(subcomponentName) > len(prefix) && subcomponentName[:len(prefix)] == prefix
+}
@@ -0,0 +1,60 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// minimumEtcdDataDirFreeBytes is the minimum amount of free space
+// required on an overridden etcd data directory before a component
+// is allowed to be placed on it
+const minimumEtcdDataDirFreeBytes uint64 = 1 * 1024 * 1024 * 1024 // 1GiB
+
+// ValidateEtcdDataDir checks that the hypervisor's overridden etcd
+// data directory exists and has enough free space to host etcd
+// data. This check is only meaningful for hypervisors reachable on
+// the local filesystem (i.e. using a LocalCRIEndpoint); the manager
+// has no direct filesystem access to remote hypervisors, so those
+// are trusted to have been provisioned correctly and are skipped.
+func (hypervisor *Hypervisor) ValidateEtcdDataDir() error {
+	if hypervisor.EtcdDataDir == "" {
+		return nil
+	}
+	if _, isLocal := hypervisor.Endpoint.(*localHypervisorEndpoint); !isLocal {
+		return nil
+	}
+	info, err := os.Stat(hypervisor.EtcdDataDir)
+	if err != nil {
+		return errors.Wrapf(err, "etcd data directory %q for hypervisor %q is not accessible", hypervisor.EtcdDataDir, hypervisor.Name)
+	}
+	if !info.IsDir() {
+		return errors.Errorf("etcd data directory %q for hypervisor %q is not a directory", hypervisor.EtcdDataDir, hypervisor.Name)
+	}
+	var fsStat syscall.Statfs_t
+	if err := syscall.Statfs(hypervisor.EtcdDataDir, &fsStat); err != nil {
+		return errors.Wrapf(err, "could not check free space on etcd data directory %q for hypervisor %q", hypervisor.EtcdDataDir, hypervisor.Name)
+	}
+	freeBytes := fsStat.Bavail * uint64(fsStat.Bsize)
+	if freeBytes < minimumEtcdDataDirFreeBytes {
+		return errors.Errorf("etcd data directory %q for hypervisor %q has %d bytes free, at least %d are required", hypervisor.EtcdDataDir, hypervisor.Name, freeBytes, minimumEtcdDataDirFreeBytes)
+	}
+	return nil
+}
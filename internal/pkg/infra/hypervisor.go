@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math/rand"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -33,6 +34,7 @@ import (
 	criapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 	"k8s.io/klog/v2"
 
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
 	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
 	podapi "github.com/oneinfra/oneinfra/internal/pkg/infra/pod"
 )
@@ -44,6 +46,7 @@ const (
 
 const (
 	podSandboxSHA1SumLabel = "oneinfra/pod-sha1sum"
+	containerSHA1SumLabel  = "oneinfra/container-sha1sum"
 	clusterNamespaceLabel  = "oneinfra/cluster-namespace"
 	clusterNameLabel       = "oneinfra/cluster-name"
 	componentNameLabel     = "oneinfra/component-name"
@@ -52,22 +55,34 @@ const (
 
 // Hypervisor represents an hypervisor
 type Hypervisor struct {
-	Name               string
-	ResourceVersion    string
-	Labels             map[string]string
-	Annotations        map[string]string
-	Public             bool
-	IPAddress          string
-	Files              NamespacedClusterFileMap
-	Endpoint           hypervisorEndpoint
-	criRuntime         criapi.RuntimeServiceClient
-	criImage           criapi.ImageServiceClient
-	portRangeLow       int
-	portRangeHigh      int
-	freedPorts         []int
-	allocatedPorts     HypervisorPortAllocationList
-	loadedContentsHash string
-	connectionPool     *HypervisorConnectionPool
+	Name                              string
+	ResourceVersion                   string
+	Labels                            map[string]string
+	Annotations                       map[string]string
+	Public                            bool
+	IPAddress                         string
+	EtcdDataDir                       string
+	Files                             NamespacedClusterFileMap
+	Endpoint                          hypervisorEndpoint
+	criRuntime                        criapi.RuntimeServiceClient
+	criImage                          criapi.ImageServiceClient
+	portRangeLow                      int
+	portRangeHigh                     int
+	freedPorts                        []int
+	allocatedPorts                    HypervisorPortAllocationList
+	loadedContentsHash                string
+	connectionPool                    *HypervisorConnectionPool
+	allowedCPUSet                     string
+	allowedMemoryMB                   int64
+	maxComponents                     int
+	proxy                             *commonv1alpha1.Proxy
+	seLinux                           *infrav1alpha1.HypervisorSELinux
+	imageSignaturePolicy              *commonv1alpha1.ImageSignaturePolicy
+	pendingTransactions               ComponentTransactionList
+	podRestarts                       PodRestartList
+	preflightChecks                   PreflightCheckResultList
+	allowedExtraHostPathMountPrefixes []string
+	Unschedulable                     bool
 }
 
 // HypervisorMap represents a map of hypervisors
@@ -86,19 +101,33 @@ func NewHypervisorFromv1alpha1(hypervisor *infrav1alpha1.Hypervisor, connectionP
 		connectionPool = &HypervisorConnectionPool{}
 	}
 	res := Hypervisor{
-		Name:            hypervisor.Name,
-		ResourceVersion: hypervisor.ResourceVersion,
-		Labels:          hypervisor.Labels,
-		Annotations:     hypervisor.Annotations,
-		Public:          hypervisor.Spec.Public,
-		IPAddress:       hypervisor.Spec.IPAddress,
-		Files:           NewNamespacedClusterFileMapFromv1alpha1(hypervisorFiles),
-		portRangeLow:    hypervisor.Spec.PortRange.Low,
-		portRangeHigh:   hypervisor.Spec.PortRange.High,
-		freedPorts:      hypervisor.Status.FreedPorts,
-		allocatedPorts:  NewHypervisorPortAllocationListFromv1alpha1(hypervisor.Status.AllocatedPorts),
-		connectionPool:  connectionPool,
-	}
+		Name:                hypervisor.Name,
+		ResourceVersion:     hypervisor.ResourceVersion,
+		Labels:              hypervisor.Labels,
+		Annotations:         hypervisor.Annotations,
+		Public:              hypervisor.Spec.Public,
+		Unschedulable:       hypervisor.Spec.Unschedulable,
+		IPAddress:           hypervisor.Spec.IPAddress,
+		EtcdDataDir:         hypervisor.Spec.EtcdDataDir,
+		Files:               NewNamespacedClusterFileMapFromv1alpha1(hypervisorFiles),
+		portRangeLow:        hypervisor.Spec.PortRange.Low,
+		portRangeHigh:       hypervisor.Spec.PortRange.High,
+		freedPorts:          hypervisor.Status.FreedPorts,
+		allocatedPorts:      NewHypervisorPortAllocationListFromv1alpha1(hypervisor.Status.AllocatedPorts),
+		connectionPool:      connectionPool,
+		pendingTransactions: NewComponentTransactionListFromv1alpha1(hypervisor.Status.PendingComponentTransactions),
+		podRestarts:         NewPodRestartListFromv1alpha1(hypervisor.Status.PodRestarts),
+		preflightChecks:     NewPreflightCheckResultListFromv1alpha1(hypervisor.Status.PreflightChecks),
+	}
+	if reservations := hypervisor.Spec.Reservations; reservations != nil {
+		res.allowedCPUSet = reservations.CPUSet
+		res.allowedMemoryMB = reservations.MemoryMB
+		res.maxComponents = reservations.MaxComponents
+	}
+	res.proxy = hypervisor.Spec.Proxy
+	res.seLinux = hypervisor.Spec.SELinux
+	res.imageSignaturePolicy = hypervisor.Spec.ImageSignaturePolicy
+	res.allowedExtraHostPathMountPrefixes = hypervisor.Spec.AllowedExtraHostPathMountPrefixes
 	if err := setHypervisorEndpointFromv1alpha1(hypervisor, connectionPool, &res); err != nil {
 		return nil, err
 	}
@@ -180,6 +209,134 @@ func (hypervisor *Hypervisor) EnsureImages(images ...string) error {
 	return nil
 }
 
+// VerifyImageDigest checks that the image present on the current
+// hypervisor matches the expected content digest (e.g.
+// "sha256:abcd..."), returning an error if it does not. Callers are
+// expected to have called EnsureImage first, so the image is
+// guaranteed to be present
+func (hypervisor *Hypervisor) VerifyImageDigest(image, expectedDigest string) error {
+	criImage, err := hypervisor.CRIImage()
+	if err != nil {
+		return err
+	}
+	imageStatus, err := criImage.ImageStatus(context.TODO(), &criapi.ImageStatusRequest{
+		Image: &criapi.ImageSpec{
+			Image: image,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if imageStatus.Image == nil {
+		return errors.Errorf("image %q was not found in hypervisor %q, cannot verify its digest", image, hypervisor.Name)
+	}
+	for _, repoDigest := range imageStatus.Image.RepoDigests {
+		if strings.HasSuffix(repoDigest, expectedDigest) {
+			return nil
+		}
+	}
+	return errors.Errorf("image %q in hypervisor %q does not match the pinned digest %q", image, hypervisor.Name, expectedDigest)
+}
+
+// reservationAwareResources returns the Linux container resources
+// that enforce this hypervisor's reservations, or nil if none were
+// declared
+func (hypervisor *Hypervisor) reservationAwareResources() *criapi.LinuxContainerResources {
+	if hypervisor.allowedCPUSet == "" && hypervisor.allowedMemoryMB == 0 {
+		return nil
+	}
+	resources := &criapi.LinuxContainerResources{}
+	if hypervisor.allowedCPUSet != "" {
+		resources.CpusetCpus = hypervisor.allowedCPUSet
+	}
+	if hypervisor.allowedMemoryMB != 0 {
+		resources.MemoryLimitInBytes = hypervisor.allowedMemoryMB * 1024 * 1024
+	}
+	return resources
+}
+
+const defaultSELinuxType = "container_t"
+
+// selinuxOptions returns the CRI SELinux options to apply to
+// containers and their bind mounts scheduled on this hypervisor, or
+// nil when the hypervisor host was not declared to run with SELinux
+// enforcement
+func (hypervisor *Hypervisor) selinuxOptions() *criapi.SELinuxOption {
+	if hypervisor.seLinux == nil {
+		return nil
+	}
+	seLinuxType := hypervisor.seLinux.Type
+	if seLinuxType == "" {
+		seLinuxType = defaultSELinuxType
+	}
+	return &criapi.SELinuxOption{
+		Type:  seLinuxType,
+		Level: hypervisor.seLinux.Level,
+	}
+}
+
+// ProxyEnv returns the egress proxy environment variables that apply
+// to components scheduled on this hypervisor, with this hypervisor's
+// own proxy settings taking precedence over the cluster's
+func (hypervisor *Hypervisor) ProxyEnv(clusterProxy *commonv1alpha1.Proxy) map[string]string {
+	env := clusterProxy.Env()
+	for key, value := range hypervisor.proxy.Env() {
+		env[key] = value
+	}
+	return env
+}
+
+// EffectiveImageSignaturePolicy returns the image signature policy
+// that applies to components scheduled on this hypervisor, with this
+// hypervisor's own policy taking precedence over the cluster's when set
+func (hypervisor *Hypervisor) EffectiveImageSignaturePolicy(clusterPolicy *commonv1alpha1.ImageSignaturePolicy) *commonv1alpha1.ImageSignaturePolicy {
+	if hypervisor.imageSignaturePolicy != nil {
+		return hypervisor.imageSignaturePolicy
+	}
+	return clusterPolicy
+}
+
+// AllowsExtraHostPathMount returns whether hostPath falls under one
+// of this hypervisor's AllowedExtraHostPathMountPrefixes, and can
+// therefore be bind mounted into a component scheduled on it through
+// a cluster's ExtraHostPathMounts
+func (hypervisor *Hypervisor) AllowsExtraHostPathMount(hostPath string) bool {
+	for _, allowedPrefix := range hypervisor.allowedExtraHostPathMountPrefixes {
+		if strings.HasPrefix(hostPath, allowedPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyImageSignature verifies that image passes cosign signature
+// verification against at least one of policy's public keys,
+// running cosign directly (no shell) from a one-shot tooling pod. A
+// nil policy, or one with no configured public keys, verifies
+// nothing
+func (hypervisor *Hypervisor) VerifyImageSignature(clusterNamespace, clusterName, componentName, image string, policy *commonv1alpha1.ImageSignaturePolicy) error {
+	if !policy.Enabled() {
+		return nil
+	}
+	if err := validateImageReference(image); err != nil {
+		return err
+	}
+	if err := hypervisor.EnsureImage(ToolingImage); err != nil {
+		return err
+	}
+	return hypervisor.RunAndWaitForPod(
+		clusterNamespace,
+		clusterName,
+		componentName,
+		podapi.NewPod(
+			fmt.Sprintf("%s-%s-%s-cosign-verify", clusterNamespace, clusterName, componentName),
+			cosignVerifyContainers(image, policy.PublicKeys),
+			map[int]int{},
+			podapi.PrivilegesUnprivileged,
+		),
+	)
+}
+
 // PodSandboxConfig returns a pod sandbox config for the given pod and cluster
 func (hypervisor *Hypervisor) PodSandboxConfig(clusterNamespace, clusterName, componentName string, pod podapi.Pod) (criapi.PodSandboxConfig, error) {
 	portMappings := []*criapi.PortMapping{}
@@ -189,7 +346,7 @@ func (hypervisor *Hypervisor) PodSandboxConfig(clusterNamespace, clusterName, co
 			ContainerPort: int32(podPort),
 		})
 	}
-	podSum, err := pod.SHA1Sum()
+	sandboxSum, err := pod.SandboxSHA1Sum()
 	if err != nil {
 		return criapi.PodSandboxConfig{}, err
 	}
@@ -203,26 +360,36 @@ func (hypervisor *Hypervisor) PodSandboxConfig(clusterNamespace, clusterName, co
 	if len(componentName) > 0 {
 		clusterAndComponentName += fmt.Sprintf("%s-", componentName)
 	}
+	sandboxLabels := map[string]string{}
+	for key, value := range pod.Labels {
+		sandboxLabels[key] = value
+	}
+	for key, value := range map[string]string{
+		clusterNamespaceLabel:  clusterNamespace,
+		clusterNameLabel:       clusterName,
+		componentNameLabel:     componentName,
+		podNameLabel:           pod.Name,
+		podSandboxSHA1SumLabel: sandboxSum,
+	} {
+		sandboxLabels[key] = value
+	}
 	podSandboxConfig := criapi.PodSandboxConfig{
 		Metadata: &criapi.PodSandboxMetadata{
 			Name:      pod.Name,
-			Namespace: fmt.Sprintf("%s%s-%s", clusterAndComponentName, pod.Name, podSum),
-			Uid:       podSum,
-		},
-		Labels: map[string]string{
-			clusterNamespaceLabel:  clusterNamespace,
-			clusterNameLabel:       clusterName,
-			componentNameLabel:     componentName,
-			podNameLabel:           pod.Name,
-			podSandboxSHA1SumLabel: podSum,
+			Namespace: fmt.Sprintf("%s%s-%s", clusterAndComponentName, pod.Name, sandboxSum),
+			Uid:       sandboxSum,
 		},
+		Labels:       sandboxLabels,
+		Annotations:  pod.Annotations,
 		PortMappings: portMappings,
 		LogDirectory: "/var/log/pods/",
 	}
-	if pod.Privileges&podapi.PrivilegesPrivileged != 0 {
+	selinuxOptions := hypervisor.selinuxOptions()
+	if pod.Privileges&podapi.PrivilegesPrivileged != 0 || selinuxOptions != nil {
 		podSandboxConfig.Linux = &criapi.LinuxPodSandboxConfig{
 			SecurityContext: &criapi.LinuxSandboxSecurityContext{
-				Privileged: true,
+				Privileged:     pod.Privileges&podapi.PrivilegesPrivileged != 0,
+				SelinuxOptions: selinuxOptions,
 			},
 		}
 	}
@@ -242,7 +409,7 @@ func (hypervisor *Hypervisor) IsPodRunning(clusterNamespace, clusterName, compon
 	if err != nil {
 		return false, "", false, nil, nil, err
 	}
-	podSum, err := pod.SHA1Sum()
+	sandboxSum, err := pod.SandboxSHA1Sum()
 	if err != nil {
 		return false, "", false, nil, nil, err
 	}
@@ -256,7 +423,7 @@ func (hypervisor *Hypervisor) IsPodRunning(clusterNamespace, clusterName, compon
 					clusterNameLabel:       clusterName,
 					componentNameLabel:     componentName,
 					podNameLabel:           pod.Name,
-					podSandboxSHA1SumLabel: podSum,
+					podSandboxSHA1SumLabel: sandboxSum,
 				},
 			},
 		},
@@ -286,6 +453,10 @@ func (hypervisor *Hypervisor) IsPodRunning(clusterNamespace, clusterName, compon
 	if err != nil {
 		return false, podSandboxID, false, nil, nil, err
 	}
+	containerSpecs := map[string]podapi.Container{}
+	for _, containerSpec := range pod.Containers {
+		containerSpecs[containerSpec.Name] = containerSpec
+	}
 	podRunningContainers := map[string]*criapi.Container{}
 	podNotRunningContainers := map[string]*criapi.Container{}
 	for _, container := range containerList.Containers {
@@ -298,7 +469,7 @@ func (hypervisor *Hypervisor) IsPodRunning(clusterNamespace, clusterName, compon
 		if err != nil {
 			continue
 		}
-		if containerStatus.Status.State == criapi.ContainerState_CONTAINER_RUNNING {
+		if containerStatus.Status.State == criapi.ContainerState_CONTAINER_RUNNING && containerMatchesSpec(container, containerSpecs[container.Metadata.Name]) {
 			podRunningContainers[container.Metadata.Name] = container
 		} else {
 			podNotRunningContainers[container.Metadata.Name] = container
@@ -312,6 +483,20 @@ func (hypervisor *Hypervisor) IsPodRunning(clusterNamespace, clusterName, compon
 		nil
 }
 
+// containerMatchesSpec returns whether container, as reported by the
+// CRI runtime, was created from containerSpec, by comparing
+// containerSpec's hash against the one recorded on the container at
+// creation time. A container whose spec has drifted (a new image
+// digest, changed command or env) no longer matches, even though the
+// CRI runtime still reports it as running
+func containerMatchesSpec(container *criapi.Container, containerSpec podapi.Container) bool {
+	containerSum, err := containerSpec.SHA1Sum()
+	if err != nil {
+		return false
+	}
+	return container.Labels[containerSHA1SumLabel] == containerSum
+}
+
 // EnsurePod runs a pod on the current hypervisor
 func (hypervisor *Hypervisor) EnsurePod(clusterNamespace, clusterName, componentName string, pod podapi.Pod) (string, error) {
 	isPodRunning, podSandboxID, allContainersRunning, podRunningContainers, podNotRunningContainers, err := hypervisor.IsPodRunning(clusterNamespace, clusterName, componentName, pod)
@@ -320,8 +505,29 @@ func (hypervisor *Hypervisor) EnsurePod(clusterNamespace, clusterName, component
 	}
 	if isPodRunning && allContainersRunning {
 		klog.V(2).Infof("pod %q and all its containers in hypervisor %q are running", pod.Name, hypervisor.Name)
+		hypervisor.clearPodRestart(clusterNamespace, clusterName, componentName, pod.Name)
 		return podSandboxID, nil
 	}
+	if isPodRunning && !hypervisor.allowPodRestart(clusterNamespace, clusterName, componentName, pod.Name) {
+		return podSandboxID, errors.Errorf("not recreating pod %q for component %q on hypervisor %q yet: backing off, or the auto-repair circuit breaker has tripped", pod.Name, componentName, hypervisor.Name)
+	}
+	if isPodRunning {
+		// The existing pod sandbox still matches this pod's sandbox
+		// spec (name, ports, privileges), so it is reused as is: only
+		// the containers that are not running, or whose spec has
+		// drifted, are recreated within it, preserving the sandbox's
+		// IP and already allocated host ports
+		klog.V(2).Infof("reusing pod sandbox %q for pod %q in hypervisor %q", podSandboxID, pod.Name, hypervisor.Name)
+		return hypervisor.ensurePod(
+			clusterNamespace,
+			clusterName,
+			componentName,
+			podSandboxID,
+			podRunningContainers,
+			podNotRunningContainers,
+			pod,
+		)
+	}
 	if err := hypervisor.DeletePod(clusterNamespace, clusterName, componentName, pod.Name); err != nil {
 		klog.V(2).Infof("could not delete pods named %q: %v", pod.Name, err)
 	}
@@ -376,6 +582,16 @@ func (hypervisor *Hypervisor) ensureContainers(criRuntime criapi.RuntimeServiceC
 			continue
 		}
 		if notRunningContainer, exists := podNotRunningContainers[container.Name]; exists {
+			if notRunningContainer.State == criapi.ContainerState_CONTAINER_RUNNING {
+				if _, err := criRuntime.StopContainer(
+					context.TODO(),
+					&criapi.StopContainerRequest{
+						ContainerId: notRunningContainer.Id,
+					},
+				); err != nil {
+					klog.Warningf("failed to stop stale container %q: %v", notRunningContainer.Id, err)
+				}
+			}
 			_, err := criRuntime.RemoveContainer(
 				context.TODO(),
 				&criapi.RemoveContainerRequest{
@@ -385,13 +601,23 @@ func (hypervisor *Hypervisor) ensureContainers(criRuntime criapi.RuntimeServiceC
 				klog.Warningf("failed to remove container %q: %v", notRunningContainer.Id, err)
 			}
 		}
+		containerSum, err := container.SHA1Sum()
+		if err != nil {
+			return err
+		}
 		containerMounts := []*criapi.Mount{}
 		for hostPath, containerPath := range container.Mounts {
 			containerMounts = append(containerMounts, &criapi.Mount{
-				HostPath:      hostPath,
-				ContainerPath: containerPath,
+				HostPath:       hostPath,
+				ContainerPath:  containerPath,
+				SelinuxRelabel: hypervisor.seLinux != nil,
 			})
 		}
+		containerLabels := map[string]string{}
+		for key, value := range container.Labels {
+			containerLabels[key] = value
+		}
+		containerLabels[containerSHA1SumLabel] = containerSum
 		createContainerRequest := criapi.CreateContainerRequest{
 			PodSandboxId: podSandboxID,
 			Config: &criapi.ContainerConfig{
@@ -401,10 +627,12 @@ func (hypervisor *Hypervisor) ensureContainers(criRuntime criapi.RuntimeServiceC
 				Image: &criapi.ImageSpec{
 					Image: container.Image,
 				},
-				Command: container.Command,
-				Args:    container.Args,
-				Mounts:  containerMounts,
-				LogPath: fmt.Sprintf("%s-%s-%s.log", pod.Name, podSandboxID, container.Name),
+				Command:     container.Command,
+				Args:        container.Args,
+				Mounts:      containerMounts,
+				LogPath:     fmt.Sprintf("%s-%s-%s.log", pod.Name, podSandboxID, container.Name),
+				Labels:      containerLabels,
+				Annotations: container.Annotations,
 			},
 			SandboxConfig: &podSandboxConfig,
 		}
@@ -420,10 +648,12 @@ func (hypervisor *Hypervisor) ensureContainers(criRuntime criapi.RuntimeServiceC
 				)
 			}
 		}
-		if container.Privileges&podapi.PrivilegesPrivileged != 0 {
+		selinuxOptions := hypervisor.selinuxOptions()
+		if container.Privileges&podapi.PrivilegesPrivileged != 0 || selinuxOptions != nil {
 			createContainerRequest.Config.Linux = &criapi.LinuxContainerConfig{
 				SecurityContext: &criapi.LinuxContainerSecurityContext{
-					Privileged: true,
+					Privileged:     container.Privileges&podapi.PrivilegesPrivileged != 0,
+					SelinuxOptions: selinuxOptions,
 				},
 			}
 		}
@@ -432,6 +662,12 @@ func (hypervisor *Hypervisor) ensureContainers(criRuntime criapi.RuntimeServiceC
 				Network: criapi.NamespaceMode_NODE,
 			}
 		}
+		if resources := hypervisor.reservationAwareResources(); resources != nil {
+			if createContainerRequest.Config.Linux == nil {
+				createContainerRequest.Config.Linux = &criapi.LinuxContainerConfig{}
+			}
+			createContainerRequest.Config.Linux.Resources = resources
+		}
 		containerResponse, err := criRuntime.CreateContainer(
 			context.TODO(),
 			&createContainerRequest,
@@ -488,6 +724,55 @@ func (hypervisor *Hypervisor) WaitForPod(podSandboxID string) error {
 	}
 }
 
+// waitForPodContainerExitCodes waits for every container in
+// podSandboxID to have exited, returning the exit code of each,
+// keyed by container name
+func (hypervisor *Hypervisor) waitForPodContainerExitCodes(podSandboxID string) (map[string]int32, error) {
+	klog.V(2).Infof("waiting for pod %q to have completed on hypervisor %q", podSandboxID, hypervisor.Name)
+	criRuntime, err := hypervisor.CRIRuntime()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		containerList, err := criRuntime.ListContainers(
+			context.TODO(),
+			&criapi.ListContainersRequest{
+				Filter: &criapi.ContainerFilter{
+					PodSandboxId: podSandboxID,
+				},
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+		allContainersExited := true
+		for _, container := range containerList.Containers {
+			if container.State != criapi.ContainerState_CONTAINER_EXITED {
+				allContainersExited = false
+				break
+			}
+		}
+		if !allContainersExited {
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		exitCodes := map[string]int32{}
+		for _, container := range containerList.Containers {
+			containerStatus, err := criRuntime.ContainerStatus(
+				context.TODO(),
+				&criapi.ContainerStatusRequest{
+					ContainerId: container.Id,
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+			exitCodes[container.Metadata.Name] = containerStatus.Status.ExitCode
+		}
+		return exitCodes, nil
+	}
+}
+
 // ListPods returns a list of pod sandbox ID's that belong to the
 // provided cluster, component and pod name
 func (hypervisor *Hypervisor) ListPods(clusterNamespace, clusterName, componentName, podName string) ([]string, error) {
@@ -547,6 +832,47 @@ func (hypervisor *Hypervisor) ListAllPods(clusterNamespace, clusterName, compone
 	return res, nil
 }
 
+// OwnedPod represents a pod sandbox found running on an hypervisor
+// that carries oneinfra's own bookkeeping labels
+type OwnedPod struct {
+	SandboxID        string
+	ClusterNamespace string
+	ClusterName      string
+	ComponentName    string
+	PodName          string
+}
+
+// ListOwnedPods returns every pod sandbox running on this hypervisor
+// that carries oneinfra's own bookkeeping labels, regardless of the
+// cluster or component it belongs to. Pod sandboxes with no
+// oneinfra labels set are not oneinfra's to account for, and are
+// skipped
+func (hypervisor *Hypervisor) ListOwnedPods() ([]OwnedPod, error) {
+	criRuntime, err := hypervisor.CRIRuntime()
+	if err != nil {
+		return []OwnedPod{}, err
+	}
+	podSandboxList, err := criRuntime.ListPodSandbox(context.TODO(), &criapi.ListPodSandboxRequest{})
+	if err != nil {
+		return []OwnedPod{}, errors.Errorf("could not list pods for hypervisor %q", hypervisor.Name)
+	}
+	res := []OwnedPod{}
+	for _, podSandbox := range podSandboxList.Items {
+		clusterName, hasClusterName := podSandbox.Labels[clusterNameLabel]
+		if !hasClusterName {
+			continue
+		}
+		res = append(res, OwnedPod{
+			SandboxID:        podSandbox.Id,
+			ClusterNamespace: podSandbox.Labels[clusterNamespaceLabel],
+			ClusterName:      clusterName,
+			ComponentName:    podSandbox.Labels[componentNameLabel],
+			PodName:          podSandbox.Labels[podNameLabel],
+		})
+	}
+	return res, nil
+}
+
 // DeletePods deletes all pods matching the given cluster and component
 func (hypervisor *Hypervisor) DeletePods(clusterNamespace, clusterName, componentName string) error {
 	klog.V(2).Infof("deleting pods for cluster %q and component %q from hypervisor %q", clusterName, componentName, hypervisor.Name)
@@ -614,16 +940,33 @@ func (hypervisor *Hypervisor) RunAndWaitForPod(clusterNamespace, clusterName, co
 }
 
 // UploadFiles uploads a map of files, with location as keys, and
-// contents as values
+// contents as values. All key material oneinfra writes to a
+// hypervisor (certificates, private keys) is written 0600 by
+// write-base64-file.sh, and files whose contents are already
+// up-to-date still have their permissions re-enforced by
+// enforce-file-permissions.sh, so permissions drifted by something
+// else on a shared host get remediated on the next reconciliation
+// without a wasted content re-upload
 func (hypervisor *Hypervisor) UploadFiles(clusterNamespace, clusterName, componentName string, files map[string]string) error {
 	filesToUpload := []podapi.Container{}
 	for fileLocation, fileContents := range files {
+		fileLocationDir := filepath.Dir(fileLocation)
 		if hypervisor.FileUpToDate(clusterNamespace, clusterName, componentName, fileLocation, fileContents) {
-			klog.V(2).Infof("skipping file upload to hypervisor %q at location %q, hash matches", hypervisor.Name, fileLocation)
+			klog.V(2).Infof("file %q is up to date on hypervisor %q, re-enforcing its permissions", fileLocation, hypervisor.Name)
+			filesToUpload = append(
+				filesToUpload,
+				podapi.Container{
+					Name:       fmt.Sprintf("enforce-file-permissions-%x", sha1.Sum([]byte(fileLocation))),
+					Image:      ToolingImage,
+					Command:    []string{"enforce-file-permissions.sh"},
+					Args:       []string{fileLocation},
+					Mounts:     map[string]string{fileLocationDir: fileLocationDir},
+					Privileges: podapi.PrivilegesUnprivileged,
+				},
+			)
 			continue
 		}
 		klog.V(2).Infof("preparing file upload to hypervisor %q at location %q", hypervisor.Name, fileLocation)
-		fileLocationDir := filepath.Dir(fileLocation)
 		filesToUpload = append(
 			filesToUpload,
 			podapi.Container{
@@ -766,20 +1109,25 @@ func (hypervisor *Hypervisor) Export() *infrav1alpha1.Hypervisor {
 			Annotations:     hypervisor.Annotations,
 		},
 		Spec: infrav1alpha1.HypervisorSpec{
-			Public:    hypervisor.Public,
-			IPAddress: hypervisor.IPAddress,
+			Public:        hypervisor.Public,
+			Unschedulable: hypervisor.Unschedulable,
+			IPAddress:     hypervisor.IPAddress,
+			EtcdDataDir:   hypervisor.EtcdDataDir,
 			PortRange: infrav1alpha1.HypervisorPortRange{
 				Low:  hypervisor.portRangeLow,
 				High: hypervisor.portRangeHigh,
 			},
 		},
 		Status: infrav1alpha1.HypervisorStatus{
-			AllocatedPorts: hypervisor.allocatedPorts.Export(),
-			FreedPorts:     hypervisor.freedPorts,
-			Files:          hypervisor.Files.Export(),
+			AllocatedPorts:               hypervisor.allocatedPorts.Export(),
+			FreedPorts:                   hypervisor.freedPorts,
+			Files:                        hypervisor.Files.Export(),
+			PendingComponentTransactions: hypervisor.pendingTransactions.Export(),
+			PodRestarts:                  hypervisor.podRestarts.Export(),
+			PreflightChecks:              hypervisor.preflightChecks.Export(),
 		},
 	}
-	resHypervisor.Spec.LocalCRIEndpoint, resHypervisor.Spec.RemoteCRIEndpoint = hypervisor.Endpoint.Export()
+	resHypervisor.Spec.LocalCRIEndpoint, resHypervisor.Spec.RemoteCRIEndpoint, resHypervisor.Spec.SSHCRIEndpoint = hypervisor.Endpoint.Export()
 	return &resHypervisor
 }
 
@@ -864,6 +1212,58 @@ func (hypervisorMap HypervisorMap) PrivateList() HypervisorList {
 	return hypervisorList
 }
 
+// WithNames returns a hypervisor map restricted to the hypervisors
+// whose name is included in names. An empty names list returns the
+// map unmodified, so callers can use it unconditionally regardless of
+// whether a pool restriction applies.
+func (hypervisorMap HypervisorMap) WithNames(names []string) HypervisorMap {
+	if len(names) == 0 {
+		return hypervisorMap
+	}
+	nameSet := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		nameSet[name] = struct{}{}
+	}
+	res := HypervisorMap{}
+	for name, hypervisor := range hypervisorMap {
+		if _, found := nameSet[name]; found {
+			res[name] = hypervisor
+		}
+	}
+	return res
+}
+
+// WithPassingPreflight returns a hypervisor map restricted to the
+// hypervisors that have not recorded any failing preflight check.
+// Hypervisors not yet probed (no preflight checks recorded) are kept,
+// since excluding them would make a freshly registered hypervisor
+// unschedulable forever
+func (hypervisorMap HypervisorMap) WithPassingPreflight() HypervisorMap {
+	res := HypervisorMap{}
+	for name, hypervisor := range hypervisorMap {
+		if len(hypervisor.FailedPreflightChecks()) > 0 {
+			continue
+		}
+		res[name] = hypervisor
+	}
+	return res
+}
+
+// WithoutCordoned returns a hypervisor map restricted to the
+// hypervisors not marked Unschedulable, so a cordoned hypervisor
+// keeps serving its already-scheduled components but never receives
+// new ones
+func (hypervisorMap HypervisorMap) WithoutCordoned() HypervisorMap {
+	res := HypervisorMap{}
+	for name, hypervisor := range hypervisorMap {
+		if hypervisor.Unschedulable {
+			continue
+		}
+		res[name] = hypervisor
+	}
+	return res
+}
+
 // IPAddresses returns the list of IP addresses
 func (hypervisorList HypervisorList) IPAddresses() []string {
 	ipAddresses := []string{}
@@ -0,0 +1,111 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+
+	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
+)
+
+// criLimiter rate limits and circuit breaks the CRI calls issued
+// against a single hypervisor, so that a slow or unresponsive
+// hypervisor cannot be hammered with retries, and callers fail fast
+// once it has proven unresponsive for consecutiveFailureLimit calls
+// in a row
+type criLimiter struct {
+	limiter                 *rate.Limiter
+	consecutiveFailureLimit int
+	mu                      sync.Mutex
+	consecutiveFailures     int
+}
+
+// newCRILimiter returns a criLimiter honoring limits, or nil when
+// limits is nil or configures no limiting at all, in which case the
+// caller should skip installing an interceptor entirely
+func newCRILimiter(limits *infrav1alpha1.HypervisorCRILimits) *criLimiter {
+	if limits == nil {
+		return nil
+	}
+	res := &criLimiter{
+		consecutiveFailureLimit: limits.CircuitBreakerThreshold,
+	}
+	if limits.QPS > 0 {
+		burst := limits.Burst
+		if burst <= 0 {
+			burst = int(math.Ceil(limits.QPS))
+		}
+		res.limiter = rate.NewLimiter(rate.Limit(limits.QPS), burst)
+	}
+	if res.limiter == nil && res.consecutiveFailureLimit <= 0 {
+		return nil
+	}
+	return res
+}
+
+// circuitBroken returns whether the circuit breaker has tripped,
+// rejecting calls without even attempting them
+func (criLimiter *criLimiter) circuitBroken() bool {
+	if criLimiter.consecutiveFailureLimit <= 0 {
+		return false
+	}
+	criLimiter.mu.Lock()
+	defer criLimiter.mu.Unlock()
+	return criLimiter.consecutiveFailures >= criLimiter.consecutiveFailureLimit
+}
+
+// recordResult updates the circuit breaker bookkeeping with the
+// outcome of a CRI call, tripping the breaker once
+// consecutiveFailureLimit failures have been observed in a row, and
+// resetting it as soon as a call succeeds
+func (criLimiter *criLimiter) recordResult(err error) {
+	if criLimiter.consecutiveFailureLimit <= 0 {
+		return
+	}
+	criLimiter.mu.Lock()
+	defer criLimiter.mu.Unlock()
+	if err != nil {
+		criLimiter.consecutiveFailures++
+		return
+	}
+	criLimiter.consecutiveFailures = 0
+}
+
+// unaryClientInterceptor returns a grpc.UnaryClientInterceptor that
+// enforces this criLimiter's rate limit and circuit breaker around
+// every unary CRI call made over the connection it is installed on
+func (criLimiter *criLimiter) unaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, conn *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if criLimiter.circuitBroken() {
+			return errors.Errorf("circuit breaker is open for %q, not attempting the call", method)
+		}
+		if criLimiter.limiter != nil {
+			if err := criLimiter.limiter.Wait(ctx); err != nil {
+				return err
+			}
+		}
+		err := invoker(ctx, method, req, reply, conn, opts...)
+		criLimiter.recordResult(err)
+		return err
+	}
+}
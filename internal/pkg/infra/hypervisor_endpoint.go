@@ -17,13 +17,17 @@
 package infra
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/oneinfra/oneinfra/internal/pkg/certificates"
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 	"google.golang.org/grpc"
 	grpccredentials "google.golang.org/grpc/credentials"
 
@@ -33,11 +37,12 @@ import (
 
 type hypervisorEndpoint interface {
 	Connection() (*grpc.ClientConn, error)
-	Export() (*infrav1alpha1.LocalHypervisorCRIEndpoint, *infrav1alpha1.RemoteHypervisorCRIEndpoint)
+	Export() (*infrav1alpha1.LocalHypervisorCRIEndpoint, *infrav1alpha1.RemoteHypervisorCRIEndpoint, *infrav1alpha1.SSHHypervisorCRIEndpoint)
 }
 
 type localHypervisorEndpoint struct {
 	CRIEndpoint string
+	CRILimits   *infrav1alpha1.HypervisorCRILimits
 	clientConn  *grpc.ClientConn
 }
 
@@ -45,6 +50,8 @@ type remoteHypervisorEndpoint struct {
 	CRIEndpoint       string
 	CACertificate     *certificates.Certificate
 	ClientCertificate *certificates.Certificate
+	CRILimits         *infrav1alpha1.HypervisorCRILimits
+	Attestation       *infrav1alpha1.HypervisorAttestation
 	clientConn        *grpc.ClientConn
 }
 
@@ -52,11 +59,17 @@ func (endpoint *localHypervisorEndpoint) Connection() (*grpc.ClientConn, error)
 	if endpoint.clientConn != nil {
 		return endpoint.clientConn, nil
 	}
-	clientConn, err := grpc.Dial(
-		fmt.Sprintf("passthrough:///unix://%s", endpoint.CRIEndpoint),
+	dialOptions := []grpc.DialOption{
 		grpc.WithInsecure(),
 		grpc.WithBlock(),
-		grpc.WithTimeout(5*time.Second),
+		grpc.WithTimeout(5 * time.Second),
+	}
+	if criLimiter := newCRILimiter(endpoint.CRILimits); criLimiter != nil {
+		dialOptions = append(dialOptions, grpc.WithUnaryInterceptor(criLimiter.unaryClientInterceptor()))
+	}
+	clientConn, err := grpc.Dial(
+		fmt.Sprintf("passthrough:///unix://%s", endpoint.CRIEndpoint),
+		dialOptions...,
 	)
 	if err != nil {
 		return nil, err
@@ -65,16 +78,19 @@ func (endpoint *localHypervisorEndpoint) Connection() (*grpc.ClientConn, error)
 	return clientConn, nil
 }
 
-func (endpoint *localHypervisorEndpoint) Export() (*infrav1alpha1.LocalHypervisorCRIEndpoint, *infrav1alpha1.RemoteHypervisorCRIEndpoint) {
+func (endpoint *localHypervisorEndpoint) Export() (*infrav1alpha1.LocalHypervisorCRIEndpoint, *infrav1alpha1.RemoteHypervisorCRIEndpoint, *infrav1alpha1.SSHHypervisorCRIEndpoint) {
 	return &infrav1alpha1.LocalHypervisorCRIEndpoint{
 		CRIEndpoint: endpoint.CRIEndpoint,
-	}, nil
+	}, nil, nil
 }
 
 func (endpoint *remoteHypervisorEndpoint) Connection() (*grpc.ClientConn, error) {
 	if endpoint.clientConn != nil {
 		return endpoint.clientConn, nil
 	}
+	if err := verifyHypervisorAttestation(endpoint.ClientCertificate, endpoint.Attestation); err != nil {
+		return nil, errors.Wrap(err, "hypervisor identity attestation failed")
+	}
 	clientCert, err := tls.X509KeyPair(
 		[]byte(endpoint.ClientCertificate.Certificate),
 		[]byte(endpoint.ClientCertificate.PrivateKey),
@@ -92,11 +108,17 @@ func (endpoint *remoteHypervisorEndpoint) Connection() (*grpc.ClientConn, error)
 			RootCAs:      certPool,
 		}),
 	)
-	clientConn, err := grpc.Dial(
-		endpoint.CRIEndpoint,
+	dialOptions := []grpc.DialOption{
 		transportCredentials,
 		grpc.WithBlock(),
-		grpc.WithTimeout(5*time.Second),
+		grpc.WithTimeout(5 * time.Second),
+	}
+	if criLimiter := newCRILimiter(endpoint.CRILimits); criLimiter != nil {
+		dialOptions = append(dialOptions, grpc.WithUnaryInterceptor(criLimiter.unaryClientInterceptor()))
+	}
+	clientConn, err := grpc.Dial(
+		endpoint.CRIEndpoint,
+		dialOptions...,
 	)
 	if err != nil {
 		return nil, err
@@ -105,22 +127,164 @@ func (endpoint *remoteHypervisorEndpoint) Connection() (*grpc.ClientConn, error)
 	return clientConn, nil
 }
 
-func (endpoint *remoteHypervisorEndpoint) Export() (*infrav1alpha1.LocalHypervisorCRIEndpoint, *infrav1alpha1.RemoteHypervisorCRIEndpoint) {
+// verifyHypervisorAttestation checks that clientCertificate chains up
+// to one of attestation's trusted CA certificates, as an additional
+// identity check on top of the mTLS handshake itself, which only
+// proves possession of the certificate, not that it was issued by a
+// CA the manager has chosen to trust for this particular hypervisor.
+// A nil attestation, or one with no trusted CA certificates
+// configured, skips this check entirely
+func verifyHypervisorAttestation(clientCertificate *certificates.Certificate, attestation *infrav1alpha1.HypervisorAttestation) error {
+	if attestation == nil || len(attestation.TrustedCACertificates) == 0 {
+		return nil
+	}
+	block, _ := pem.Decode([]byte(clientCertificate.Certificate))
+	if block == nil {
+		return errors.New("could not decode client certificate")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return errors.Wrap(err, "could not parse client certificate")
+	}
+	trustedCAPool := x509.NewCertPool()
+	for _, trustedCACertificate := range attestation.TrustedCACertificates {
+		if ok := trustedCAPool.AppendCertsFromPEM([]byte(trustedCACertificate)); !ok {
+			return errors.New("could not add trusted CA certificate to the pool of known certificates")
+		}
+	}
+	_, err = leaf.Verify(x509.VerifyOptions{
+		Roots:     trustedCAPool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+func (endpoint *remoteHypervisorEndpoint) Export() (*infrav1alpha1.LocalHypervisorCRIEndpoint, *infrav1alpha1.RemoteHypervisorCRIEndpoint, *infrav1alpha1.SSHHypervisorCRIEndpoint) {
 	return nil, &infrav1alpha1.RemoteHypervisorCRIEndpoint{
 		CRIEndpoint:       endpoint.CRIEndpoint,
 		CACertificate:     endpoint.CACertificate.Certificate,
 		ClientCertificate: endpoint.ClientCertificate.Export(),
+	}, nil
+}
+
+type sshHypervisorEndpoint struct {
+	Address         string
+	User            string
+	PrivateKey      string
+	HostPublicKey   string
+	RemoteCRISocket string
+	CRILimits       *infrav1alpha1.HypervisorCRILimits
+	clientConn      *grpc.ClientConn
+	sshClient       *ssh.Client
+}
+
+func (endpoint *sshHypervisorEndpoint) Connection() (*grpc.ClientConn, error) {
+	if endpoint.clientConn != nil {
+		return endpoint.clientConn, nil
+	}
+	signer, err := ssh.ParsePrivateKey([]byte(endpoint.PrivateKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse SSH private key")
+	}
+	hostKeyCallback, err := sshHostKeyCallback(endpoint.HostPublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse SSH host public key")
 	}
+	sshClient, err := ssh.Dial("tcp", endpoint.Address, &ssh.ClientConfig{
+		User:            endpoint.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not establish SSH connection to hypervisor")
+	}
+	dialOptions := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.WithTimeout(5 * time.Second),
+		grpc.WithContextDialer(func(_ context.Context, _ string) (net.Conn, error) {
+			return sshClient.Dial("unix", endpoint.RemoteCRISocket)
+		}),
+	}
+	if criLimiter := newCRILimiter(endpoint.CRILimits); criLimiter != nil {
+		dialOptions = append(dialOptions, grpc.WithUnaryInterceptor(criLimiter.unaryClientInterceptor()))
+	}
+	clientConn, err := grpc.Dial("passthrough:///unix-over-ssh", dialOptions...)
+	if err != nil {
+		sshClient.Close()
+		return nil, err
+	}
+	endpoint.sshClient = sshClient
+	endpoint.clientConn = clientConn
+	return clientConn, nil
+}
+
+func (endpoint *sshHypervisorEndpoint) Export() (*infrav1alpha1.LocalHypervisorCRIEndpoint, *infrav1alpha1.RemoteHypervisorCRIEndpoint, *infrav1alpha1.SSHHypervisorCRIEndpoint) {
+	return nil, nil, &infrav1alpha1.SSHHypervisorCRIEndpoint{
+		Address:         endpoint.Address,
+		User:            endpoint.User,
+		PrivateKey:      endpoint.PrivateKey,
+		HostPublicKey:   endpoint.HostPublicKey,
+		RemoteCRISocket: endpoint.RemoteCRISocket,
+	}
+}
+
+// sshHostKeyCallback builds a host key callback that accepts only the
+// authorized_keys encoded hostPublicKey, so a hypervisor presenting
+// any other host key is refused a connection. An empty hostPublicKey
+// accepts any host key, which is only acceptable for development and
+// testing purposes
+func sshHostKeyCallback(hostPublicKey string) (ssh.HostKeyCallback, error) {
+	if hostPublicKey == "" {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	trustedKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostPublicKey))
+	if err != nil {
+		return nil, err
+	}
+	return ssh.FixedHostKey(trustedKey), nil
+}
+
+// NewRemoteHypervisorCRIEndpoint mints a fresh client certificate
+// signed by ca, and returns a RemoteHypervisorCRIEndpoint pointing at
+// criEndpoint ready to be stored on a Hypervisor's RemoteCRIEndpoint,
+// so that provisioning a new remote hypervisor does not require
+// hand-rolling a compliant client certificate out of band
+func NewRemoteHypervisorCRIEndpoint(criEndpoint string, ca *certificates.Certificate) (*infrav1alpha1.RemoteHypervisorCRIEndpoint, error) {
+	clientCertificate, clientPrivateKey, err := ca.CreateCertificate("oneinfra-hypervisor-client", []string{"oneinfra"}, []string{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not mint hypervisor client certificate")
+	}
+	return &infrav1alpha1.RemoteHypervisorCRIEndpoint{
+		CRIEndpoint:   criEndpoint,
+		CACertificate: ca.Certificate,
+		ClientCertificate: (&certificates.Certificate{
+			Certificate: clientCertificate,
+			PrivateKey:  clientPrivateKey,
+		}).Export(),
+	}, nil
 }
 
 func setHypervisorEndpointFromv1alpha1(hypervisor *infrav1alpha1.Hypervisor, connectionPool *HypervisorConnectionPool, resHypervisor *Hypervisor) error {
-	if hypervisor.Spec.LocalCRIEndpoint != nil && hypervisor.Spec.RemoteCRIEndpoint != nil {
-		return errors.Errorf("hypervisor %q has both a local and a remote CRI endpoint, can only have one", hypervisor.Name)
+	endpointCount := 0
+	for _, set := range []bool{
+		hypervisor.Spec.LocalCRIEndpoint != nil,
+		hypervisor.Spec.RemoteCRIEndpoint != nil,
+		hypervisor.Spec.SSHCRIEndpoint != nil,
+	} {
+		if set {
+			endpointCount++
+		}
+	}
+	if endpointCount > 1 {
+		return errors.Errorf("hypervisor %q has more than one CRI endpoint configured, can only have one", hypervisor.Name)
 	} else if hypervisor.Spec.LocalCRIEndpoint != nil {
 		resHypervisor.Endpoint = connectionPool.connection(
 			hypervisor.Name,
 			&localHypervisorEndpoint{
 				CRIEndpoint: hypervisor.Spec.LocalCRIEndpoint.CRIEndpoint,
+				CRILimits:   hypervisor.Spec.CRILimits,
 			},
 		)
 	} else if hypervisor.Spec.RemoteCRIEndpoint != nil {
@@ -133,10 +297,24 @@ func setHypervisorEndpointFromv1alpha1(hypervisor *infrav1alpha1.Hypervisor, con
 					PrivateKey:  "",
 				}),
 				ClientCertificate: certificates.NewCertificateFromv1alpha1(hypervisor.Spec.RemoteCRIEndpoint.ClientCertificate),
+				CRILimits:         hypervisor.Spec.CRILimits,
+				Attestation:       hypervisor.Spec.Attestation,
+			},
+		)
+	} else if hypervisor.Spec.SSHCRIEndpoint != nil {
+		resHypervisor.Endpoint = connectionPool.connection(
+			hypervisor.Name,
+			&sshHypervisorEndpoint{
+				Address:         hypervisor.Spec.SSHCRIEndpoint.Address,
+				User:            hypervisor.Spec.SSHCRIEndpoint.User,
+				PrivateKey:      hypervisor.Spec.SSHCRIEndpoint.PrivateKey,
+				HostPublicKey:   hypervisor.Spec.SSHCRIEndpoint.HostPublicKey,
+				RemoteCRISocket: hypervisor.Spec.SSHCRIEndpoint.RemoteCRISocket,
+				CRILimits:       hypervisor.Spec.CRILimits,
 			},
 		)
 	} else {
-		return errors.Errorf("hypervisor %q is missing a local or a remote CRI endpoint", hypervisor.Name)
+		return errors.Errorf("hypervisor %q is missing a local, remote or SSH CRI endpoint", hypervisor.Name)
 	}
 	return nil
 }
@@ -0,0 +1,79 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
+)
+
+// HypervisorPool represents a named group of hypervisors dedicated to
+// one or more tenants
+type HypervisorPool struct {
+	Name               string
+	HypervisorNames    []string
+	SchedulingStrategy SchedulingStrategy
+	PlacementWebhook   *PlacementWebhook
+}
+
+// HypervisorPoolMap represents a map of hypervisor pools
+type HypervisorPoolMap map[string]*HypervisorPool
+
+// NewHypervisorPoolFromv1alpha1 returns an hypervisor pool based on a
+// versioned hypervisor pool
+func NewHypervisorPoolFromv1alpha1(hypervisorPool *infrav1alpha1.HypervisorPool) (*HypervisorPool, error) {
+	return &HypervisorPool{
+		Name:               hypervisorPool.Name,
+		HypervisorNames:    hypervisorPool.Spec.HypervisorNames,
+		SchedulingStrategy: SchedulingStrategy(hypervisorPool.Spec.SchedulingStrategy),
+		PlacementWebhook:   newPlacementWebhookFromv1alpha1(hypervisorPool.Spec.PlacementWebhook),
+	}, nil
+}
+
+// Export exports the hypervisor pool to a versioned hypervisor pool
+func (hypervisorPool *HypervisorPool) Export() *infrav1alpha1.HypervisorPool {
+	var placementWebhook *infrav1alpha1.PlacementWebhook
+	if hypervisorPool.PlacementWebhook != nil {
+		placementWebhook = &infrav1alpha1.PlacementWebhook{
+			URL:            hypervisorPool.PlacementWebhook.URL,
+			TimeoutSeconds: hypervisorPool.PlacementWebhook.TimeoutSeconds,
+		}
+	}
+	return &infrav1alpha1.HypervisorPool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: hypervisorPool.Name,
+		},
+		Spec: infrav1alpha1.HypervisorPoolSpec{
+			HypervisorNames:    hypervisorPool.HypervisorNames,
+			SchedulingStrategy: string(hypervisorPool.SchedulingStrategy),
+			PlacementWebhook:   placementWebhook,
+		},
+	}
+}
+
+// newPlacementWebhookFromv1alpha1 returns an internal placement
+// webhook based on a versioned one, or nil when unset
+func newPlacementWebhookFromv1alpha1(placementWebhook *infrav1alpha1.PlacementWebhook) *PlacementWebhook {
+	if placementWebhook == nil {
+		return nil
+	}
+	return &PlacementWebhook{
+		URL:            placementWebhook.URL,
+		TimeoutSeconds: placementWebhook.TimeoutSeconds,
+	}
+}
@@ -0,0 +1,77 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+
+	"github.com/pkg/errors"
+
+	podapi "github.com/oneinfra/oneinfra/internal/pkg/infra/pod"
+)
+
+// imageReferenceRegexp matches a well-formed container image
+// reference: optional registry host and repository path segments,
+// followed by an optional :tag and/or @digest. It intentionally
+// rejects anything containing shell metacharacters or whitespace,
+// since image references end up as pod container arguments
+var imageReferenceRegexp = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[._-][a-zA-Z0-9]+)*(?:/[a-zA-Z0-9]+(?:[._-][a-zA-Z0-9]+)*)*(?::[a-zA-Z0-9][a-zA-Z0-9._-]*)?(?:@[a-zA-Z0-9]+:[a-fA-F0-9]+)?$`)
+
+// validateImageReference returns an error when image does not look
+// like a well-formed container image reference
+func validateImageReference(image string) error {
+	if !imageReferenceRegexp.MatchString(image) {
+		return errors.Errorf("%q does not look like a valid image reference", image)
+	}
+	return nil
+}
+
+// cosignVerifyContainers returns the containers that write each of
+// publicKeys to its own temporary file using the same
+// write-base64-file.sh tooling script UploadFiles relies on (so key
+// contents never need to be shell escaped), followed by one cosign
+// verify invocation per key, run directly (no shell) with image
+// passed as a plain argument so shell metacharacters in it cannot
+// be interpreted. image is validated by the caller before this is
+// invoked
+func cosignVerifyContainers(image string, publicKeys []string) []podapi.Container {
+	containers := make([]podapi.Container, 0, len(publicKeys)*2)
+	for i, publicKey := range publicKeys {
+		keyPath := fmt.Sprintf("/tmp/cosign-key-%d.pem", i)
+		containers = append(
+			containers,
+			podapi.Container{
+				Name:    fmt.Sprintf("cosign-write-key-%d", i),
+				Image:   ToolingImage,
+				Command: []string{"write-base64-file.sh"},
+				Args: []string{
+					base64.StdEncoding.EncodeToString([]byte(publicKey)),
+					keyPath,
+				},
+			},
+			podapi.Container{
+				Name:    fmt.Sprintf("cosign-verify-%d", i),
+				Image:   ToolingImage,
+				Command: []string{"cosign"},
+				Args:    []string{"verify", "--key", keyPath, image},
+			},
+		)
+	}
+	return containers
+}
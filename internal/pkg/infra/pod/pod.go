@@ -43,10 +43,12 @@ const (
 
 // Pod represents a pod
 type Pod struct {
-	Name       string
-	Containers []Container
-	Ports      map[int]int
-	Privileges Privileges
+	Name        string
+	Containers  []Container
+	Ports       map[int]int
+	Privileges  Privileges
+	Labels      map[string]string
+	Annotations map[string]string
 }
 
 // Container represents a container
@@ -58,6 +60,7 @@ type Container struct {
 	Env         map[string]string
 	Mounts      map[string]string
 	Privileges  Privileges
+	Labels      map[string]string
 	Annotations map[string]string
 }
 
@@ -71,6 +74,84 @@ func NewPod(name string, containers []Container, ports map[int]int, privileges P
 	}
 }
 
+// ApplyEnv merges env into every container of this pod, without
+// overriding environment variables a container has already set
+func (pod *Pod) ApplyEnv(env map[string]string) {
+	for i := range pod.Containers {
+		if len(pod.Containers[i].Env) == 0 {
+			pod.Containers[i].Env = map[string]string{}
+		}
+		for key, value := range env {
+			if _, exists := pod.Containers[i].Env[key]; !exists {
+				pod.Containers[i].Env[key] = value
+			}
+		}
+	}
+}
+
+// ApplyMounts merges mounts into every container of this pod,
+// without overriding a mount a container has already set
+func (pod *Pod) ApplyMounts(mounts map[string]string) {
+	for i := range pod.Containers {
+		if len(pod.Containers[i].Mounts) == 0 {
+			pod.Containers[i].Mounts = map[string]string{}
+		}
+		for hostPath, containerPath := range mounts {
+			if _, exists := pod.Containers[i].Mounts[hostPath]; !exists {
+				pod.Containers[i].Mounts[hostPath] = containerPath
+			}
+		}
+	}
+}
+
+// ApplyLabels merges labels into this pod's own labels and into
+// every container's labels, without overriding labels a container
+// has already set
+func (pod *Pod) ApplyLabels(labels map[string]string) {
+	if len(pod.Labels) == 0 {
+		pod.Labels = map[string]string{}
+	}
+	for key, value := range labels {
+		if _, exists := pod.Labels[key]; !exists {
+			pod.Labels[key] = value
+		}
+	}
+	for i := range pod.Containers {
+		if len(pod.Containers[i].Labels) == 0 {
+			pod.Containers[i].Labels = map[string]string{}
+		}
+		for key, value := range labels {
+			if _, exists := pod.Containers[i].Labels[key]; !exists {
+				pod.Containers[i].Labels[key] = value
+			}
+		}
+	}
+}
+
+// ApplyAnnotations merges annotations into this pod's own
+// annotations and into every container's annotations, without
+// overriding annotations a container has already set
+func (pod *Pod) ApplyAnnotations(annotations map[string]string) {
+	if len(pod.Annotations) == 0 {
+		pod.Annotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		if _, exists := pod.Annotations[key]; !exists {
+			pod.Annotations[key] = value
+		}
+	}
+	for i := range pod.Containers {
+		if len(pod.Containers[i].Annotations) == 0 {
+			pod.Containers[i].Annotations = map[string]string{}
+		}
+		for key, value := range annotations {
+			if _, exists := pod.Containers[i].Annotations[key]; !exists {
+				pod.Containers[i].Annotations[key] = value
+			}
+		}
+	}
+}
+
 // SHA1Sum returns the SHA-1 of the textual YAML representation of
 // this pod
 func (pod *Pod) SHA1Sum() (string, error) {
@@ -80,3 +161,35 @@ func (pod *Pod) SHA1Sum() (string, error) {
 	}
 	return fmt.Sprintf("%x", sha1.Sum(podManifest)), nil
 }
+
+// SandboxSHA1Sum returns the SHA-1 of the textual YAML
+// representation of this pod's sandbox-defining attributes (name,
+// ports and privileges), deliberately excluding its containers. Two
+// pod specs that only differ in their containers hash to the same
+// value here, so a pod sandbox already running on an hypervisor can
+// be reused -- along with the IPs and host ports already allocated
+// to it -- across a config-only change, instead of being torn down
+// and recreated from scratch
+func (pod *Pod) SandboxSHA1Sum() (string, error) {
+	sandbox := Pod{
+		Name:       pod.Name,
+		Ports:      pod.Ports,
+		Privileges: pod.Privileges,
+	}
+	sandboxManifest, err := yaml.Marshal(sandbox)
+	if err != nil {
+		return "", errors.Errorf("cannot marshal pod %q sandbox: %v", pod.Name, err)
+	}
+	return fmt.Sprintf("%x", sha1.Sum(sandboxManifest)), nil
+}
+
+// SHA1Sum returns the SHA-1 of the textual YAML representation of
+// this container, used to detect whether a running container has
+// drifted from its desired spec and needs to be recreated
+func (container *Container) SHA1Sum() (string, error) {
+	containerManifest, err := yaml.Marshal(container)
+	if err != nil {
+		return "", errors.Errorf("cannot marshal container %q: %v", container.Name, err)
+	}
+	return fmt.Sprintf("%x", sha1.Sum(containerManifest)), nil
+}
@@ -0,0 +1,155 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
+)
+
+const (
+	// MaxPodRestartAttempts is the number of consecutive times a pod
+	// found with containers not all running will be automatically
+	// recreated before the auto-repair circuit breaker trips and
+	// further restarts are suspended
+	MaxPodRestartAttempts = 5
+
+	podRestartBaseBackoff = 30 * time.Second
+	podRestartMaxBackoff  = 10 * time.Minute
+)
+
+// PodRestart tracks the auto-repair restart attempts performed for a
+// single pod on an hypervisor, after it was found with containers not
+// all running
+type PodRestart struct {
+	ClusterNamespace string
+	Cluster          string
+	Component        string
+	Pod              string
+	Attempts         int
+	LastRestartTime  metav1.Time
+}
+
+// PodRestartList represents a list of pod restarts
+type PodRestartList []PodRestart
+
+// NewPodRestartListFromv1alpha1 creates a pod restart list from a versioned one
+func NewPodRestartListFromv1alpha1(podRestartList []infrav1alpha1.PodRestart) PodRestartList {
+	res := PodRestartList{}
+	for _, podRestart := range podRestartList {
+		lastRestartTime := metav1.Time{}
+		if podRestart.LastRestartTime != nil {
+			lastRestartTime = *podRestart.LastRestartTime
+		}
+		res = append(res, PodRestart{
+			ClusterNamespace: podRestart.ClusterNamespace,
+			Cluster:          podRestart.Cluster,
+			Component:        podRestart.Component,
+			Pod:              podRestart.Pod,
+			Attempts:         podRestart.Attempts,
+			LastRestartTime:  lastRestartTime,
+		})
+	}
+	return res
+}
+
+// Export exports the pod restart list to a versioned object
+func (podRestartList PodRestartList) Export() []infrav1alpha1.PodRestart {
+	res := []infrav1alpha1.PodRestart{}
+	for _, podRestart := range podRestartList {
+		lastRestartTime := podRestart.LastRestartTime
+		res = append(res, infrav1alpha1.PodRestart{
+			ClusterNamespace: podRestart.ClusterNamespace,
+			Cluster:          podRestart.Cluster,
+			Component:        podRestart.Component,
+			Pod:              podRestart.Pod,
+			Attempts:         podRestart.Attempts,
+			LastRestartTime:  &lastRestartTime,
+		})
+	}
+	return res
+}
+
+func (hypervisor *Hypervisor) podRestart(clusterNamespace, clusterName, componentName, podName string) *PodRestart {
+	for i := range hypervisor.podRestarts {
+		podRestart := &hypervisor.podRestarts[i]
+		if podRestart.ClusterNamespace == clusterNamespace && podRestart.Cluster == clusterName && podRestart.Component == componentName && podRestart.Pod == podName {
+			return podRestart
+		}
+	}
+	return nil
+}
+
+// allowPodRestart returns whether a pod found with containers not all
+// running is allowed to be recreated now, honoring the exponential
+// backoff between restart attempts and the max-restarts circuit
+// breaker, and records the attempt when it is allowed
+func (hypervisor *Hypervisor) allowPodRestart(clusterNamespace, clusterName, componentName, podName string) bool {
+	podRestart := hypervisor.podRestart(clusterNamespace, clusterName, componentName, podName)
+	if podRestart == nil {
+		hypervisor.podRestarts = append(hypervisor.podRestarts, PodRestart{
+			ClusterNamespace: clusterNamespace,
+			Cluster:          clusterName,
+			Component:        componentName,
+			Pod:              podName,
+			Attempts:         1,
+			LastRestartTime:  metav1.Now(),
+		})
+		return true
+	}
+	if podRestart.Attempts >= MaxPodRestartAttempts {
+		return false
+	}
+	backoff := podRestartBaseBackoff << uint(podRestart.Attempts-1)
+	if backoff > podRestartMaxBackoff {
+		backoff = podRestartMaxBackoff
+	}
+	if time.Since(podRestart.LastRestartTime.Time) < backoff {
+		return false
+	}
+	podRestart.Attempts++
+	podRestart.LastRestartTime = metav1.Now()
+	return true
+}
+
+// clearPodRestart resets the auto-repair restart accounting for a pod
+// once it has been observed with all its containers running
+func (hypervisor *Hypervisor) clearPodRestart(clusterNamespace, clusterName, componentName, podName string) {
+	newPodRestarts := PodRestartList{}
+	for _, podRestart := range hypervisor.podRestarts {
+		if podRestart.ClusterNamespace == clusterNamespace && podRestart.Cluster == clusterName && podRestart.Component == componentName && podRestart.Pod == podName {
+			continue
+		}
+		newPodRestarts = append(newPodRestarts, podRestart)
+	}
+	hypervisor.podRestarts = newPodRestarts
+}
+
+// IsComponentRepairCircuitBroken returns whether any of componentName's
+// pods on this hypervisor have exceeded the max-restarts circuit
+// breaker, and are no longer being automatically recreated
+func (hypervisor *Hypervisor) IsComponentRepairCircuitBroken(clusterNamespace, clusterName, componentName string) bool {
+	for _, podRestart := range hypervisor.podRestarts {
+		if podRestart.ClusterNamespace == clusterNamespace && podRestart.Cluster == clusterName && podRestart.Component == componentName && podRestart.Attempts >= MaxPodRestartAttempts {
+			return true
+		}
+	}
+	return false
+}
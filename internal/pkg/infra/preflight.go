@@ -0,0 +1,170 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	infrav1alpha1 "github.com/oneinfra/oneinfra/apis/infra/v1alpha1"
+	podapi "github.com/oneinfra/oneinfra/internal/pkg/infra/pod"
+)
+
+// PreflightCheck represents a single host preflight check that can
+// be run against an hypervisor through a one-shot tooling container.
+// Script is expected to exit zero when the check passes
+type PreflightCheck struct {
+	Name            string
+	Script          string
+	RemediationHint string
+	HostMounts      map[string]string
+}
+
+// PreflightChecks is the fixed set of host checks run against an
+// hypervisor before it is trusted with scheduled workloads. Each
+// check is intentionally a single pass/fail test: the hypervisor is
+// only ever reachable through its CRI endpoint, which exposes no way
+// to retrieve arbitrary command output back to the manager, so a
+// static, per-check remediation hint is recorded instead of the
+// live value that failed the check
+var PreflightChecks = []PreflightCheck{
+	{
+		Name:            "kernel-version",
+		Script:          `[ "$(printf '%s\n4.19\n' "$(uname -r | cut -d- -f1)" | sort -V | head -n1)" = "4.19" ]`,
+		RemediationHint: "upgrade the host kernel to 4.19 or newer",
+		HostMounts:      map[string]string{"/proc": "/proc"},
+	},
+	{
+		Name:            "wireguard-module",
+		Script:          `grep -qx wireguard /proc/modules || test -d /sys/module/wireguard`,
+		RemediationHint: "load the wireguard kernel module (modprobe wireguard) or install a kernel with it built in",
+		HostMounts:      map[string]string{"/proc": "/proc", "/sys": "/sys"},
+	},
+	{
+		Name:            "conntrack-max",
+		Script:          `[ "$(cat /proc/sys/net/netfilter/nf_conntrack_max)" -ge 131072 ]`,
+		RemediationHint: "raise net.netfilter.nf_conntrack_max to at least 131072",
+		HostMounts:      map[string]string{"/proc": "/proc"},
+	},
+	{
+		Name:            "cgroup-v2",
+		Script:          `grep -q cgroup2 /proc/filesystems && [ -f /sys/fs/cgroup/cgroup.controllers ]`,
+		RemediationHint: "enable the unified cgroup v2 hierarchy (add systemd.unified_cgroup_hierarchy=1 to the kernel command line)",
+		HostMounts:      map[string]string{"/proc": "/proc", "/sys": "/sys"},
+	},
+	{
+		Name:            "open-file-limit",
+		Script:          `[ "$(ulimit -Hn)" -ge 1048576 ]`,
+		RemediationHint: "raise the host's hard open file limit to at least 1048576 (fs.nr_open / limits.conf)",
+	},
+}
+
+// PreflightCheckResultList represents a list of preflight check
+// results
+type PreflightCheckResultList []PreflightCheckResult
+
+// NewPreflightCheckResultListFromv1alpha1 creates a preflight check
+// result list from a versioned one
+func NewPreflightCheckResultListFromv1alpha1(preflightChecks []infrav1alpha1.PreflightCheckResult) PreflightCheckResultList {
+	res := PreflightCheckResultList{}
+	for _, preflightCheck := range preflightChecks {
+		res = append(res, PreflightCheckResult{
+			Name:            preflightCheck.Name,
+			Passed:          preflightCheck.Passed,
+			RemediationHint: preflightCheck.RemediationHint,
+		})
+	}
+	return res
+}
+
+// Export exports the preflight check result list to a versioned object
+func (preflightCheckResultList PreflightCheckResultList) Export() []infrav1alpha1.PreflightCheckResult {
+	res := []infrav1alpha1.PreflightCheckResult{}
+	for _, preflightCheck := range preflightCheckResultList {
+		res = append(res, infrav1alpha1.PreflightCheckResult{
+			Name:            preflightCheck.Name,
+			Passed:          preflightCheck.Passed,
+			RemediationHint: preflightCheck.RemediationHint,
+		})
+	}
+	return res
+}
+
+// PreflightCheckResult represents the outcome of a single preflight
+// check run against an hypervisor
+type PreflightCheckResult struct {
+	Name            string
+	Passed          bool
+	RemediationHint string
+}
+
+// FailedPreflightChecks returns the preflight checks recorded for
+// this hypervisor that did not pass
+func (hypervisor *Hypervisor) FailedPreflightChecks() PreflightCheckResultList {
+	res := PreflightCheckResultList{}
+	for _, preflightCheck := range hypervisor.preflightChecks {
+		if !preflightCheck.Passed {
+			res = append(res, preflightCheck)
+		}
+	}
+	return res
+}
+
+// RunPreflightChecks runs every configured PreflightCheck against
+// this hypervisor through one-shot tooling containers, records the
+// outcome on the hypervisor and returns it
+func (hypervisor *Hypervisor) RunPreflightChecks(clusterNamespace, clusterName, componentName string) (PreflightCheckResultList, error) {
+	if err := hypervisor.EnsureImage(ToolingImage); err != nil {
+		return nil, err
+	}
+	containers := make([]podapi.Container, 0, len(PreflightChecks))
+	for _, preflightCheck := range PreflightChecks {
+		containers = append(containers, podapi.Container{
+			Name:       preflightCheck.Name,
+			Image:      ToolingImage,
+			Command:    []string{"/bin/sh", "-c", preflightCheck.Script},
+			Mounts:     preflightCheck.HostMounts,
+			Privileges: podapi.PrivilegesPrivileged,
+		})
+	}
+	podSandboxID, err := hypervisor.EnsurePod(
+		clusterNamespace,
+		clusterName,
+		componentName,
+		podapi.NewPod("preflight", containers, map[int]int{}, podapi.PrivilegesPrivileged),
+	)
+	if err != nil {
+		return nil, err
+	}
+	exitCodes, err := hypervisor.waitForPodContainerExitCodes(podSandboxID)
+	if err != nil {
+		return nil, err
+	}
+	if err := hypervisor.DeletePodWithID(podSandboxID); err != nil {
+		return nil, err
+	}
+	results := PreflightCheckResultList{}
+	for _, preflightCheck := range PreflightChecks {
+		result := PreflightCheckResult{
+			Name:   preflightCheck.Name,
+			Passed: exitCodes[preflightCheck.Name] == 0,
+		}
+		if !result.Passed {
+			result.RemediationHint = preflightCheck.RemediationHint
+		}
+		results = append(results, result)
+	}
+	hypervisor.preflightChecks = results
+	return results, nil
+}
@@ -0,0 +1,210 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SchedulingStrategy picks which hypervisor in a HypervisorList a new
+// component should be placed on
+type SchedulingStrategy string
+
+const (
+	// SpreadSchedulingStrategy selects the least loaded hypervisor in
+	// the list, spreading components evenly across the fleet
+	SpreadSchedulingStrategy SchedulingStrategy = "Spread"
+
+	// BinPackSchedulingStrategy selects the most loaded hypervisor in
+	// the list that still has room, packing components tightly onto
+	// fewer hypervisors before spilling over to idle ones
+	BinPackSchedulingStrategy SchedulingStrategy = "BinPack"
+
+	// WebhookSchedulingStrategy defers the placement decision to an
+	// external HTTP service, configured on the hypervisor pool's
+	// PlacementWebhook
+	WebhookSchedulingStrategy SchedulingStrategy = "Webhook"
+
+	// defaultPlacementWebhookTimeout is used when a PlacementWebhook
+	// does not set TimeoutSeconds
+	defaultPlacementWebhookTimeout = 10 * time.Second
+)
+
+// PlacementWebhook points to an external HTTP service consulted by
+// the Webhook scheduling strategy to pick a hypervisor among a list
+// of candidates
+type PlacementWebhook struct {
+	URL            string
+	TimeoutSeconds int
+}
+
+// timeout returns how long the scheduler should wait for the webhook
+// to respond before falling back to the Spread strategy
+func (placementWebhook *PlacementWebhook) timeout() time.Duration {
+	if placementWebhook.TimeoutSeconds == 0 {
+		return defaultPlacementWebhookTimeout
+	}
+	return time.Duration(placementWebhook.TimeoutSeconds) * time.Second
+}
+
+// placementWebhookCandidate describes one candidate hypervisor sent
+// to the placement webhook
+type placementWebhookCandidate struct {
+	Name string `json:"name"`
+	Load int    `json:"load"`
+}
+
+// placementWebhookRequest is the payload POSTed to a PlacementWebhook
+type placementWebhookRequest struct {
+	Hypervisors []placementWebhookCandidate `json:"hypervisors"`
+}
+
+// placementWebhookResponse is the payload expected back from a
+// PlacementWebhook, naming the chosen hypervisor
+type placementWebhookResponse struct {
+	HypervisorName string `json:"hypervisorName"`
+}
+
+// Load returns the number of host ports currently allocated on this
+// hypervisor, the capacity signal the Spread and BinPack scheduling
+// strategies place against. Every component scheduled on a
+// hypervisor holds at least one allocated port, but a component may
+// hold several (a control plane component holds one per
+// subcomponent), and such a component adds one to Load per port, not
+// one in all
+func (hypervisor *Hypervisor) Load() int {
+	return len(hypervisor.allocatedPorts)
+}
+
+// HasRoom returns whether this hypervisor is allowed to receive one
+// more component, according to its Reservations.MaxComponents cap,
+// which is compared against Load, that is against the allocated host
+// ports rather than a count of components. A hypervisor with no cap
+// configured always has room
+func (hypervisor *Hypervisor) HasRoom() bool {
+	if hypervisor.maxComponents == 0 {
+		return true
+	}
+	return hypervisor.Load() < hypervisor.maxComponents
+}
+
+// Select picks a hypervisor from the current list according to
+// strategy. An empty or unrecognized strategy falls back to Sample,
+// preserving the previous random-assignment behavior. WebhookScheduling
+// Strategy requires placementWebhook to be set, and falls back to
+// leastLoaded when the webhook call fails, so a misbehaving external
+// service cannot block scheduling altogether
+func (hypervisorList HypervisorList) Select(strategy SchedulingStrategy, placementWebhook *PlacementWebhook) (*Hypervisor, error) {
+	switch strategy {
+	case SpreadSchedulingStrategy:
+		return hypervisorList.leastLoaded()
+	case BinPackSchedulingStrategy:
+		return hypervisorList.mostLoadedWithRoom()
+	case WebhookSchedulingStrategy:
+		if placementWebhook == nil {
+			return hypervisorList.leastLoaded()
+		}
+		if hypervisor, err := hypervisorList.viaWebhook(placementWebhook); err == nil {
+			return hypervisor, nil
+		}
+		return hypervisorList.leastLoaded()
+	default:
+		return hypervisorList.Sample()
+	}
+}
+
+// viaWebhook asks placementWebhook to pick a hypervisor among the
+// current list, POSTing every candidate's name and current load, and
+// expecting the chosen hypervisor's name back
+func (hypervisorList HypervisorList) viaWebhook(placementWebhook *PlacementWebhook) (*Hypervisor, error) {
+	if len(hypervisorList) == 0 {
+		return nil, errors.New("no hypervisors available")
+	}
+	request := placementWebhookRequest{}
+	for _, hypervisor := range hypervisorList {
+		request.Hypervisors = append(request.Hypervisors, placementWebhookCandidate{
+			Name: hypervisor.Name,
+			Load: hypervisor.Load(),
+		})
+	}
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal placement webhook request")
+	}
+	client := &http.Client{Timeout: placementWebhook.timeout()}
+	res, err := client.Post(placementWebhook.URL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not reach placement webhook")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("placement webhook returned status code %d", res.StatusCode)
+	}
+	var response placementWebhookResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, errors.Wrap(err, "could not decode placement webhook response")
+	}
+	for _, hypervisor := range hypervisorList {
+		if hypervisor.Name == response.HypervisorName {
+			return hypervisor, nil
+		}
+	}
+	return nil, errors.Errorf("placement webhook chose hypervisor %q, which is not among the candidates", response.HypervisorName)
+}
+
+// leastLoaded returns the hypervisor in the list with the fewest
+// components already scheduled on it
+func (hypervisorList HypervisorList) leastLoaded() (*Hypervisor, error) {
+	if len(hypervisorList) == 0 {
+		return nil, errors.New("no hypervisors available")
+	}
+	leastLoadedHypervisor := hypervisorList[0]
+	for _, hypervisor := range hypervisorList[1:] {
+		if hypervisor.Load() < leastLoadedHypervisor.Load() {
+			leastLoadedHypervisor = hypervisor
+		}
+	}
+	return leastLoadedHypervisor, nil
+}
+
+// mostLoadedWithRoom returns the hypervisor in the list with the most
+// components already scheduled on it that still has room for one
+// more, falling back to the least loaded hypervisor when every
+// hypervisor in the list is at capacity
+func (hypervisorList HypervisorList) mostLoadedWithRoom() (*Hypervisor, error) {
+	if len(hypervisorList) == 0 {
+		return nil, errors.New("no hypervisors available")
+	}
+	var mostLoadedHypervisorWithRoom *Hypervisor
+	for _, hypervisor := range hypervisorList {
+		if !hypervisor.HasRoom() {
+			continue
+		}
+		if mostLoadedHypervisorWithRoom == nil || hypervisor.Load() > mostLoadedHypervisorWithRoom.Load() {
+			mostLoadedHypervisorWithRoom = hypervisor
+		}
+	}
+	if mostLoadedHypervisorWithRoom == nil {
+		return hypervisorList.leastLoaded()
+	}
+	return mostLoadedHypervisorWithRoom, nil
+}
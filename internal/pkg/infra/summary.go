@@ -0,0 +1,75 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package infra
+
+import (
+	"context"
+
+	criapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
+)
+
+// HypervisorSummary is a point-in-time view of an hypervisor's
+// registered capacity and current allocations, used to power
+// read-only inspection commands
+type HypervisorSummary struct {
+	Name            string
+	Public          bool
+	IPAddress       string
+	PortRangeLow    int
+	PortRangeHigh   int
+	AllocatedPorts  HypervisorPortAllocationList
+	FreedPorts      []int
+	AllowedCPUSet   string
+	AllowedMemoryMB int64
+}
+
+// Summary returns a point-in-time view of this hypervisor's
+// registered capacity and current allocations
+func (hypervisor *Hypervisor) Summary() HypervisorSummary {
+	return HypervisorSummary{
+		Name:            hypervisor.Name,
+		Public:          hypervisor.Public,
+		IPAddress:       hypervisor.IPAddress,
+		PortRangeLow:    hypervisor.portRangeLow,
+		PortRangeHigh:   hypervisor.portRangeHigh,
+		AllocatedPorts:  hypervisor.allocatedPorts,
+		FreedPorts:      hypervisor.freedPorts,
+		AllowedCPUSet:   hypervisor.allowedCPUSet,
+		AllowedMemoryMB: hypervisor.allowedMemoryMB,
+	}
+}
+
+// Health reaches out to this hypervisor's CRI endpoint and returns
+// whether it is currently reachable and reporting itself as
+// healthy. This performs a live query, unlike Summary, which only
+// reflects the last reconciled status
+func (hypervisor *Hypervisor) Health() (bool, error) {
+	criRuntime, err := hypervisor.CRIRuntime()
+	if err != nil {
+		return false, err
+	}
+	status, err := criRuntime.Status(context.Background(), &criapi.StatusRequest{})
+	if err != nil {
+		return false, err
+	}
+	for _, condition := range status.GetStatus().GetConditions() {
+		if !condition.GetStatus() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
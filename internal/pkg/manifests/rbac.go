@@ -0,0 +1,141 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package manifests
+
+import (
+	"github.com/pkg/errors"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ManagerFeatures represents the set of features enabled on an
+// oneinfra manager installation, used to compute the minimal set of
+// RBAC permissions it requires
+type ManagerFeatures struct {
+	// KeyInCRDSecretBackend enables storing join and certificate
+	// private keys directly in the oneinfra custom resources instead
+	// of in Kubernetes Secrets. When enabled, the manager does not
+	// need write access to the secrets resource.
+	KeyInCRDSecretBackend bool
+}
+
+// managerCoreRules are the RBAC rules required by the manager
+// regardless of the enabled feature set
+var managerCoreRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{"cluster.oneinfra.ereslibre.es"},
+		Resources: []string{"clusters", "components"},
+		Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+	},
+	{
+		APIGroups: []string{"cluster.oneinfra.ereslibre.es"},
+		Resources: []string{"clusters/status", "components/status"},
+		Verbs:     []string{"get", "patch", "update"},
+	},
+	{
+		APIGroups: []string{"cluster.oneinfra.ereslibre.es"},
+		Resources: []string{"oneinfraconfigs"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"infra.oneinfra.ereslibre.es"},
+		Resources: []string{"hypervisors"},
+		Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+	},
+	{
+		APIGroups: []string{"infra.oneinfra.ereslibre.es"},
+		Resources: []string{"hypervisors/status"},
+		Verbs:     []string{"get", "patch", "update"},
+	},
+	{
+		APIGroups: []string{"infra.oneinfra.ereslibre.es"},
+		Resources: []string{"hypervisorpools"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"cluster.oneinfra.ereslibre.es"},
+		Resources: []string{"freezewindows"},
+		Verbs:     []string{"get", "list", "watch"},
+	},
+	{
+		APIGroups: []string{"node.oneinfra.ereslibre.es"},
+		Resources: []string{"nodejoinrequests"},
+		Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+	},
+	{
+		APIGroups: []string{"node.oneinfra.ereslibre.es"},
+		Resources: []string{"nodejoinrequests/status"},
+		Verbs:     []string{"get", "patch", "update"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"configmaps"},
+		Verbs:     []string{"create", "get", "list", "patch", "update", "watch"},
+	},
+	{
+		APIGroups: []string{""},
+		Resources: []string{"services"},
+		Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+	},
+	{
+		APIGroups: []string{"discovery.k8s.io"},
+		Resources: []string{"endpointslices"},
+		Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+	},
+}
+
+// managerSecretRules are only required when private keys are stored
+// as Kubernetes Secrets
+var managerSecretRules = []rbacv1.PolicyRule{
+	{
+		APIGroups: []string{""},
+		Resources: []string{"secrets"},
+		Verbs:     []string{"create", "delete", "get", "list", "patch", "update", "watch"},
+	},
+}
+
+// GenerateManagerClusterRole returns the minimal ClusterRole required
+// by the manager for the given enabled features
+func GenerateManagerClusterRole(features ManagerFeatures) *rbacv1.ClusterRole {
+	rules := append([]rbacv1.PolicyRule{}, managerCoreRules...)
+	if !features.KeyInCRDSecretBackend {
+		rules = append(rules, managerSecretRules...)
+	}
+	return &rbacv1.ClusterRole{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "rbac.authorization.k8s.io/v1",
+			Kind:       "ClusterRole",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "manager-role",
+		},
+		Rules: rules,
+	}
+}
+
+// GenerateManagerClusterRoleYAML returns the YAML representation of
+// the minimal ClusterRole required by the manager for the given
+// enabled features
+func GenerateManagerClusterRoleYAML(features ManagerFeatures) (string, error) {
+	clusterRole := GenerateManagerClusterRole(features)
+	manifest, err := yaml.Marshal(clusterRole)
+	if err != nil {
+		return "", errors.Errorf("could not marshal manager ClusterRole: %v", err)
+	}
+	return string(manifest), nil
+}
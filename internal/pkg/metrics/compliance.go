@@ -0,0 +1,41 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var clusterComplianceIssues = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "oneinfra_cluster_compliance_issues",
+		Help: "Number of non-compliant findings from the last certificate and configuration compliance scan of a cluster.",
+	},
+	[]string{"cluster_namespace", "cluster_name"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(clusterComplianceIssues)
+}
+
+// SetClusterComplianceIssues records how many compliance issues were
+// found in the last scan of the cluster named clusterName in
+// clusterNamespace
+func SetClusterComplianceIssues(clusterNamespace, clusterName string, issueCount int) {
+	clusterComplianceIssues.WithLabelValues(clusterNamespace, clusterName).Set(float64(issueCount))
+}
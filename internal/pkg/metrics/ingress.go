@@ -0,0 +1,69 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	ingressBackendUp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oneinfra_cluster_ingress_backend_up",
+			Help: "Whether the control plane ingress haproxy currently considers an apiserver backend server healthy (1) or not (0).",
+		},
+		[]string{"cluster_namespace", "cluster_name", "component"},
+	)
+
+	ingressBackendCurrentSessions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oneinfra_cluster_ingress_backend_current_sessions",
+			Help: "Number of sessions currently open against an apiserver backend server behind the control plane ingress.",
+		},
+		[]string{"cluster_namespace", "cluster_name", "component"},
+	)
+
+	ingressBackendErrorResponses = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "oneinfra_cluster_ingress_backend_error_responses",
+			Help: "Cumulative count of connection and response errors the control plane ingress haproxy has recorded against an apiserver backend server.",
+		},
+		[]string{"cluster_namespace", "cluster_name", "component"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		ingressBackendUp,
+		ingressBackendCurrentSessions,
+		ingressBackendErrorResponses,
+	)
+}
+
+// SetClusterIngressBackendStats records the last collected haproxy
+// backend statistics for the control plane replica named component,
+// behind the cluster named clusterName in clusterNamespace's ingress
+func SetClusterIngressBackendStats(clusterNamespace, clusterName, component string, up bool, currentSessions int, errorResponses int64) {
+	upValue := float64(0)
+	if up {
+		upValue = 1
+	}
+	ingressBackendUp.WithLabelValues(clusterNamespace, clusterName, component).Set(upValue)
+	ingressBackendCurrentSessions.WithLabelValues(clusterNamespace, clusterName, component).Set(float64(currentSessions))
+	ingressBackendErrorResponses.WithLabelValues(clusterNamespace, clusterName, component).Set(float64(errorResponses))
+}
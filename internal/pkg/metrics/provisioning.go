@@ -0,0 +1,53 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+// Package metrics registers oneinfra-specific Prometheus collectors
+// against the controller-runtime metrics registry, so they are
+// served alongside the manager's own controller metrics without
+// standing up a separate endpoint
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
+)
+
+var provisioningPhaseDurationSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name: "oneinfra_cluster_provisioning_phase_duration_seconds",
+		Help: "Time elapsed between cluster creation and first reaching a provisioning phase, " +
+			"letting teams track time-to-cluster and find the slowest phase across the fleet.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	},
+	[]string{"phase"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(provisioningPhaseDurationSeconds)
+}
+
+// ObserveProvisioningPhase records how long it took a cluster created
+// at creationTimestamp to first reach phase at reachedAt
+func ObserveProvisioningPhase(phase clusterv1alpha1.ProvisioningPhase, creationTimestamp, reachedAt time.Time) {
+	if creationTimestamp.IsZero() {
+		return
+	}
+	provisioningPhaseDurationSeconds.WithLabelValues(string(phase)).Observe(reachedAt.Sub(creationTimestamp).Seconds())
+}
@@ -17,6 +17,9 @@
 package nodejoinrequests
 
 import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	nodev1alpha1 "github.com/oneinfra/oneinfra/apis/node/v1alpha1"
@@ -33,25 +36,40 @@ const (
 // NodeJoinRequest represents a node join request
 type NodeJoinRequest struct {
 	Name                       string
+	SchemaVersion              int
+	SourceFingerprint          string
 	SymmetricKey               crypto.SymmetricKey
 	APIServerEndpoint          string
 	ContainerRuntimeEndpoint   string
 	ImageServiceEndpoint       string
 	KubernetesVersion          string
+	ImageRegistry              string
 	VPN                        *VPN
 	KubeConfig                 string
 	KubeletConfig              string
 	KubeletServerCertificate   string
 	KubeletServerPrivateKey    string
 	KubeletClientCACertificate string
+	ClusterCACertificate       string
 	ExtraSANs                  []string
+	PreferredAddressFamily     string
+	CipherSuite                string
 	Conditions                 conditions.ConditionList
 	ResourceVersion            string
-	joinKey                    *crypto.KeyPair
+	Signature                  string
+	joinKey                    crypto.KeyPairProvider
 }
 
 // NewNodeJoinRequestFromv1alpha1 returns a node join request based on a versioned node join request
-func NewNodeJoinRequestFromv1alpha1(nodeJoinRequest *nodev1alpha1.NodeJoinRequest, joinKey *crypto.KeyPair) (*NodeJoinRequest, error) {
+func NewNodeJoinRequestFromv1alpha1(nodeJoinRequest *nodev1alpha1.NodeJoinRequest, joinKey crypto.KeyPairProvider) (*NodeJoinRequest, error) {
+	if nodeJoinRequest.Status.SchemaVersion > nodev1alpha1.NodeJoinRequestStatusSchemaVersion {
+		return nil, errors.Errorf(
+			"node join request %q has schema version %d, newer than the %d this version understands",
+			nodeJoinRequest.Name,
+			nodeJoinRequest.Status.SchemaVersion,
+			nodev1alpha1.NodeJoinRequestStatusSchemaVersion,
+		)
+	}
 	symmetricKey := nodeJoinRequest.Spec.SymmetricKey
 	if joinKey != nil {
 		key, err := joinKey.Decrypt(nodeJoinRequest.Spec.SymmetricKey)
@@ -62,19 +80,26 @@ func NewNodeJoinRequestFromv1alpha1(nodeJoinRequest *nodev1alpha1.NodeJoinReques
 	}
 	res := NodeJoinRequest{
 		Name:                       nodeJoinRequest.Name,
+		SchemaVersion:              nodeJoinRequest.Status.SchemaVersion,
+		SourceFingerprint:          nodeJoinRequest.Status.SourceFingerprint,
 		SymmetricKey:               crypto.SymmetricKey(symmetricKey),
 		APIServerEndpoint:          nodeJoinRequest.Spec.APIServerEndpoint,
 		ContainerRuntimeEndpoint:   nodeJoinRequest.Spec.ContainerRuntimeEndpoint,
 		ImageServiceEndpoint:       nodeJoinRequest.Spec.ImageServiceEndpoint,
 		KubernetesVersion:          nodeJoinRequest.Status.KubernetesVersion,
+		ImageRegistry:              nodeJoinRequest.Status.ImageRegistry,
 		KubeConfig:                 nodeJoinRequest.Status.KubeConfig,
 		KubeletConfig:              nodeJoinRequest.Status.KubeletConfig,
 		KubeletServerCertificate:   nodeJoinRequest.Status.KubeletServerCertificate,
 		KubeletServerPrivateKey:    nodeJoinRequest.Status.KubeletServerPrivateKey,
 		KubeletClientCACertificate: nodeJoinRequest.Status.KubeletClientCACertificate,
+		ClusterCACertificate:       nodeJoinRequest.Status.ClusterCACertificate,
 		ExtraSANs:                  nodeJoinRequest.Spec.ExtraSANs,
+		PreferredAddressFamily:     nodeJoinRequest.Spec.PreferredAddressFamily,
+		CipherSuite:                nodeJoinRequest.Status.CipherSuite,
 		Conditions:                 conditions.NewConditionListFromv1alpha1(nodeJoinRequest.Status.Conditions),
 		ResourceVersion:            nodeJoinRequest.ResourceVersion,
+		Signature:                  nodeJoinRequest.Status.Signature,
 		joinKey:                    joinKey,
 	}
 	if nodeJoinRequest.Status.VPN != nil {
@@ -84,6 +109,7 @@ func NewNodeJoinRequestFromv1alpha1(nodeJoinRequest *nodev1alpha1.NodeJoinReques
 			PeerPrivateKey:    nodeJoinRequest.Status.VPN.PeerPrivateKey,
 			Endpoint:          nodeJoinRequest.Status.VPN.Endpoint,
 			EndpointPublicKey: nodeJoinRequest.Status.VPN.EndpointPublicKey,
+			KeepaliveSeconds:  nodeJoinRequest.Status.VPN.KeepaliveSeconds,
 		}
 	}
 	return &res, nil
@@ -110,15 +136,22 @@ func (nodeJoinRequest *NodeJoinRequest) Export() (*nodev1alpha1.NodeJoinRequest,
 			ContainerRuntimeEndpoint: nodeJoinRequest.ContainerRuntimeEndpoint,
 			ImageServiceEndpoint:     nodeJoinRequest.ImageServiceEndpoint,
 			ExtraSANs:                nodeJoinRequest.ExtraSANs,
+			PreferredAddressFamily:   nodeJoinRequest.PreferredAddressFamily,
 		},
 		Status: nodev1alpha1.NodeJoinRequestStatus{
+			SchemaVersion:              nodev1alpha1.NodeJoinRequestStatusSchemaVersion,
+			SourceFingerprint:          nodeJoinRequest.SourceFingerprint,
 			KubernetesVersion:          nodeJoinRequest.KubernetesVersion,
+			ImageRegistry:              nodeJoinRequest.ImageRegistry,
 			KubeConfig:                 nodeJoinRequest.KubeConfig,
 			KubeletConfig:              nodeJoinRequest.KubeletConfig,
 			KubeletServerCertificate:   nodeJoinRequest.KubeletServerCertificate,
 			KubeletServerPrivateKey:    nodeJoinRequest.KubeletServerPrivateKey,
 			KubeletClientCACertificate: nodeJoinRequest.KubeletClientCACertificate,
+			ClusterCACertificate:       nodeJoinRequest.ClusterCACertificate,
+			CipherSuite:                nodeJoinRequest.CipherSuite,
 			Conditions:                 nodeJoinRequest.Conditions.Export(),
+			Signature:                  nodeJoinRequest.Signature,
 		},
 	}
 	if nodeJoinRequest.VPN != nil {
@@ -128,11 +161,49 @@ func (nodeJoinRequest *NodeJoinRequest) Export() (*nodev1alpha1.NodeJoinRequest,
 			PeerPrivateKey:    nodeJoinRequest.VPN.PeerPrivateKey,
 			Endpoint:          nodeJoinRequest.VPN.Endpoint,
 			EndpointPublicKey: nodeJoinRequest.VPN.EndpointPublicKey,
+			KeepaliveSeconds:  nodeJoinRequest.VPN.KeepaliveSeconds,
 		}
 	}
 	return &res, nil
 }
 
+// SignaturePayload returns a canonical representation of the status
+// fields covered by Signature, so the management plane can sign it
+// once it has filled them all in, and a joining node can recompute
+// the same payload to verify it
+func (nodeJoinRequest *NodeJoinRequest) SignaturePayload() (string, error) {
+	signed := struct {
+		Name                       string
+		SourceFingerprint          string
+		KubernetesVersion          string
+		ImageRegistry              string
+		VPN                        *VPN
+		KubeConfig                 string
+		KubeletConfig              string
+		KubeletServerCertificate   string
+		KubeletServerPrivateKey    string
+		KubeletClientCACertificate string
+		ClusterCACertificate       string
+	}{
+		Name:                       nodeJoinRequest.Name,
+		SourceFingerprint:          nodeJoinRequest.SourceFingerprint,
+		KubernetesVersion:          nodeJoinRequest.KubernetesVersion,
+		ImageRegistry:              nodeJoinRequest.ImageRegistry,
+		VPN:                        nodeJoinRequest.VPN,
+		KubeConfig:                 nodeJoinRequest.KubeConfig,
+		KubeletConfig:              nodeJoinRequest.KubeletConfig,
+		KubeletServerCertificate:   nodeJoinRequest.KubeletServerCertificate,
+		KubeletServerPrivateKey:    nodeJoinRequest.KubeletServerPrivateKey,
+		KubeletClientCACertificate: nodeJoinRequest.KubeletClientCACertificate,
+		ClusterCACertificate:       nodeJoinRequest.ClusterCACertificate,
+	}
+	marshaled, err := json.Marshal(signed)
+	if err != nil {
+		return "", err
+	}
+	return string(marshaled), nil
+}
+
 // Decrypt decrypts the given content using this node join request symmetric key
 func (nodeJoinRequest *NodeJoinRequest) Decrypt(content string) (string, error) {
 	return nodeJoinRequest.SymmetricKey.Decrypt(content)
@@ -23,4 +23,9 @@ type VPN struct {
 	PeerPrivateKey    string
 	Endpoint          string
 	EndpointPublicKey string
+
+	// KeepaliveSeconds is the WireGuard persistent keepalive interval
+	// this node should use, in seconds. Not ciphered, since it carries
+	// no sensitive information
+	KeepaliveSeconds int
 }
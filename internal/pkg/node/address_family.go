@@ -0,0 +1,54 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package node
+
+import (
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	addressFamilyIPv4 = "IPv4"
+	addressFamilyIPv6 = "IPv6"
+)
+
+// selectNodeIP returns the address the kubelet should advertise as
+// its node IP, picked among this node's non-loopback unicast
+// addresses according to preferredAddressFamily. An empty
+// preferredAddressFamily leaves the choice to the kubelet, and
+// selectNodeIP returns an empty string
+func selectNodeIP(preferredAddressFamily string) (string, error) {
+	if preferredAddressFamily == "" {
+		return "", nil
+	}
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || !ipNet.IP.IsGlobalUnicast() {
+			continue
+		}
+		isIPv4 := ipNet.IP.To4() != nil
+		if (preferredAddressFamily == addressFamilyIPv4) == isIPv4 {
+			return ipNet.IP.String(), nil
+		}
+	}
+	return "", errors.Errorf("could not find a %s address on this node", preferredAddressFamily)
+}
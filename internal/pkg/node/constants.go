@@ -17,6 +17,7 @@
 package node
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"github.com/oneinfra/oneinfra/pkg/constants"
@@ -32,7 +33,7 @@ Description=kubelet: The Kubernetes Node Agent
 Documentation=https://kubernetes.io/docs/home/
 
 [Service]
-Environment="KUBELET_ARGS=--hostname-override={{.Nodename}}"
+Environment="KUBELET_ARGS=--hostname-override={{.Nodename}}{{if .NodeIP}} --node-ip={{.NodeIP}}{{end}}"
 Environment="KUBELET_KUBECONFIG_ARGS=--kubeconfig={{.KubeletKubeConfigPath}}"
 Environment="KUBELET_CONFIG_ARGS=--config={{.KubeletConfigPath}}"
 Environment="SERVICE_ENDPOINTS_ARGS=--container-runtime=remote --image-service-endpoint={{.ImageServiceEndpoint}} --container-runtime-endpoint={{.ContainerRuntimeEndpoint}}"
@@ -51,3 +52,11 @@ var (
 	wireguardSystemdServicePath = filepath.Join(systemdDir, "oi-wg.service")
 	peerPrivateKeyPath          = filepath.Join(constants.OneInfraConfigDir, "wg.key")
 )
+
+// KubeletInstallerImage returns the kubelet-installer image pinned
+// for kubernetesVersion, with no registry override applied, for
+// callers that need it upfront and not tied to a joining node (e.g.
+// the offline bundle builder)
+func KubeletInstallerImage(kubernetesVersion string) string {
+	return fmt.Sprintf(kubeletInstallerImage, kubernetesVersion)
+}
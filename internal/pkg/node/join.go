@@ -28,6 +28,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"text/template"
 	"time"
 
@@ -40,6 +41,7 @@ import (
 	criapi "k8s.io/cri-api/pkg/apis/runtime/v1alpha2"
 	"k8s.io/klog/v2"
 
+	clusterv1alpha1 "github.com/oneinfra/oneinfra/apis/cluster/v1alpha1"
 	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
 	nodev1alpha1 "github.com/oneinfra/oneinfra/apis/node/v1alpha1"
 	"github.com/oneinfra/oneinfra/internal/pkg/cluster"
@@ -52,19 +54,25 @@ import (
 )
 
 // Join joins a node to an existing cluster
-func Join(nodename, apiServerEndpoint, caCertificate, token, containerRuntimeEndpoint, imageServiceEndpoint string, extraSANs []string) error {
+func Join(nodename, apiServerEndpoint, caCertificate, token, containerRuntimeEndpoint, imageServiceEndpoint string, extraSANs []string, preferredAddressFamily string, maxClockSkew time.Duration) error {
 	if err := checkContainerRuntimeEndpoint(containerRuntimeEndpoint); err != nil {
 		return err
 	}
 	if err := checkImageServiceEndpoint(imageServiceEndpoint); err != nil {
 		return err
 	}
+	if err := checkPreferredAddressFamily(preferredAddressFamily); err != nil {
+		return err
+	}
+	if err := checkClockSkew(apiServerEndpoint, caCertificate, token, maxClockSkew); err != nil {
+		return err
+	}
 	klog.Info("loading or generating symmetric key")
 	symmetricKey, err := readOrGenerateSymmetricKey()
 	if err != nil {
 		return err
 	}
-	nodeJoinRequest, err := createAndWaitForJoinRequest(nodename, apiServerEndpoint, caCertificate, token, containerRuntimeEndpoint, imageServiceEndpoint, symmetricKey, extraSANs)
+	nodeJoinRequest, err := createAndWaitForJoinRequest(nodename, apiServerEndpoint, caCertificate, token, containerRuntimeEndpoint, imageServiceEndpoint, symmetricKey, extraSANs, preferredAddressFamily)
 	if err != nil {
 		return err
 	}
@@ -76,6 +84,14 @@ func Join(nodename, apiServerEndpoint, caCertificate, token, containerRuntimeEnd
 	return setupKubelet(nodeJoinRequest, symmetricKey)
 }
 
+// knownContainerRuntimes lists the CRI runtime names oneinfra has
+// been validated against. An unrecognized name is not rejected, since
+// oneinfra only ever talks to a node's container runtime through the
+// standard CRI v1alpha2 API and any compliant runtime is expected to
+// work, but it is logged so operators know they are on an unverified
+// combination
+var knownContainerRuntimes = []string{"containerd", "cri-o", "docker"}
+
 func checkContainerRuntimeEndpoint(containerRuntimeEndpoint string) error {
 	klog.Info("checking whether the provided container runtime endpoint is responding")
 	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
@@ -90,9 +106,21 @@ func checkContainerRuntimeEndpoint(containerRuntimeEndpoint string) error {
 		return errors.Errorf("could not connect to the container runtime endpoint at %q", containerRuntimeEndpoint)
 	}
 	klog.Infof("container runtime endpoint: %s (%s)", runtimeVersion.RuntimeName, runtimeVersion.RuntimeVersion)
+	if !isKnownContainerRuntime(runtimeVersion.RuntimeName) {
+		klog.Warningf("container runtime %q has not been validated against oneinfra; proceeding, since it speaks the standard CRI v1alpha2 API", runtimeVersion.RuntimeName)
+	}
 	return nil
 }
 
+func isKnownContainerRuntime(runtimeName string) bool {
+	for _, knownContainerRuntime := range knownContainerRuntimes {
+		if strings.EqualFold(runtimeName, knownContainerRuntime) {
+			return true
+		}
+	}
+	return false
+}
+
 func checkImageServiceEndpoint(imageServiceEndpoint string) error {
 	klog.Info("checking whether the provided image service endpoint is responding")
 	ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
@@ -108,6 +136,40 @@ func checkImageServiceEndpoint(imageServiceEndpoint string) error {
 	return nil
 }
 
+func checkPreferredAddressFamily(preferredAddressFamily string) error {
+	switch preferredAddressFamily {
+	case "", addressFamilyIPv4, addressFamilyIPv6:
+		return nil
+	default:
+		return errors.Errorf("invalid preferred address family %q, expected one of: %q, %q", preferredAddressFamily, addressFamilyIPv4, addressFamilyIPv6)
+	}
+}
+
+// checkClockSkew compares this node's clock against the management
+// plane's, refusing to join when the drift exceeds maxClockSkew. A
+// node clock running behind the management plane would otherwise
+// receive certificates that are not yet valid from its own point of
+// view, and fail to start its kubelet with "not yet valid" errors
+func checkClockSkew(apiServerEndpoint, caCertificate, token string, maxClockSkew time.Duration) error {
+	klog.Info("checking the local clock against the management plane's")
+	kubeConfig, err := cluster.KubeConfigWithToken("cluster", apiServerEndpoint, caCertificate, token)
+	if err != nil {
+		return err
+	}
+	remoteTime, err := cluster.RemoteTime(kubeConfig, apiServerEndpoint)
+	if err != nil {
+		return errors.Wrap(err, "could not check the management plane's clock")
+	}
+	skew := time.Since(remoteTime)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxClockSkew {
+		return errors.Errorf("local clock is off from the management plane's by %s, which exceeds the maximum tolerated clock skew of %s; fix the local clock (e.g. with NTP) before joining", skew, maxClockSkew)
+	}
+	return nil
+}
+
 func createClient(apiServerEndpoint, caCertificate, token string) (*restclient.RESTClient, error) {
 	kubeConfig, err := cluster.KubeConfigWithToken("cluster", apiServerEndpoint, caCertificate, token)
 	if err != nil {
@@ -140,7 +202,7 @@ func createOneInfraManagedClient(apiServerEndpoint, caCertificate, token string)
 	return cluster.OneInfraManagedClientFromKubeConfig(kubeConfig)
 }
 
-func createJoinRequest(client oneinframanagedclientset.Interface, apiServerEndpoint, nodename, cryptedSymmetricKey, containerRuntimeEndpoint, imageServiceEndpoint string, extraSANs []string, symmetricKey crypto.SymmetricKey) error {
+func createJoinRequest(client oneinframanagedclientset.Interface, apiServerEndpoint, nodename, cryptedSymmetricKey, containerRuntimeEndpoint, imageServiceEndpoint string, extraSANs []string, preferredAddressFamily string, symmetricKey crypto.SymmetricKey) error {
 	cryptedAPIServerEndpoint, err := symmetricKey.Encrypt(apiServerEndpoint)
 	if err != nil {
 		return err
@@ -161,6 +223,10 @@ func createJoinRequest(client oneinframanagedclientset.Interface, apiServerEndpo
 		}
 		cryptedExtraSANs = append(cryptedExtraSANs, cryptedExtraSAN)
 	}
+	cryptedPreferredAddressFamily, err := symmetricKey.Encrypt(preferredAddressFamily)
+	if err != nil {
+		return err
+	}
 	nodeJoinRequest := nodev1alpha1.NodeJoinRequest{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: nodename,
@@ -171,6 +237,7 @@ func createJoinRequest(client oneinframanagedclientset.Interface, apiServerEndpo
 			ContainerRuntimeEndpoint: cryptedContainerRuntimeEndpoint,
 			ImageServiceEndpoint:     cryptedImageServiceEndpoint,
 			ExtraSANs:                cryptedExtraSANs,
+			PreferredAddressFamily:   cryptedPreferredAddressFamily,
 		},
 	}
 	_, err = client.NodeV1alpha1().NodeJoinRequests().Create(
@@ -289,6 +356,17 @@ func writeKubeletClientCACertificate(nodeJoinRequest *nodejoinrequests.NodeJoinR
 	return ioutil.WriteFile(constants.KubeletClientCACertificatePath, []byte(clientCACertificate), 0600)
 }
 
+func writeClusterCACertificate(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetricKey crypto.SymmetricKey) error {
+	clusterCACertificate, err := decrypt(symmetricKey, nodeJoinRequest.ClusterCACertificate)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(constants.OneInfraConfigDir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(constants.ClusterCACertificatePath, []byte(clusterCACertificate), 0600)
+}
+
 func installKubelet(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetricKey crypto.SymmetricKey) error {
 	kubernetesVersion, err := decrypt(symmetricKey, nodeJoinRequest.KubernetesVersion)
 	if err != nil {
@@ -302,7 +380,14 @@ func installKubelet(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetric
 	if err != nil {
 		return err
 	}
-	kubeletImage := fmt.Sprintf(kubeletInstallerImage, kubernetesVersion)
+	imageRegistry := ""
+	if nodeJoinRequest.ImageRegistry != "" {
+		imageRegistry, err = decrypt(symmetricKey, nodeJoinRequest.ImageRegistry)
+		if err != nil {
+			return err
+		}
+	}
+	kubeletImage := constants.ImageWithRegistry(fmt.Sprintf(kubeletInstallerImage, kubernetesVersion), imageRegistry)
 	klog.Infof("installing the kubelet from %q", kubeletImage)
 	hypervisorRuntimeEndpoint := infra.NewLocalHypervisor(nodeJoinRequest.Name, containerRuntimeEndpoint)
 	hypervisorImageEndpoint := infra.NewLocalHypervisor(nodeJoinRequest.Name, imageServiceEndpoint)
@@ -318,7 +403,7 @@ func installKubelet(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetric
 			Containers: []podapi.Container{
 				{
 					Name:  "kubelet-installer",
-					Image: fmt.Sprintf(kubeletInstallerImage, kubernetesVersion),
+					Image: kubeletImage,
 					Mounts: map[string]string{
 						"/usr/local/bin": "/host",
 					},
@@ -343,6 +428,14 @@ func setupSystemd(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetricKe
 	if err != nil {
 		return err
 	}
+	preferredAddressFamily, err := decrypt(symmetricKey, nodeJoinRequest.PreferredAddressFamily)
+	if err != nil {
+		return err
+	}
+	nodeIP, err := selectNodeIP(preferredAddressFamily)
+	if err != nil {
+		return err
+	}
 	var kubeletSystemdService bytes.Buffer
 	err = kubeletSystemdServiceTpl.Execute(&kubeletSystemdService, struct {
 		Nodename                 string
@@ -350,12 +443,14 @@ func setupSystemd(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetricKe
 		KubeletConfigPath        string
 		ImageServiceEndpoint     string
 		ContainerRuntimeEndpoint string
+		NodeIP                   string
 	}{
 		Nodename:                 nodeJoinRequest.Name,
 		KubeletKubeConfigPath:    constants.KubeletKubeConfigPath,
 		KubeletConfigPath:        constants.KubeletConfigPath,
 		ContainerRuntimeEndpoint: containerRuntimeEndpoint,
 		ImageServiceEndpoint:     imageServiceEndpoint,
+		NodeIP:                   nodeIP,
 	})
 	if err != nil {
 		return err
@@ -367,7 +462,7 @@ func startKubelet() error {
 	return exec.Command("systemctl", "enable", "--now", "kubelet").Run()
 }
 
-func createAndWaitForJoinRequest(nodename, apiServerEndpoint, caCertificate, token, containerRuntimeEndpoint, imageServiceEndpoint string, symmetricKey crypto.SymmetricKey, extraSANs []string) (*nodejoinrequests.NodeJoinRequest, error) {
+func createAndWaitForJoinRequest(nodename, apiServerEndpoint, caCertificate, token, containerRuntimeEndpoint, imageServiceEndpoint string, symmetricKey crypto.SymmetricKey, extraSANs []string, preferredAddressFamily string) (*nodejoinrequests.NodeJoinRequest, error) {
 	oneinfraManagedClient, err := createOneInfraManagedClient(apiServerEndpoint, caCertificate, token)
 	if err != nil {
 		return nil, err
@@ -385,11 +480,17 @@ func createAndWaitForJoinRequest(nodename, apiServerEndpoint, caCertificate, tok
 	if err != nil {
 		return nil, err
 	}
-	joinPublicKeyPEM, exists := oneinfraPublicConfigMap.Data[constants.OneInfraJoinConfigMapJoinKey]
+	joinPublicKeyEncoded, exists := oneinfraPublicConfigMap.Data[constants.OneInfraJoinConfigMapJoinKey]
 	if !exists {
 		return nil, errors.Errorf("could not find field %q in ConfigMap %q (in namespace %q)", constants.OneInfraJoinConfigMapJoinKey, constants.OneInfraJoinConfigMap, metav1.NamespacePublic)
 	}
-	joinPublicKey, err := crypto.NewPublicKeyFromString(joinPublicKeyPEM)
+	var joinPublicKey crypto.Encrypter
+	switch clusterv1alpha1.JoinKeyCipherSuite(oneinfraPublicConfigMap.Data[constants.OneInfraJoinConfigMapJoinKeyCipherSuite]) {
+	case clusterv1alpha1.JoinKeyCipherSuiteNaClBox:
+		joinPublicKey, err = crypto.NewNaClBoxPublicKeyFromString(joinPublicKeyEncoded)
+	default:
+		joinPublicKey, err = crypto.NewPublicKeyFromString(joinPublicKeyEncoded)
+	}
 	if err != nil {
 		return nil, errors.New("could not read a public key")
 	}
@@ -398,10 +499,40 @@ func createAndWaitForJoinRequest(nodename, apiServerEndpoint, caCertificate, tok
 		return nil, err
 	}
 	klog.Infof("creating node join request for nodename %q", nodename)
-	if err := createJoinRequest(oneinfraManagedClient, apiServerEndpoint, nodename, cryptedSymmetricKey, containerRuntimeEndpoint, imageServiceEndpoint, extraSANs, symmetricKey); err != nil {
+	if err := createJoinRequest(oneinfraManagedClient, apiServerEndpoint, nodename, cryptedSymmetricKey, containerRuntimeEndpoint, imageServiceEndpoint, extraSANs, preferredAddressFamily, symmetricKey); err != nil {
+		return nil, err
+	}
+	nodeJoinRequest, err := waitForJoinRequestIssuedCondition(oneinfraManagedClient, nodename, 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	signingPublicKeyPEM, exists := oneinfraPublicConfigMap.Data[constants.OneInfraJoinConfigMapSigningKey]
+	if !exists {
+		return nil, errors.Errorf("could not find field %q in ConfigMap %q (in namespace %q); refusing to trust an unsigned join response", constants.OneInfraJoinConfigMapSigningKey, constants.OneInfraJoinConfigMap, constants.OneInfraNamespace)
+	}
+	if err := verifyNodeJoinRequestSignature(nodeJoinRequest, signingPublicKeyPEM); err != nil {
 		return nil, err
 	}
-	return waitForJoinRequestIssuedCondition(oneinfraManagedClient, nodename, 5*time.Minute)
+	return nodeJoinRequest, nil
+}
+
+// verifyNodeJoinRequestSignature verifies that nodeJoinRequest was
+// signed by the holder of signingPublicKeyPEM, so a compromised or
+// misbehaving apiserver cannot hand this node tampered kubeconfig,
+// kubelet configuration or certificates
+func verifyNodeJoinRequestSignature(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, signingPublicKeyPEM string) error {
+	signingPublicKey, err := crypto.NewSigningPublicKeyFromString(signingPublicKeyPEM)
+	if err != nil {
+		return errors.New("could not read the management plane signing public key")
+	}
+	payload, err := nodeJoinRequest.SignaturePayload()
+	if err != nil {
+		return err
+	}
+	if err := signingPublicKey.Verify(payload, nodeJoinRequest.Signature); err != nil {
+		return errors.Errorf("node join request %q failed signature verification, refusing to trust its contents", nodeJoinRequest.Name)
+	}
+	return nil
 }
 
 func setupKubelet(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetricKey crypto.SymmetricKey) error {
@@ -418,6 +549,9 @@ func setupKubelet(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetricKe
 	if err := writeKubeletClientCACertificate(nodeJoinRequest, symmetricKey); err != nil {
 		return err
 	}
+	if err := writeClusterCACertificate(nodeJoinRequest, symmetricKey); err != nil {
+		return err
+	}
 	if err := installKubelet(nodeJoinRequest, symmetricKey); err != nil {
 		return err
 	}
@@ -0,0 +1,87 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	commonv1alpha1 "github.com/oneinfra/oneinfra/apis/common/v1alpha1"
+	nodev1alpha1 "github.com/oneinfra/oneinfra/apis/node/v1alpha1"
+	oneinframanagedclientset "github.com/oneinfra/oneinfra/pkg/clientsets/managed"
+)
+
+// Leave decommissions this node from an existing cluster, by issuing
+// a node leave request and waiting for the management plane to mark
+// it as completed
+func Leave(nodename, apiServerEndpoint, caCertificate, token string, timeout time.Duration) error {
+	client, err := createOneInfraManagedClient(apiServerEndpoint, caCertificate, token)
+	if err != nil {
+		return err
+	}
+	if err := createLeaveRequest(client, nodename); err != nil {
+		return err
+	}
+	return waitForLeaveRequestCompletedCondition(client, nodename, timeout)
+}
+
+func createLeaveRequest(client oneinframanagedclientset.Interface, nodename string) error {
+	nodeLeaveRequest := nodev1alpha1.NodeLeaveRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodename,
+		},
+	}
+	_, err := client.NodeV1alpha1().NodeLeaveRequests().Create(
+		context.TODO(),
+		&nodeLeaveRequest,
+		metav1.CreateOptions{},
+	)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+func waitForLeaveRequestCompletedCondition(client oneinframanagedclientset.Interface, nodename string, timeout time.Duration) error {
+	klog.Infof("waiting for leave request %q to be completed; will timeout in %s", nodename, timeout)
+	timeoutChan := time.After(timeout)
+	tickChan := time.Tick(time.Second)
+	for {
+		select {
+		case <-timeoutChan:
+			return errors.New("timed out waiting for completed condition")
+		case <-tickChan:
+			klog.Info("waiting for the node leave request to be completed")
+			nodeLeaveRequest, err := client.NodeV1alpha1().NodeLeaveRequests().Get(
+				context.TODO(),
+				nodename,
+				metav1.GetOptions{},
+			)
+			if err != nil {
+				continue
+			}
+			if nodeLeaveRequest.Status.Conditions.IsCondition(nodev1alpha1.Completed, commonv1alpha1.ConditionTrue) {
+				return nil
+			}
+		}
+	}
+}
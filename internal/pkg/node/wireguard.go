@@ -50,9 +50,14 @@ fi
 ip link del oi-wg
 ip link add dev oi-wg type wireguard
 ip addr add {{ .Address }} dev oi-wg
-wg set oi-wg private-key {{ .PeerPrivateKeyPath }} peer {{ .EndpointPublicKey }} endpoint {{ .Endpoint }} allowed-ips {{ .CIDR }} persistent-keepalive 20
+wg set oi-wg private-key {{ .PeerPrivateKeyPath }} peer {{ .EndpointPublicKey }} endpoint {{ .Endpoint }} allowed-ips {{ .CIDR }} persistent-keepalive {{ .KeepaliveSeconds }}
 ip link set oi-wg up
 `
+
+	// wireguardDefaultKeepaliveSeconds is the WireGuard persistent
+	// keepalive interval used when a node join request was issued
+	// before this field existed
+	wireguardDefaultKeepaliveSeconds = 20
 )
 
 func wireguardScriptContents(nodeJoinRequest *nodejoinrequests.NodeJoinRequest, symmetricKey crypto.SymmetricKey) (string, error) {
@@ -76,6 +81,10 @@ func wireguardScriptContents(nodeJoinRequest *nodejoinrequests.NodeJoinRequest,
 	if err != nil {
 		return "", err
 	}
+	keepaliveSeconds := nodeJoinRequest.VPN.KeepaliveSeconds
+	if keepaliveSeconds == 0 {
+		keepaliveSeconds = wireguardDefaultKeepaliveSeconds
+	}
 	var rendered bytes.Buffer
 	err = template.Execute(&rendered, struct {
 		CIDR               string
@@ -83,12 +92,14 @@ func wireguardScriptContents(nodeJoinRequest *nodejoinrequests.NodeJoinRequest,
 		PeerPrivateKeyPath string
 		Endpoint           string
 		EndpointPublicKey  string
+		KeepaliveSeconds   int
 	}{
 		CIDR:               cidr,
 		Address:            peerAddress,
 		PeerPrivateKeyPath: peerPrivateKeyPath,
 		Endpoint:           endpointAddress,
 		EndpointPublicKey:  endpointPublicKey,
+		KeepaliveSeconds:   keepaliveSeconds,
 	})
 	return rendered.String(), err
 }
@@ -56,6 +56,11 @@ func (reconcileErrors ReconcileErrors) IsClusterErrorFree(clusterNamespace, clus
 	return len(clusterErrors) == 0
 }
 
+// ClusterErrors returns the errors recorded for the cluster provided
+func (reconcileErrors ReconcileErrors) ClusterErrors(clusterNamespace, clusterName string) []error {
+	return reconcileErrors[fullClusterName(clusterNamespace, clusterName)]
+}
+
 // AddClusterError adds a cluster-level error
 func (reconcileErrors ReconcileErrors) AddClusterError(clusterNamespace, clusterName string, err error) {
 	fullClusterName := fullClusterName(clusterNamespace, clusterName)
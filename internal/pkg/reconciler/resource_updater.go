@@ -63,7 +63,12 @@ func updateClusters(ctx context.Context, reconciler Reconciler, client clientapi
 			continue
 		}
 		if isDirty {
-			if err := client.Status().Update(ctx, cluster.Export()); err != nil {
+			exportedCluster, err := cluster.Export()
+			if err != nil {
+				klog.Errorf("could not export cluster %q: %v", cluster.Name, err)
+				return err
+			}
+			if err := client.Status().Update(ctx, exportedCluster); err != nil {
 				klog.Errorf("could not update cluster %q status: %v", cluster.Name, err)
 				return err
 			}
@@ -0,0 +1,112 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package reconciler
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// stepBackoffBase is the base delay used to compute the exponential
+// backoff between attempts of a failing step
+const stepBackoffBase = 100 * time.Millisecond
+
+// Step represents a single unit of work within a StepGraph. A step
+// is only run once every step named in DependsOn has already
+// completed successfully, letting callers declare the dependencies
+// between steps instead of relying on the order in which they
+// happen to be listed
+type Step struct {
+	// Name uniquely identifies this step within its StepGraph
+	Name string
+	// DependsOn lists the names of the steps that must have
+	// completed successfully before this step is run
+	DependsOn []string
+	// MaxAttempts is the maximum number of times Run is attempted,
+	// backing off exponentially between attempts, before the step
+	// is considered failed. Zero means one attempt, with no retries
+	MaxAttempts int
+	// Run performs the actual work for this step
+	Run func() error
+}
+
+// StepGraph is an ordered collection of steps, executed respecting
+// the dependencies declared by each one
+type StepGraph []Step
+
+// StepStatus records the outcome of running a single step
+type StepStatus struct {
+	Name     string
+	Attempts int
+	Err      error
+}
+
+// Succeeded returns whether this step completed without error
+func (status StepStatus) Succeeded() bool {
+	return status.Err == nil
+}
+
+// Execute runs every step in graph, in the order they are listed,
+// retrying a failing step with exponential backoff up to its
+// MaxAttempts. A step whose dependencies have not all completed
+// successfully is not run, and is recorded as failed. Execute stops
+// on the first step that ultimately fails, returning the per-step
+// statuses recorded so far alongside the error
+func Execute(graph StepGraph) ([]StepStatus, error) {
+	statuses := []StepStatus{}
+	succeeded := map[string]bool{}
+	for _, step := range graph {
+		if err := unmetDependencies(step, succeeded); err != nil {
+			statuses = append(statuses, StepStatus{Name: step.Name, Err: err})
+			return statuses, errors.Wrapf(err, "step %q", step.Name)
+		}
+		status := runStepWithBackoff(step)
+		statuses = append(statuses, status)
+		if !status.Succeeded() {
+			return statuses, errors.Wrapf(status.Err, "step %q", step.Name)
+		}
+		succeeded[step.Name] = true
+	}
+	return statuses, nil
+}
+
+func unmetDependencies(step Step, succeeded map[string]bool) error {
+	for _, dependency := range step.DependsOn {
+		if !succeeded[dependency] {
+			return errors.Errorf("dependency %q did not complete successfully", dependency)
+		}
+	}
+	return nil
+}
+
+func runStepWithBackoff(step Step) StepStatus {
+	maxAttempts := step.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if lastErr = step.Run(); lastErr == nil {
+			return StepStatus{Name: step.Name, Attempts: attempt}
+		}
+		if attempt < maxAttempts {
+			time.Sleep(stepBackoffBase * (1 << (attempt - 1)))
+		}
+	}
+	return StepStatus{Name: step.Name, Attempts: maxAttempts, Err: lastErr}
+}
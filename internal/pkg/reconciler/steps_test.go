@@ -0,0 +1,159 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package reconciler
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestExecuteStopsOnFailingStep(t *testing.T) {
+	ran := []string{}
+	errBoom := errors.New("boom")
+	graph := StepGraph{
+		{
+			Name: "first",
+			Run: func() error {
+				ran = append(ran, "first")
+				return nil
+			},
+		},
+		{
+			Name: "second",
+			Run: func() error {
+				ran = append(ran, "second")
+				return errBoom
+			},
+		},
+		{
+			Name: "third",
+			Run: func() error {
+				ran = append(ran, "third")
+				return nil
+			},
+		},
+	}
+	statuses, err := Execute(graph)
+	if err == nil {
+		t.Fatal("expected Execute to return an error")
+	}
+	if len(ran) != 2 || ran[0] != "first" || ran[1] != "second" {
+		t.Fatalf("expected only first and second to run, ran: %v", ran)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses to be recorded, got %d", len(statuses))
+	}
+	if statuses[1].Succeeded() {
+		t.Fatal("expected the second step status to be recorded as failed")
+	}
+}
+
+func TestExecuteSkipsStepWithUnmetDependency(t *testing.T) {
+	ran := []string{}
+	graph := StepGraph{
+		{
+			Name: "first",
+			Run: func() error {
+				ran = append(ran, "first")
+				return nil
+			},
+		},
+		{
+			Name:      "second",
+			DependsOn: []string{"never-declared"},
+			Run: func() error {
+				ran = append(ran, "second")
+				return nil
+			},
+		},
+	}
+	statuses, err := Execute(graph)
+	if err == nil {
+		t.Fatal("expected Execute to return an error")
+	}
+	if len(ran) != 1 {
+		t.Fatalf("expected second to be skipped, ran: %v", ran)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("expected 2 statuses to be recorded, got %d", len(statuses))
+	}
+	secondStatus := statuses[1]
+	if secondStatus.Name != "second" {
+		t.Fatalf("expected the second recorded status to be for step %q, got %q", "second", secondStatus.Name)
+	}
+	if secondStatus.Succeeded() {
+		t.Fatal("expected the second step to be recorded as failed due to its unmet dependency")
+	}
+	if secondStatus.Attempts != 0 {
+		t.Fatalf("expected a skipped step to have made no attempts, got %d", secondStatus.Attempts)
+	}
+}
+
+func TestExecuteRetriesUpToMaxAttempts(t *testing.T) {
+	attempts := 0
+	graph := StepGraph{
+		{
+			Name:        "flaky",
+			MaxAttempts: 3,
+			Run: func() error {
+				attempts++
+				return errors.New("still failing")
+			},
+		},
+	}
+	statuses, err := Execute(graph)
+	if err == nil {
+		t.Fatal("expected Execute to return an error")
+	}
+	if attempts != 3 {
+		t.Fatalf("expected Run to be attempted 3 times, got %d", attempts)
+	}
+	if len(statuses) != 1 {
+		t.Fatalf("expected 1 status to be recorded, got %d", len(statuses))
+	}
+	if statuses[0].Attempts != 3 {
+		t.Fatalf("expected the recorded status to show 3 attempts, got %d", statuses[0].Attempts)
+	}
+}
+
+func TestExecuteRetriesUntilSuccess(t *testing.T) {
+	attempts := 0
+	graph := StepGraph{
+		{
+			Name:        "eventually-succeeds",
+			MaxAttempts: 3,
+			Run: func() error {
+				attempts++
+				if attempts < 2 {
+					return errors.New("not yet")
+				}
+				return nil
+			},
+		},
+	}
+	statuses, err := Execute(graph)
+	if err != nil {
+		t.Fatalf("expected Execute to succeed, got: %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected Run to stop retrying once it succeeded, attempted %d times", attempts)
+	}
+	if !statuses[0].Succeeded() {
+		t.Fatal("expected the step to be recorded as succeeded")
+	}
+}
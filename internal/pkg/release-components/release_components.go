@@ -27,6 +27,8 @@ const (
 	Etcd KubernetesComponent = "etcd"
 	// CoreDNS is the CoreDNS component
 	CoreDNS KubernetesComponent = "coredns"
+	// KubeProxy is the kube-proxy component
+	KubeProxy KubernetesComponent = "kube-proxy"
 )
 
 const (
@@ -42,7 +44,7 @@ const (
 
 var (
 	// KubernetesComponents is the list of all versioned components
-	KubernetesComponents = []KubernetesComponent{Etcd, CoreDNS}
+	KubernetesComponents = []KubernetesComponent{Etcd, CoreDNS, KubeProxy}
 )
 
 var (
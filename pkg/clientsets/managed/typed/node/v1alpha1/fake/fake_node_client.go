@@ -32,6 +32,10 @@ func (c *FakeNodeV1alpha1) NodeJoinRequests() v1alpha1.NodeJoinRequestInterface
 	return &FakeNodeJoinRequests{c}
 }
 
+func (c *FakeNodeV1alpha1) NodeLeaveRequests() v1alpha1.NodeLeaveRequestInterface {
+	return &FakeNodeLeaveRequests{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeNodeV1alpha1) RESTClient() rest.Interface {
@@ -19,3 +19,5 @@
 package v1alpha1
 
 type NodeJoinRequestExpansion interface{}
+
+type NodeLeaveRequestExpansion interface{}
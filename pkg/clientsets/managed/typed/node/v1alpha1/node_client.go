@@ -27,6 +27,7 @@ import (
 type NodeV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	NodeJoinRequestsGetter
+	NodeLeaveRequestsGetter
 }
 
 // NodeV1alpha1Client is used to interact with features provided by the node group.
@@ -38,6 +39,10 @@ func (c *NodeV1alpha1Client) NodeJoinRequests() NodeJoinRequestInterface {
 	return newNodeJoinRequests(c)
 }
 
+func (c *NodeV1alpha1Client) NodeLeaveRequests() NodeLeaveRequestInterface {
+	return newNodeLeaveRequests(c)
+}
+
 // NewForConfig creates a new NodeV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*NodeV1alpha1Client, error) {
 	config := *c
@@ -18,6 +18,7 @@ package constants
 
 import (
 	"path/filepath"
+	"time"
 )
 
 const (
@@ -25,6 +26,15 @@ const (
 	DefaultCAKeyBitSize = 4096
 	// DefaultKeyBitSize is the default key bit size for non-CA certificates
 	DefaultKeyBitSize = 2048
+	// DefaultCertificateClockSkewTolerance is how far in the past
+	// NotBefore is backdated when generating a certificate, so a
+	// certificate issued by the management plane is already valid on
+	// a node whose clock lags slightly behind it
+	DefaultCertificateClockSkewTolerance = 5 * time.Minute
+	// DefaultMaxJoinClockSkew is the default maximum tolerated clock
+	// difference between a joining node and the management plane
+	// before the join preflight check refuses to proceed
+	DefaultMaxJoinClockSkew = 5 * time.Minute
 	// DefaultClusterCIDR is the default cluster CIDR
 	DefaultClusterCIDR = "10.244.0.0/16"
 	// DefaultServiceCIDR is the default service CIDR
@@ -43,6 +53,18 @@ const (
 	// OneInfraJoinConfigMapJoinKey is the name of the key that holds
 	// the join key inside the join ConfigMap
 	OneInfraJoinConfigMapJoinKey = "joinKey"
+	// OneInfraJoinConfigMapSigningKey is the name of the key that
+	// holds the management plane's signing public key inside the
+	// join ConfigMap, used by joining nodes to verify the
+	// authenticity of their node join request status
+	OneInfraJoinConfigMapSigningKey = "signingKey"
+	// OneInfraJoinConfigMapJoinKeyCipherSuite is the name of the key
+	// that holds the cipher suite the join key was generated with
+	// inside the join ConfigMap, so joining nodes know how to decode
+	// and use the join key. Absent for the RSA-OAEP default, to keep
+	// old node agents working against a ConfigMap that predates this
+	// field.
+	OneInfraJoinConfigMapJoinKeyCipherSuite = "joinKeyCipherSuite"
 	// OneInfraNodeJoinTokenExtraGroups represents the bootstrap token
 	// extra groups used to identify oneinfra bootstrap tokens
 	OneInfraNodeJoinTokenExtraGroups = "system:bootstrappers:oneinfra"
@@ -59,6 +81,25 @@ const (
 	OneInfraClusterUninitializedCertificates = "oneinfra/uninitialized-certificates"
 	// OneInfraCleanupFinalizer is a finalizer for cleaning up resources
 	OneInfraCleanupFinalizer = "oneinfra/cleanup"
+	// OneInfraAllowMigrationAnnotation marks a single update as
+	// allowed to change a cluster spec field that the validating
+	// webhook otherwise treats as immutable (VPN CIDR, certificate
+	// authority material). The certificate rotation reconciler sets
+	// and clears it itself around an automatic CA rotation; the
+	// `oi cluster rotate-ca` and `oi cluster expand-vpn-cidr` commands
+	// do the same around their own updates
+	OneInfraAllowMigrationAnnotation = "oneinfra/allow-migration"
+	// OneInfraRequestedAtAnnotation records the time a NodeJoinRequest
+	// was first admitted, in RFC3339 format. Set once by the
+	// defaulting webhook, and used to detect stale, never-issued join
+	// requests that have been left behind by a node that gave up
+	// joining
+	OneInfraRequestedAtAnnotation = "oneinfra/requested-at"
+	// OneInfraLastModifiedByAnnotation records the identity of the
+	// last principal to perform a disruptive CLI-driven action
+	// against a resource, taken from the invoking kubeconfig
+	// context's user, for attribution purposes
+	OneInfraLastModifiedByAnnotation = "oneinfra/last-modified-by"
 	// KubeletDir is the kubelet configuration dir
 	KubeletDir = "/var/lib/kubelet"
 )
@@ -72,6 +113,8 @@ var (
 	KubeletServerPrivateKeyPath = filepath.Join(OneInfraConfigDir, "kubelet.key")
 	// KubeletClientCACertificatePath represents the kubelet server certificate path
 	KubeletClientCACertificatePath = filepath.Join(OneInfraConfigDir, "kubelet-client-ca.crt")
+	// ClusterCACertificatePath represents the cluster certificate authority path
+	ClusterCACertificatePath = filepath.Join(OneInfraConfigDir, "cluster-ca.crt")
 	// KubeletConfigPath represents the kubelet configuration path
 	KubeletConfigPath = filepath.Join(KubeletDir, "config.yaml")
 )
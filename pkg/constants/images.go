@@ -0,0 +1,36 @@
+/**
+ * Copyright 2020 Rafael Fernández López <ereslibre@ereslibre.es>
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ **/
+
+package constants
+
+import "strings"
+
+// ImageWithRegistry returns image with its registry host replaced by
+// registry, so air-gapped or otherwise restricted environments can
+// mirror oneinfra's built-in component images into a private registry
+// without changing anything else about the image reference. An empty
+// registry leaves image untouched. An image with no explicit registry
+// host (i.e. it is assumed to come from the default public registry)
+// simply gets registry prepended
+func ImageWithRegistry(image, registry string) string {
+	if registry == "" {
+		return image
+	}
+	if slash := strings.IndexByte(image, '/'); slash != -1 && strings.ContainsAny(image[:slash], ".:") {
+		return registry + image[slash:]
+	}
+	return registry + "/" + image
+}
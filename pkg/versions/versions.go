@@ -26,7 +26,8 @@ type ReleaseInfo struct {
 
 // KubernetesVersion represents a supported Kubernetes version
 type KubernetesVersion struct {
-	Version        string `json:"version"`
-	EtcdVersion    string `json:"etcdVersion"`
-	CoreDNSVersion string `json:"coreDNSVersion"`
+	Version          string `json:"version"`
+	EtcdVersion      string `json:"etcdVersion"`
+	CoreDNSVersion   string `json:"coreDNSVersion"`
+	KubeProxyVersion string `json:"kubeProxyVersion"`
 }